@@ -5,6 +5,7 @@ import (
 	"github.com/rohitramu/kpm/src/cli/model/utils/constants"
 	"github.com/rohitramu/kpm/src/pkg/utils/log"
 
+	"os"
 	"runtime/debug"
 	"strconv"
 )
@@ -39,5 +40,6 @@ func main() {
 
 	if err = cli.Execute(); err != nil {
 		log.Errorf("Failed to execute command: %s", err.Error())
+		os.Exit(1)
 	}
 }
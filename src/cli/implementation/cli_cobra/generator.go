@@ -6,6 +6,7 @@ import (
 
 	"github.com/emirpasic/gods/stacks/linkedliststack"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/rohitramu/kpm/src/cli/model/utils/config"
 	"github.com/rohitramu/kpm/src/cli/model/utils/types"
@@ -128,16 +129,22 @@ func setArgs(argCollection *types.ArgCollection, args []string) {
 func addFlags(cobraCmd *cobra.Command, modelCmd *types.Command, config *config.KpmConfig) {
 	// String flags.
 	for _, modelFlag := range modelCmd.Flags.StringFlags {
-		addFlag(cobraCmd.PersistentFlags().StringVarP, modelFlag, config)
+		addFlag(cobraCmd.PersistentFlags(), cobraCmd.PersistentFlags().StringVarP, modelFlag, config)
+	}
+
+	// String array flags (which may be repeated).
+	for _, modelFlag := range modelCmd.Flags.StringArrayFlags {
+		addFlag(cobraCmd.PersistentFlags(), cobraCmd.PersistentFlags().StringArrayVarP, modelFlag, config)
 	}
 
 	// Bool flags.
 	for _, modelFlag := range modelCmd.Flags.BoolFlags {
-		addFlag(cobraCmd.PersistentFlags().BoolVarP, modelFlag, config)
+		addFlag(cobraCmd.PersistentFlags(), cobraCmd.PersistentFlags().BoolVarP, modelFlag, config)
 	}
 }
 
 func addFlag[T any](
+	flagSet *pflag.FlagSet,
 	addFlagPFunc func(p *T, name string, alias string, value T, usage string),
 	modelFlag types.Flag[T],
 	config *config.KpmConfig,
@@ -164,4 +171,18 @@ func addFlag[T any](
 		defaultValue,
 		modelFlag.GetShortDescription(),
 	)
+
+	// Pflag only allows one shorthand per flag, so extra aliases are hidden flags which share the same value.  This way,
+	// repeated flags are combined even if different aliases are used.
+	var pflagFlag = flagSet.Lookup(modelFlag.GetName())
+	for _, extraAlias := range modelFlag.GetExtraAliases() {
+		var aliasFlag = flagSet.VarPF(
+			pflagFlag.Value,
+			fmt.Sprintf("%s-%c", modelFlag.GetName(), extraAlias),
+			string(extraAlias),
+			modelFlag.GetShortDescription(),
+		)
+		aliasFlag.NoOptDefVal = pflagFlag.NoOptDefVal
+		aliasFlag.Hidden = true
+	}
 }
@@ -0,0 +1,13 @@
+package args
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+func RepositoryDirectory(shortDescription string) *types.Arg {
+	return &types.Arg{
+		Name:             "repository-directory",
+		ShortDescription: shortDescription,
+		Value:            "",
+	}
+}
@@ -0,0 +1,13 @@
+package args
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+func RepositoryName(shortDescription string) *types.Arg {
+	return &types.Arg{
+		Name:             "repository-name",
+		ShortDescription: shortDescription,
+		Value:            "",
+	}
+}
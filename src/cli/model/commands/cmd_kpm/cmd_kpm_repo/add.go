@@ -0,0 +1,54 @@
+package cmd_kpm_repo
+
+import (
+	"errors"
+
+	"github.com/rohitramu/kpm/src/cli/model/args"
+	"github.com/rohitramu/kpm/src/cli/model/flags"
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/constants"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg"
+)
+
+var AddCmd = &types.Command{
+	Name:             constants.CmdRepoAdd,
+	ShortDescription: "Adds a repository to the end of the list of repositories in a config file.",
+	Flags: types.FlagCollection{
+		StringFlags: []types.Flag[string]{
+			flags.RepoType,
+			flags.RepoConnection,
+		},
+		BoolFlags: []types.Flag[bool]{
+			flags.WorkingDirConfig,
+		},
+	},
+	Args: types.ArgCollection{
+		MandatoryArgs: []*types.Arg{
+			args.RepositoryName("The name of the repository to add."),
+		},
+	},
+	ExecuteFunc: func(config *config.KpmConfig, args types.ArgCollection) (err error) {
+		// Flags
+		var repoType = flags.RepoType.GetValueOrDefault(config)
+		var connection = flags.RepoConnection.GetValueOrDefault(config)
+		var useWorkingDirConfig = flags.WorkingDirConfig.GetValueOrDefault(config)
+
+		// Args
+		var repoName = args.MandatoryArgs[0].Value
+
+		// Validation
+		{
+			if repoType == "" {
+				return errors.New("flag '--type' must be set")
+			}
+		}
+
+		var configFilePath string
+		if configFilePath, err = getConfigFilePath(useWorkingDirConfig); err != nil {
+			return err
+		}
+
+		return pkg.AddRepository(configFilePath, repoName, repoType, connection)
+	},
+}
@@ -0,0 +1,25 @@
+package cmd_kpm_repo
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/args"
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/constants"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg"
+)
+
+var IndexCmd = &types.Command{
+	Name:             constants.CmdRepoIndex,
+	ShortDescription: "Generates the index file for a directory of package archives, so it can be served as an HTTP repository.",
+	Args: types.ArgCollection{
+		MandatoryArgs: []*types.Arg{
+			args.RepositoryDirectory("The directory which contains the package archives."),
+		},
+	},
+	ExecuteFunc: func(config *config.KpmConfig, args types.ArgCollection) (err error) {
+		// Args
+		var repoDir = args.MandatoryArgs[0].Value
+
+		return pkg.IndexRepository(repoDir)
+	},
+}
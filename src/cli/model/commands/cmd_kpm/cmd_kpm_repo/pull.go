@@ -49,18 +49,20 @@ var PullCmd = &types.Command{
 
 		// If the repo name is provided, don't look in other repos.
 		if repoName != "" {
-			return pkg.PullPackage(
+			_, err = pkg.PullPackage(
 				kpmHomeDir,
 				config.Repositories,
 				repoName,
 				packageName,
 				packageVersion,
 			)
+
+			return err
 		}
 
 		// If the repo name is not provided, search all repos in order of priority.
 		for _, repoName := range repoNames {
-			err = pkg.PullPackage(
+			_, err = pkg.PullPackage(
 				kpmHomeDir,
 				config.Repositories,
 				repoName,
@@ -0,0 +1,39 @@
+package cmd_kpm_repo
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/args"
+	"github.com/rohitramu/kpm/src/cli/model/flags"
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/constants"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg"
+)
+
+var SetDefaultCmd = &types.Command{
+	Name:             constants.CmdRepoSetDefault,
+	ShortDescription: "Makes a repository the default one, by moving it to the start of the list of repositories in a config file.",
+	Flags: types.FlagCollection{
+		BoolFlags: []types.Flag[bool]{
+			flags.WorkingDirConfig,
+		},
+	},
+	Args: types.ArgCollection{
+		MandatoryArgs: []*types.Arg{
+			args.RepositoryName("The name of the repository which should be the default."),
+		},
+	},
+	ExecuteFunc: func(config *config.KpmConfig, args types.ArgCollection) (err error) {
+		// Flags
+		var useWorkingDirConfig = flags.WorkingDirConfig.GetValueOrDefault(config)
+
+		// Args
+		var repoName = args.MandatoryArgs[0].Value
+
+		var configFilePath string
+		if configFilePath, err = getConfigFilePath(useWorkingDirConfig); err != nil {
+			return err
+		}
+
+		return pkg.SetDefaultRepository(configFilePath, repoName)
+	},
+}
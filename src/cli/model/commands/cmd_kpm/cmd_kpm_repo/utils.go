@@ -0,0 +1,18 @@
+package cmd_kpm_repo
+
+import (
+	"fmt"
+
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+)
+
+// getConfigFilePath returns the path of the config file which repository commands should edit.  This is a separate
+// function because the "config" package is shadowed by the "config" parameter of each command's ExecuteFunc.
+func getConfigFilePath(useWorkingDirConfig bool) (string, error) {
+	var configFilePath, err = config.GetConfigFilePath(useWorkingDirConfig)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config file: %s", err)
+	}
+
+	return configFilePath, nil
+}
@@ -0,0 +1,80 @@
+package cmd_kpm
+
+import (
+	"fmt"
+
+	"github.com/rohitramu/kpm/src/cli/model/args"
+	"github.com/rohitramu/kpm/src/cli/model/flags"
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/constants"
+	"github.com/rohitramu/kpm/src/cli/model/utils/directories"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg"
+	"github.com/rohitramu/kpm/src/pkg/utils/params"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+var Eval = &types.Command{
+	Name:             constants.CmdEval,
+	ShortDescription: "Evaluates the interface of a template package and prints the values, without executing its templates.",
+	Flags: types.FlagCollection{
+		StringFlags: []types.Flag[string]{
+			flags.Expression,
+			flags.ValuesFormat,
+		},
+		StringArrayFlags: []types.Flag[[]string]{
+			flags.ParametersFile,
+			flags.Set,
+			flags.SetString,
+			flags.SetFile,
+		},
+		BoolFlags: []types.Flag[bool]{
+			flags.UserConfirmation,
+			flags.Prerelease,
+		},
+	},
+	Args: types.ArgCollection{
+		MandatoryArgs: []*types.Arg{args.PackageName("The name of the template package to evaluate.")},
+		OptionalArg:   args.PackageVersion("The version of the template package to evaluate.  If not set, the latest version will be evaluated."),
+	},
+	ExecuteFunc: func(config *config.KpmConfig, args types.ArgCollection) (err error) {
+		// Args
+		var packageName = args.MandatoryArgs[0].Value
+		var packageVersion = args.OptionalArg.Value
+
+		// Flags
+		var paramFiles = flags.ParametersFile.GetValueOrDefault(config)
+		var setExpressions = flags.Set.GetValueOrDefault(config)
+		var setStringExpressions = flags.SetString.GetValueOrDefault(config)
+		var setFileExpressions = flags.SetFile.GetValueOrDefault(config)
+		var expression = flags.Expression.GetValueOrDefault(config)
+		var valuesFormat = flags.ValuesFormat.GetValueOrDefault(config)
+		var skipConfirmation = flags.UserConfirmation.GetValueOrDefault(config)
+		var includePrerelease = flags.Prerelease.GetValueOrDefault(config)
+
+		// Get KPM home directory or create it if it doesn't exist.
+		var kpmHomeDir string
+		if kpmHomeDir, err = directories.GetOrCreateKpmHomeDir(skipConfirmation); err != nil {
+			return err
+		}
+
+		// Validation
+		{
+			// Package version
+			if packageVersion == "" {
+				// Since the package version was not provided, check the local repository for the highest version.
+				if packageVersion, err = template_package.GetHighestPackageVersion(kpmHomeDir, packageName, includePrerelease); err != nil {
+					return fmt.Errorf("could not find package '%s' in the local KPM repository: %s", packageName, err)
+				}
+			}
+		}
+
+		// Parameter overrides
+		var parameterOverrides []*params.Override
+		if parameterOverrides, err = getParameterOverrides(setExpressions, setStringExpressions, setFileExpressions); err != nil {
+			return err
+		}
+
+		return pkg.EvalCmd(packageName, packageVersion, paramFiles, parameterOverrides, kpmHomeDir, expression, valuesFormat)
+	},
+}
@@ -15,10 +15,12 @@ import (
 
 var Inspect = &types.Command{
 	Name:             constants.CmdInspect,
-	ShortDescription: "Prints the contents of the default parameters file in a template package.",
+	ShortDescription: "Prints the contents of the default parameters file (or the parameters schema) in a template package.",
 	Flags: types.FlagCollection{
 		BoolFlags: []types.Flag[bool]{
 			flags.UserConfirmation,
+			flags.Prerelease,
+			flags.Schema,
 		},
 	},
 	Args: types.ArgCollection{
@@ -28,6 +30,8 @@ var Inspect = &types.Command{
 	ExecuteFunc: func(config *config.KpmConfig, args types.ArgCollection) (err error) {
 		// Flags
 		var skipConfirmation = flags.UserConfirmation.GetValueOrDefault(config)
+		var includePrerelease = flags.Prerelease.GetValueOrDefault(config)
+		var showSchema = flags.Schema.GetValueOrDefault(config)
 
 		// Args
 		var packageName = args.MandatoryArgs[0].Value
@@ -45,12 +49,12 @@ var Inspect = &types.Command{
 			if packageVersion == "" {
 				// Since the package version was not provided, check the local repository for the highest version.
 				var err error
-				if packageVersion, err = template_package.GetHighestPackageVersion(kpmHomeDir, packageName); err != nil {
+				if packageVersion, err = template_package.GetHighestPackageVersion(kpmHomeDir, packageName, includePrerelease); err != nil {
 					return fmt.Errorf("could not find package '%s' in the local KPM repository: %s", packageName, err)
 				}
 			}
 		}
 
-		return pkg.InspectCmd(packageName, packageVersion, kpmHomeDir)
+		return pkg.InspectCmd(packageName, packageVersion, kpmHomeDir, showSchema)
 	},
 }
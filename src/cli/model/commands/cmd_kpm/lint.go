@@ -0,0 +1,23 @@
+package cmd_kpm
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/args"
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/constants"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg"
+)
+
+var Lint = &types.Command{
+	Name:             constants.CmdLint,
+	ShortDescription: "Checks a template package for problems without executing it.",
+	Args: types.ArgCollection{
+		MandatoryArgs: []*types.Arg{args.PackageDirectory("The location of the template package directory which should be checked.")},
+	},
+	ExecuteFunc: func(config *config.KpmConfig, args types.ArgCollection) (err error) {
+		// Args
+		var packageDir = args.MandatoryArgs[0].Value
+
+		return pkg.LintCmd(packageDir)
+	},
+}
@@ -14,14 +14,16 @@ var Pack = &types.Command{
 	Name:             constants.CmdPack,
 	ShortDescription: "Validates a template package and makes it available for use.",
 	Flags: types.FlagCollection{
-		BoolFlags: []types.Flag[bool]{flags.UserConfirmation},
+		StringFlags: []types.Flag[string]{flags.Archive},
+		BoolFlags:   []types.Flag[bool]{flags.UserConfirmation},
 	},
 	Args: types.ArgCollection{
-		MandatoryArgs: []*types.Arg{args.PackageDirectory("The location of the template package directory which should be packed.")},
+		MandatoryArgs: []*types.Arg{args.PackageDirectory("The location of the template package directory (or package archive) which should be packed.")},
 	},
 	ExecuteFunc: func(config *config.KpmConfig, args types.ArgCollection) (err error) {
 		// Flags
 		var skipConfirmation = flags.UserConfirmation.GetValueOrDefault(config)
+		var archivePath = flags.Archive.GetValueOrDefault(config)
 
 		// Args
 		var packageDir = args.MandatoryArgs[0].Value
@@ -32,6 +34,6 @@ var Pack = &types.Command{
 			return err
 		}
 
-		return pkg.PackCmd(packageDir, kpmHomeDir, skipConfirmation)
+		return pkg.PackCmd(packageDir, archivePath, kpmHomeDir, skipConfirmation)
 	},
 }
@@ -18,7 +18,7 @@ var Remove = &types.Command{
 	Alias:            "rm",
 	ShortDescription: "Removes a template package.",
 	Flags: types.FlagCollection{
-		BoolFlags: []types.Flag[bool]{flags.UserConfirmation},
+		BoolFlags: []types.Flag[bool]{flags.UserConfirmation, flags.Prerelease},
 	},
 	Args: types.ArgCollection{
 		MandatoryArgs: []*types.Arg{args.PackageName("The name of the template package to remove.")},
@@ -27,6 +27,7 @@ var Remove = &types.Command{
 	ExecuteFunc: func(config *config.KpmConfig, args types.ArgCollection) (err error) {
 		// Flags
 		var skipConfirmation = flags.UserConfirmation.GetValueOrDefault(config)
+		var includePrerelease = flags.Prerelease.GetValueOrDefault(config)
 
 		// Args
 		var packageName = args.MandatoryArgs[0].Value
@@ -41,7 +42,7 @@ var Remove = &types.Command{
 		if packageVersion == "" {
 			// Since the package version was not provided, check the local repository for the highest version.
 			var err error
-			if packageVersion, err = template_package.GetHighestPackageVersion(kpmHomeDir, packageName); err != nil {
+			if packageVersion, err = template_package.GetHighestPackageVersion(kpmHomeDir, packageName, includePrerelease); err != nil {
 				return fmt.Errorf("could not find package '%s' in the local KPM repository: %s", packageName, err)
 			}
 		}
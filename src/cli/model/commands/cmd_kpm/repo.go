@@ -15,5 +15,9 @@ var Repo = &types.Command{
 		cmd_kpm_repo.FindCmd,
 		cmd_kpm_repo.PushCmd,
 		cmd_kpm_repo.PullCmd,
+		cmd_kpm_repo.IndexCmd,
+		cmd_kpm_repo.AddCmd,
+		cmd_kpm_repo.RemoveCmd,
+		cmd_kpm_repo.SetDefaultCmd,
 	},
 }
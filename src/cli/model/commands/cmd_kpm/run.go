@@ -1,7 +1,7 @@
 package cmd_kpm
 
 import (
-	"fmt"
+	"os"
 
 	"github.com/rohitramu/kpm/src/cli/model/args"
 	"github.com/rohitramu/kpm/src/cli/model/flags"
@@ -10,7 +10,8 @@ import (
 	"github.com/rohitramu/kpm/src/cli/model/utils/directories"
 	"github.com/rohitramu/kpm/src/cli/model/utils/types"
 	"github.com/rohitramu/kpm/src/pkg"
-	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/params"
 )
 
 var Run = &types.Command{
@@ -18,17 +19,28 @@ var Run = &types.Command{
 	ShortDescription: "Runs a template package.",
 	Flags: types.FlagCollection{
 		StringFlags: []types.Flag[string]{
-			flags.ParametersFile,
 			flags.OutputDir,
 			flags.OutputName,
+			flags.OutputFormat,
+		},
+		StringArrayFlags: []types.Flag[[]string]{
+			flags.ParametersFile,
+			flags.Set,
+			flags.SetString,
+			flags.SetFile,
 		},
 		BoolFlags: []types.Flag[bool]{
 			flags.UserConfirmation,
+			flags.Offline,
+			flags.FrozenLockfile,
+			flags.Prerelease,
+			flags.DryRun,
+			flags.ExplainValues,
 		},
 	},
 	Args: types.ArgCollection{
 		MandatoryArgs: []*types.Arg{args.PackageName("The name of the template package to run.")},
-		OptionalArg:   args.PackageVersion("The version of the template package to run.  If not set, the latest version will be run."),
+		OptionalArg:   args.PackageVersion("The version of the template package to run, or a version constraint (e.g. \"^1.2.0\").  If not set, the version in the lock file or the latest version will be run."),
 	},
 	ExecuteFunc: func(config *config.KpmConfig, args types.ArgCollection) (err error) {
 		// Args
@@ -36,10 +48,24 @@ var Run = &types.Command{
 		var packageVersion = args.OptionalArg.Value
 
 		// Flags
-		var paramFile = flags.ParametersFile.GetValueOrDefault(config)
+		var paramFiles = flags.ParametersFile.GetValueOrDefault(config)
+		var setExpressions = flags.Set.GetValueOrDefault(config)
+		var setStringExpressions = flags.SetString.GetValueOrDefault(config)
+		var setFileExpressions = flags.SetFile.GetValueOrDefault(config)
 		var outputDir = flags.OutputDir.GetValueOrDefault(config)
 		var outputName = flags.OutputName.GetValueOrDefault(config)
 		var skipConfirmation = flags.UserConfirmation.GetValueOrDefault(config)
+		var offline = flags.Offline.GetValueOrDefault(config)
+		var frozenLockfile = flags.FrozenLockfile.GetValueOrDefault(config)
+		var includePrerelease = flags.Prerelease.GetValueOrDefault(config)
+		var dryRun = flags.DryRun.GetValueOrDefault(config)
+		var outputFormat = flags.OutputFormat.GetValueOrDefault(config)
+		var explainValues = flags.ExplainValues.GetValueOrDefault(config)
+
+		// Keep logs out of the output when it is written to stdout
+		if outputFormat != pkg.OutputFormatDir || explainValues {
+			log.SetWriterInfo(os.Stderr)
+		}
 
 		// Get KPM home directory or create it if it doesn't exist.
 		var kpmHomeDir string
@@ -48,27 +74,55 @@ var Run = &types.Command{
 		}
 
 		// Validation
-		var optionalParamFile = &paramFile
 		var optionalOutputName = &outputName
 		{
-			// Package version
-			if packageVersion == "" {
-				// Since the package version was not provided, check the local repository for the highest version.
-				var err error
-				if packageVersion, err = template_package.GetHighestPackageVersion(kpmHomeDir, packageName); err != nil {
-					return fmt.Errorf("could not find package '%s' in the local KPM repository: %s", packageName, err)
-				}
-			}
-			// Parameters file
-			if paramFile == "" {
-				optionalParamFile = nil
-			}
 			// Output name
 			if outputName == "" {
 				optionalOutputName = nil
 			}
 		}
 
-		return pkg.RunCmd(packageName, packageVersion, optionalParamFile, outputDir, optionalOutputName, kpmHomeDir, skipConfirmation)
+		// Parameter overrides
+		var parameterOverrides []*params.Override
+		if parameterOverrides, err = getParameterOverrides(setExpressions, setStringExpressions, setFileExpressions); err != nil {
+			return err
+		}
+
+		return pkg.RunCmd(packageName, packageVersion, kpmHomeDir, config.Repositories, &pkg.RunOptions{
+			ParametersFilePaths: paramFiles,
+			ParameterOverrides:  parameterOverrides,
+			OutputDirPath:       outputDir,
+			OutputName:          optionalOutputName,
+			Offline:             offline,
+			FrozenLockfile:      frozenLockfile,
+			IncludePrerelease:   includePrerelease,
+			DryRun:              dryRun,
+			OutputFormat:        outputFormat,
+			ExplainValues:       explainValues,
+		})
 	},
 }
+
+// getParameterOverrides parses the expressions from the "--set", "--set-string" and "--set-file" flags.  The overrides
+// are returned in the same order as the flags are listed, which is the order they are applied in.
+func getParameterOverrides(setExpressions []string, setStringExpressions []string, setFileExpressions []string) ([]*params.Override, error) {
+	var result = []*params.Override{}
+	for _, expressionsAndParser := range []struct {
+		expressions []string
+		parse       func(string) ([]*params.Override, error)
+	}{
+		{setExpressions, params.ParseSet},
+		{setStringExpressions, params.ParseSetString},
+		{setFileExpressions, params.ParseSetFile},
+	} {
+		for _, expression := range expressionsAndParser.expressions {
+			var overrides, err = expressionsAndParser.parse(expression)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, overrides...)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,69 @@
+package cmd_kpm
+
+import (
+	"os"
+
+	"github.com/rohitramu/kpm/src/cli/model/args"
+	"github.com/rohitramu/kpm/src/cli/model/flags"
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/constants"
+	"github.com/rohitramu/kpm/src/cli/model/utils/directories"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+)
+
+var Test = &types.Command{
+	Name:             constants.CmdTest,
+	ShortDescription: "Runs the test cases in a template package and compares the output with the expected output.",
+	Flags: types.FlagCollection{
+		StringFlags: []types.Flag[string]{
+			flags.ReportFormat,
+			flags.CoverageOut,
+			flags.CoverageFormat,
+		},
+		BoolFlags: []types.Flag[bool]{
+			flags.UserConfirmation,
+			flags.Offline,
+			flags.Prerelease,
+			flags.Update,
+			flags.Coverage,
+		},
+	},
+	Args: types.ArgCollection{
+		MandatoryArgs: []*types.Arg{args.PackageDirectory("The location of the template package directory which should be tested.")},
+	},
+	ExecuteFunc: func(config *config.KpmConfig, args types.ArgCollection) (err error) {
+		// Args
+		var packageDir = args.MandatoryArgs[0].Value
+
+		// Flags
+		var skipConfirmation = flags.UserConfirmation.GetValueOrDefault(config)
+		var offline = flags.Offline.GetValueOrDefault(config)
+		var includePrerelease = flags.Prerelease.GetValueOrDefault(config)
+		var update = flags.Update.GetValueOrDefault(config)
+		var reportFormat = flags.ReportFormat.GetValueOrDefault(config)
+		var coverage = flags.Coverage.GetValueOrDefault(config)
+		var coverageOut = flags.CoverageOut.GetValueOrDefault(config)
+		var coverageFormat = flags.CoverageFormat.GetValueOrDefault(config)
+
+		// Keep logs out of the report
+		log.SetWriterInfo(os.Stderr)
+
+		// Get KPM home directory or create it if it doesn't exist.
+		var kpmHomeDir string
+		if kpmHomeDir, err = directories.GetOrCreateKpmHomeDir(skipConfirmation); err != nil {
+			return err
+		}
+
+		return pkg.TestCmd(packageDir, kpmHomeDir, config.Repositories, &pkg.TestOptions{
+			Offline:           offline,
+			IncludePrerelease: includePrerelease,
+			Update:            update,
+			ReportFormat:      reportFormat,
+			EnableCoverage:    coverage,
+			CoverageOutPath:   coverageOut,
+			CoverageFormat:    coverageFormat,
+		})
+	},
+}
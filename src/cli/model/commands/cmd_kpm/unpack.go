@@ -2,6 +2,7 @@ package cmd_kpm
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/rohitramu/kpm/src/cli/model/args"
 	"github.com/rohitramu/kpm/src/cli/model/flags"
@@ -10,6 +11,7 @@ import (
 	"github.com/rohitramu/kpm/src/cli/model/utils/directories"
 	"github.com/rohitramu/kpm/src/cli/model/utils/types"
 	"github.com/rohitramu/kpm/src/pkg"
+	pkg_constants "github.com/rohitramu/kpm/src/pkg/utils/constants"
 	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
 )
 
@@ -23,15 +25,20 @@ var Unpack = &types.Command{
 		},
 		BoolFlags: []types.Flag[bool]{
 			flags.UserConfirmation,
+			flags.Prerelease,
 		},
 	},
 	Args: types.ArgCollection{
-		MandatoryArgs: []*types.Arg{args.PackageName("The name of the template package to unpack.")},
-		OptionalArg:   args.PackageVersion("The version of the template package to unpack.  If not provided, the latest package version will be used."),
+		MandatoryArgs: []*types.Arg{args.PackageName(fmt.Sprintf(
+			"The name of the template package to unpack, or the path of a package archive (ending in \"%s\").",
+			pkg_constants.PackageArchiveExtension,
+		))},
+		OptionalArg: args.PackageVersion("The version of the template package to unpack.  If not provided, the latest package version will be used."),
 	},
 	ExecuteFunc: func(config *config.KpmConfig, inputArgs types.ArgCollection) (err error) {
 		// Flags
 		var skipConfirmation = flags.UserConfirmation.GetValueOrDefault(config)
+		var includePrerelease = flags.Prerelease.GetValueOrDefault(config)
 		var exportDir = flags.ExportDir.GetValueOrDefault(config)
 		var exportName = flags.ExportName.GetValueOrDefault(config)
 
@@ -39,6 +46,11 @@ var Unpack = &types.Command{
 		var packageName = inputArgs.MandatoryArgs[0].Value
 		var packageVersion = inputArgs.OptionalArg.Value
 
+		// Package archives contain everything that is needed.
+		if strings.HasSuffix(packageName, pkg_constants.PackageArchiveExtension) {
+			return pkg.UnpackArchiveCmd(packageName, exportDir, exportName, skipConfirmation)
+		}
+
 		// Get KPM home directory or create it if it doesn't exist.
 		var kpmHomeDir string
 		if kpmHomeDir, err = directories.GetOrCreateKpmHomeDir(skipConfirmation); err != nil {
@@ -51,7 +63,7 @@ var Unpack = &types.Command{
 			if packageVersion == "" {
 				// Since the package version was not provided, check the local repository for the highest version
 				var err error
-				if packageVersion, err = template_package.GetHighestPackageVersion(kpmHomeDir, packageName); err != nil {
+				if packageVersion, err = template_package.GetHighestPackageVersion(kpmHomeDir, packageName, includePrerelease); err != nil {
 					return fmt.Errorf("package version must be provided if the package does not exist in the local repository: %s", err)
 				}
 			}
@@ -20,9 +20,12 @@ var Kpm = &types.Command{
 		cmd_kpm.Remove,
 		cmd_kpm.Purge,
 		cmd_kpm.Pack,
+		cmd_kpm.Lint,
+		cmd_kpm.Test,
 		cmd_kpm.Unpack,
 		cmd_kpm.Inspect,
 		cmd_kpm.Run,
+		cmd_kpm.Eval,
 		cmd_kpm.New,
 		cmd_kpm.Repo,
 	},
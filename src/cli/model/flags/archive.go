@@ -0,0 +1,16 @@
+package flags
+
+import (
+	"fmt"
+
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+)
+
+var Archive = types.NewFlagBuilder[string]("archive").
+	SetAlias('a').
+	SetShortDescription(fmt.Sprintf(
+		"Write the template package to a package archive at this path (e.g. \"my-package%s\") instead of making it available for use.",
+		constants.PackageArchiveExtension,
+	)).
+	Build()
@@ -0,0 +1,11 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var Coverage = types.NewFlagBuilder[bool]("coverage").
+	SetShortDescription("Record which actions and branches in the template files are executed by the test cases, and print the coverage of each file.").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) bool { return false }).
+	Build()
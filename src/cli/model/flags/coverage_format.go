@@ -0,0 +1,34 @@
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg"
+)
+
+var CoverageFormat = types.NewFlagBuilder[string]("coverage-format").
+	SetShortDescription(fmt.Sprintf(
+		"Format of the coverage report (one of: %s) - \"%s\" is an LCOV tracefile and \"%s\" is each template file annotated with execution counts.",
+		strings.Join(pkg.CoverageFormats, ", "),
+		pkg.CoverageFormatLcov,
+		pkg.CoverageFormatListing,
+	)).
+	SetDefaultValueFunc(func(kc *config.KpmConfig) string { return pkg.CoverageFormatLcov }).
+	SetValidationFunc(func(flagName string, flagValueRef *string) error {
+		// Skip this validation if the value isn't set.
+		if flagValueRef == nil {
+			return nil
+		}
+
+		for _, coverageFormat := range pkg.CoverageFormats {
+			if *flagValueRef == coverageFormat {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("flag '--%s' must be one of: %s", flagName, strings.Join(pkg.CoverageFormats, ", "))
+	}).
+	Build()
@@ -0,0 +1,11 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var CoverageOut = types.NewFlagBuilder[string]("coverage-out").
+	SetShortDescription("File which the coverage report should be written to (implies \"--coverage\").").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) string { return "" }).
+	Build()
@@ -0,0 +1,11 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var DryRun = types.NewFlagBuilder[bool]("dry-run").
+	SetShortDescription("Print the differences between the generated output and the existing output directory instead of writing any files, and fail if there are any differences.").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) bool { return false }).
+	Build()
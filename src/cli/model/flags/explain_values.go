@@ -0,0 +1,11 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var ExplainValues = types.NewFlagBuilder[bool]("explain-values").
+	SetShortDescription("Print the parameters and values of each package in the dependency tree, and where each parameter came from, instead of writing any files.").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) bool { return false }).
+	Build()
@@ -0,0 +1,11 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var Expression = types.NewFlagBuilder[string]("expr").
+	SetShortDescription("A template snippet (e.g. '{{ .values.name }}') to evaluate against the package's template input, instead of printing the package's values.").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) string { return "" }).
+	Build()
@@ -0,0 +1,11 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var FrozenLockfile = types.NewFlagBuilder[bool]("frozen-lockfile").
+	SetShortDescription("Fail if the template packages which are used don't exactly match the lock file in the output directory, instead of updating it.").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) bool { return false }).
+	Build()
@@ -0,0 +1,11 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var Offline = types.NewFlagBuilder[bool]("offline").
+	SetShortDescription("Only use template packages which are already in the local KPM repository, instead of pulling missing packages from the configured repositories.").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) bool { return false }).
+	Build()
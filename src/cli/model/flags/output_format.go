@@ -0,0 +1,35 @@
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg"
+)
+
+var OutputFormat = types.NewFlagBuilder[string]("output-format").
+	SetShortDescription(fmt.Sprintf(
+		"Format of the output (one of: %s) - \"%s\" writes files to the output directory, \"%s\" writes all files to stdout as YAML documents and \"%s\" writes them to stdout as a tar archive.",
+		strings.Join(pkg.OutputFormats, ", "),
+		pkg.OutputFormatDir,
+		pkg.OutputFormatYaml,
+		pkg.OutputFormatTar,
+	)).
+	SetDefaultValueFunc(func(kc *config.KpmConfig) string { return pkg.OutputFormatDir }).
+	SetValidationFunc(func(flagName string, flagValueRef *string) error {
+		// Skip this validation if the value isn't set.
+		if flagValueRef == nil {
+			return nil
+		}
+
+		for _, outputFormat := range pkg.OutputFormats {
+			if *flagValueRef == outputFormat {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("flag '--%s' must be one of: %s", flagName, strings.Join(pkg.OutputFormats, ", "))
+	}).
+	Build()
@@ -1,10 +1,13 @@
 package flags
 
 import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
 	"github.com/rohitramu/kpm/src/cli/model/utils/types"
 )
 
-var ParametersFile = types.NewFlagBuilder[string]("parameters-file").
+var ParametersFile = types.NewFlagBuilder[[]string]("parameters-file").
 	SetAlias('p').
-	SetShortDescription("Filepath of the parameters file to use.").
+	AddExtraAlias('f').
+	SetShortDescription("Filepath of a parameters file to use.  May be repeated, in which case later files are deep-merged on top of earlier files.  May also be given as \"-f\".").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) []string { return []string{} }).
 	Build()
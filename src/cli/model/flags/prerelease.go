@@ -0,0 +1,11 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var Prerelease = types.NewFlagBuilder[bool]("prerelease").
+	SetShortDescription("Allow prerelease versions (e.g. \"2.0.0-rc.1\") to be chosen when a version is not provided or a version constraint is resolved.").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) bool { return false }).
+	Build()
@@ -0,0 +1,10 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var RepoConnection = types.NewFlagBuilder[string]("connection").
+	SetAlias('c').
+	SetShortDescription("The connection information of the repository, as YAML.  This is a path for \"filesystem\" repositories, or a mapping for other repository types (e.g. '{url: https://example.com/kpm/}').").
+	Build()
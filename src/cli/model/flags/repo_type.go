@@ -0,0 +1,10 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var RepoType = types.NewFlagBuilder[string]("type").
+	SetAlias('t').
+	SetShortDescription("The type of the repository (e.g. \"filesystem\", \"git\", \"oci\", \"http\" or \"docker\").").
+	Build()
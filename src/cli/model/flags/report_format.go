@@ -0,0 +1,34 @@
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg"
+)
+
+var ReportFormat = types.NewFlagBuilder[string]("report-format").
+	SetShortDescription(fmt.Sprintf(
+		"Format of the test report (one of: %s) - \"%s\" is the Test Anything Protocol and \"%s\" is JUnit XML.",
+		strings.Join(pkg.TestReportFormats, ", "),
+		pkg.TestReportFormatTap,
+		pkg.TestReportFormatJunit,
+	)).
+	SetDefaultValueFunc(func(kc *config.KpmConfig) string { return pkg.TestReportFormatTap }).
+	SetValidationFunc(func(flagName string, flagValueRef *string) error {
+		// Skip this validation if the value isn't set.
+		if flagValueRef == nil {
+			return nil
+		}
+
+		for _, reportFormat := range pkg.TestReportFormats {
+			if *flagValueRef == reportFormat {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("flag '--%s' must be one of: %s", flagName, strings.Join(pkg.TestReportFormats, ", "))
+	}).
+	Build()
@@ -0,0 +1,14 @@
+package flags
+
+import (
+	"fmt"
+
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+)
+
+var Schema = types.NewFlagBuilder[bool]("schema").
+	SetShortDescription(fmt.Sprintf("Print the package's parameters schema (\"%s\") instead of its default parameters.", constants.ParametersSchemaFileName)).
+	SetDefaultValueFunc(func(kc *config.KpmConfig) bool { return false }).
+	Build()
@@ -0,0 +1,21 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var Set = types.NewFlagBuilder[[]string]("set").
+	SetShortDescription("Set parameters on top of the parameters files (e.g. \"a.b[0].c=value,d=true\").  \"true\", \"false\", \"null\" and integers are typed values, and \"null\" removes a parameter.  May be repeated.").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) []string { return []string{} }).
+	Build()
+
+var SetString = types.NewFlagBuilder[[]string]("set-string").
+	SetShortDescription("Set parameters to string values on top of the parameters files and \"--set\" (e.g. \"version=1.10\").  May be repeated.").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) []string { return []string{} }).
+	Build()
+
+var SetFile = types.NewFlagBuilder[[]string]("set-file").
+	SetShortDescription("Set parameters to the contents of files, after all other parameters (e.g. \"tls.cert=./cert.pem\").  May be repeated.").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) []string { return []string{} }).
+	Build()
@@ -0,0 +1,11 @@
+package flags
+
+import (
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var Update = types.NewFlagBuilder[bool]("update").
+	SetShortDescription("Rewrite the expected output of each test case with the generated output, instead of comparing them.").
+	SetDefaultValueFunc(func(kc *config.KpmConfig) bool { return false }).
+	Build()
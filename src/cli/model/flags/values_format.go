@@ -0,0 +1,29 @@
+package flags
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+	"github.com/rohitramu/kpm/src/pkg"
+)
+
+var ValuesFormat = types.NewFlagBuilder[string]("format").
+	SetShortDescription(fmt.Sprintf("Format of the printed values (one of: %s).", strings.Join(pkg.ValuesFormats, ", "))).
+	SetDefaultValueFunc(func(kc *config.KpmConfig) string { return pkg.ValuesFormatYaml }).
+	SetValidationFunc(func(flagName string, flagValueRef *string) error {
+		// Skip this validation if the value isn't set.
+		if flagValueRef == nil {
+			return nil
+		}
+
+		for _, valuesFormat := range pkg.ValuesFormats {
+			if *flagValueRef == valuesFormat {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("flag '--%s' must be one of: %s", flagName, strings.Join(pkg.ValuesFormats, ", "))
+	}).
+	Build()
@@ -0,0 +1,18 @@
+package flags
+
+import (
+	"fmt"
+
+	"github.com/rohitramu/kpm/src/cli/model/utils/config"
+	"github.com/rohitramu/kpm/src/cli/model/utils/constants"
+	"github.com/rohitramu/kpm/src/cli/model/utils/types"
+)
+
+var WorkingDirConfig = types.NewFlagBuilder[bool]("local").
+	SetAlias('l').
+	SetShortDescription(fmt.Sprintf(
+		"Edits the \"%s\" file in the current working directory instead of the one in the KPM home directory.",
+		constants.KpmConfigFileName,
+	)).
+	SetDefaultValueFunc(func(kc *config.KpmConfig) bool { return false }).
+	Build()
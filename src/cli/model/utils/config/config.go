@@ -27,7 +27,7 @@ func ReadConfig() (*KpmConfig, error) {
 	// Set defaults
 	var result = &KpmConfig{
 		LogLevel:     log.DefaultLevel,
-		Repositories: &template_repository.RepositoryCollection{},
+		Repositories: template_repository.NewRepositoryCollection(),
 	}
 
 	var kpmHomeDir string
@@ -70,6 +70,23 @@ func ReadConfig() (*KpmConfig, error) {
 	return result, nil
 }
 
+// GetConfigFilePath returns the path of the config file in the KPM home directory, or the current working directory's
+// config file if "workingDir" is true.
+func GetConfigFilePath(workingDir bool) (string, error) {
+	var configDir string
+	var err error
+	if workingDir {
+		configDir, err = files.GetWorkingDir()
+	} else {
+		configDir, err = directories.GetKpmHomeDir()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, constants.KpmConfigFileName), nil
+}
+
 func (result *KpmConfig) readConfigFromFile(filepath string) (err error) {
 	// Get the absolute filepath.
 	var absoluteFilePath string
@@ -6,12 +6,19 @@ var CmdList = "list"
 var CmdRemove = "remove"
 var CmdPurge = "purge"
 var CmdPack = "pack"
+var CmdLint = "lint"
+var CmdTest = "test"
 var CmdUnpack = "unpack"
 var CmdInspect = "inspect"
 var CmdRun = "run"
+var CmdEval = "eval"
 var CmdNewPackage = "new-package"
 var CmdRepo = "repositories"
 var CmdRepoList = "list"
 var CmdRepoFind = "find"
 var CmdRepoPush = "push"
 var CmdRepoPull = "pull"
+var CmdRepoIndex = "index"
+var CmdRepoAdd = "add"
+var CmdRepoRemove = "remove"
+var CmdRepoSetDefault = "set-default"
@@ -5,8 +5,9 @@ import (
 )
 
 type FlagCollection struct {
-	StringFlags []Flag[string]
-	BoolFlags   []Flag[bool]
+	StringFlags      []Flag[string]
+	StringArrayFlags []Flag[[]string]
+	BoolFlags        []Flag[bool]
 }
 
 type FlagIsValidFunc[T any] func(flagName string, flagValueRef *T) error
@@ -14,6 +15,7 @@ type FlagIsValidFunc[T any] func(flagName string, flagValueRef *T) error
 type Flag[T any] interface {
 	GetName() string
 	GetAlias() *rune
+	GetExtraAliases() []rune
 	GetShortDescription() string
 	GetDefaultValue(*config.KpmConfig) T
 	GetValueRef() *T
@@ -27,6 +29,7 @@ var _ Flag[any] = &flag[any]{}
 type flag[T any] struct {
 	name             string
 	alias            *rune
+	extraAliases     []rune
 	shortDescription string
 	defaultValueFunc DefaultValueFunc[T]
 	valueRef         *T
@@ -41,6 +44,10 @@ func (this *flag[T]) GetAlias() *rune {
 	return this.alias
 }
 
+func (this *flag[T]) GetExtraAliases() []rune {
+	return this.extraAliases
+}
+
 func (this *flag[T]) GetShortDescription() string {
 	return this.shortDescription
 }
@@ -9,6 +9,7 @@ type DefaultValueFunc[T any] func(*config.KpmConfig) T
 
 type FlagBuilder[T any] interface {
 	SetAlias(rune) FlagBuilder[T]
+	AddExtraAlias(rune) FlagBuilder[T]
 	SetShortDescription(string) FlagBuilder[T]
 	SetDefaultValueFunc(DefaultValueFunc[T]) FlagBuilder[T]
 	SetValidationFunc(FlagIsValidFunc[T]) FlagBuilder[T]
@@ -45,6 +46,13 @@ func (thisBuilder *flagBuilder[T]) SetAlias(alias rune) FlagBuilder[T] {
 	return thisBuilder
 }
 
+// AddExtraAlias adds another single-character name for the flag, which is accepted but isn't shown in help text.
+func (thisBuilder *flagBuilder[T]) AddExtraAlias(alias rune) FlagBuilder[T] {
+	thisBuilder.value.extraAliases = append(thisBuilder.value.extraAliases, alias)
+
+	return thisBuilder
+}
+
 func (thisBuilder *flagBuilder[T]) SetShortDescription(shortDescription string) FlagBuilder[T] {
 	thisBuilder.value.shortDescription = shortDescription
 
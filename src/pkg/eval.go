@@ -0,0 +1,167 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/params"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/templates"
+	"github.com/rohitramu/kpm/src/pkg/utils/validation"
+	"github.com/rohitramu/kpm/src/pkg/utils/yaml"
+)
+
+const (
+	// ValuesFormatYaml prints the values as YAML.
+	ValuesFormatYaml = "yaml"
+
+	// ValuesFormatJson prints the values as JSON.
+	ValuesFormatJson = "json"
+)
+
+// ValuesFormats are the supported formats for printing a package's values.
+var ValuesFormats = []string{ValuesFormatYaml, ValuesFormatJson}
+
+// expressionTemplateName is the name of the template which is created from an expression.
+const expressionTemplateName = "expression"
+
+// EvalCmd evaluates the interface of the given template package with the given parameters files and parameter overrides,
+// without executing any of the package's templates or dependencies.  The resulting values are printed in the given
+// format (see "ValuesFormats").
+// If an expression is provided, it is executed as a template against the package's full template input instead, and the
+// result is printed.  The expression can use the package's helper templates.
+func EvalCmd(
+	packageName string,
+	packageVersion string,
+	parametersFilePaths []string,
+	parameterOverrides []*params.Override,
+	kpmHomeDirPath string,
+	expression string,
+	valuesFormat string,
+) error {
+	var err error
+
+	// Get KPM home directory
+	var kpmHomeDir string
+	kpmHomeDir, err = files.GetAbsolutePath(kpmHomeDirPath)
+	if err != nil {
+		return err
+	}
+
+	// Validate package name
+	err = validation.ValidatePackageName(packageName)
+	if err != nil {
+		return err
+	}
+
+	// Validate package version
+	err = validation.ValidatePackageVersion(packageVersion)
+	if err != nil {
+		return err
+	}
+
+	// Get the paths of the parameters files
+	var absoluteParametersFilePaths = make([]string, len(parametersFilePaths))
+	for i, parametersFilePath := range parametersFilePaths {
+		absoluteParametersFilePaths[i], err = files.GetAbsolutePath(parametersFilePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	var packageFullName = template_package.GetPackageFullName(packageName, packageVersion)
+	var packageDirPath = template_package.GetPackageDir(kpmHomeDir, packageFullName)
+
+	// Log resolved values
+	log.Verbosef("====")
+	log.Verbosef("Package name:         %s", packageName)
+	log.Verbosef("Package version:      %s", packageVersion)
+	log.Verbosef("Package directory:    %s", packageDirPath)
+	log.Verbosef("Parameters files:     %s", strings.Join(absoluteParametersFilePaths, ", "))
+	log.Verbosef("Parameter overrides:  %d", len(parameterOverrides))
+	log.Verbosef("Expression:           %s", expression)
+	log.Verbosef("Values format:        %s", valuesFormat)
+	log.Verbosef("====")
+
+	if err = files.DirExists(packageDirPath, "template package"); err != nil {
+		return fmt.Errorf("failed to get package \"%s\": it is not in the local KPM repository", packageFullName)
+	}
+
+	// Get the parameters provided by the user
+	var packageParameters *map[string]any
+	packageParameters, _, err = getUserParameters(absoluteParametersFilePaths, parameterOverrides)
+	if err != nil {
+		return err
+	}
+
+	// Get the shared template, so the interface and the expression can use the package's helpers
+	var sharedTemplate *template.Template
+	sharedTemplate, err = template_package.GetSharedTemplate(packageDirPath, nil)
+	if err != nil {
+		return err
+	}
+
+	// Evaluate the interface
+	var templateInput *map[string]any
+	templateInput, err = template_package.GetTemplateInput(kpmHomeDir, packageFullName, sharedTemplate, packageParameters, nil)
+	if err != nil {
+		return err
+	}
+
+	if expression != "" {
+		return printExpression(sharedTemplate, expression, templateInput)
+	}
+
+	return printValues((*templateInput)[constants.TemplateFieldValues], valuesFormat)
+}
+
+// printExpression executes an expression as a template and prints the result.
+func printExpression(sharedTemplate *template.Template, expression string, templateInput *map[string]any) error {
+	var tmpl, err = templates.GetTemplateFromString(sharedTemplate, expressionTemplateName, expression)
+	if err != nil {
+		return fmt.Errorf("failed to parse expression: %s", err)
+	}
+
+	var outputBytes []byte
+	outputBytes, err = templates.ExecuteTemplate(tmpl, templateInput)
+	if err != nil {
+		return fmt.Errorf("failed to evaluate expression: %s", err)
+	}
+
+	log.Outputf("%s", strings.TrimSuffix(string(outputBytes), "\n"))
+
+	return nil
+}
+
+// printValues prints a package's values in the given format.
+func printValues(values any, valuesFormat string) (err error) {
+	// The interface may not generate any values
+	if typedValues, ok := values.(*map[string]any); !ok || typedValues == nil || *typedValues == nil {
+		values = map[string]any{}
+	}
+
+	var outputBytes []byte
+	switch valuesFormat {
+	case ValuesFormatYaml:
+		outputBytes, err = yaml.ObjectToBytes(values)
+	case ValuesFormatJson:
+		outputBytes, err = json.MarshalIndent(values, "", "  ")
+		if err != nil {
+			err = fmt.Errorf("failed to serialize values to json: %s", err)
+		}
+	default:
+		return fmt.Errorf("unknown values format \"%s\", expected one of: %s", valuesFormat, strings.Join(ValuesFormats, ", "))
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Outputf("%s", strings.TrimSuffix(string(outputBytes), "\n"))
+
+	return nil
+}
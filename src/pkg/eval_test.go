@@ -0,0 +1,93 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/params"
+)
+
+// captureOutput runs a function and returns what it printed to the output writer.
+func captureOutput(fn func() error) (string, error) {
+	var outputFile, err = os.CreateTemp("", "kpm-output-")
+	So(err, ShouldBeNil)
+	defer os.Remove(outputFile.Name())
+	defer outputFile.Close()
+
+	var originalWriterOut = log.WriterOut
+	log.WriterOut = outputFile
+	defer func() { log.WriterOut = originalWriterOut }()
+
+	var fnErr = fn()
+
+	var output []byte
+	output, err = os.ReadFile(outputFile.Name())
+	So(err, ShouldBeNil)
+
+	return string(output), fnErr
+}
+
+func TestEvalCmd(t *testing.T) {
+	Convey("Given a package in the KPM home directory", t, func() {
+		var kpmHomeDir = t.TempDir()
+		writeTestPackage(kpmHomeDir, "test/app", "1.0.0", map[string]string{
+			constants.InterfaceFileName:  "name: {{ .name }}\nreplicas: {{ .replicas }}\n",
+			constants.ParametersFileName: "name: app\nreplicas: 1\n",
+			"helpers/names.tpl":          "{{- define \"fullName\" -}}\n{{ .values.name }}-{{ .values.replicas }}\n{{- end -}}\n",
+		})
+		writeTestPackage(kpmHomeDir, "test/empty", "1.0.0", map[string]string{
+			constants.InterfaceFileName:  "",
+			constants.ParametersFileName: "{}\n",
+		})
+
+		var parametersFilePath = filepath.Join(t.TempDir(), "parameters.yaml")
+		So(os.WriteFile(parametersFilePath, []byte("replicas: 3\n"), 0644), ShouldBeNil)
+
+		Convey("The values are printed as YAML", func() {
+			var output, err = captureOutput(func() error {
+				return EvalCmd("test/app", "1.0.0", []string{parametersFilePath}, nil, kpmHomeDir, "", ValuesFormatYaml)
+			})
+			So(err, ShouldBeNil)
+			So(output, ShouldEqual, "name: app\nreplicas: 3\n")
+		})
+
+		Convey("The values are printed as JSON", func() {
+			var overrides, err = params.ParseSet("name=web")
+			So(err, ShouldBeNil)
+
+			var output string
+			output, err = captureOutput(func() error {
+				return EvalCmd("test/app", "1.0.0", nil, overrides, kpmHomeDir, "", ValuesFormatJson)
+			})
+			So(err, ShouldBeNil)
+			So(output, ShouldEqual, "{\n  \"name\": \"web\",\n  \"replicas\": 1\n}\n")
+		})
+
+		Convey("Expressions can use the package's helpers", func() {
+			var output, err = captureOutput(func() error {
+				return EvalCmd("test/app", "1.0.0", []string{parametersFilePath}, nil, kpmHomeDir, `{{ include "fullName" . }}`, ValuesFormatYaml)
+			})
+			So(err, ShouldBeNil)
+			So(output, ShouldEqual, "app-3\n")
+		})
+
+		Convey("An interface which produces no values prints an empty object", func() {
+			var output, err = captureOutput(func() error {
+				return EvalCmd("test/empty", "1.0.0", nil, nil, kpmHomeDir, "", ValuesFormatYaml)
+			})
+			So(err, ShouldBeNil)
+			So(output, ShouldEqual, "{}\n")
+
+			output, err = captureOutput(func() error {
+				return EvalCmd("test/empty", "1.0.0", nil, nil, kpmHomeDir, "", ValuesFormatJson)
+			})
+			So(err, ShouldBeNil)
+			So(output, ShouldEqual, "{}\n")
+		})
+	})
+}
@@ -11,11 +11,13 @@ import (
 	"golang.org/x/exp/slices"
 )
 
-// InspectCmd displays the given template package's parameters file.
+// InspectCmd displays the given template package's parameters file, or its parameters schema file if "showSchema" is
+// true.
 func InspectCmd(
 	packageName string,
 	packageVersion string,
 	kpmHomeDirPath string,
+	showSchema bool,
 ) error {
 	var err error
 
@@ -42,12 +44,14 @@ func InspectCmd(
 	var packageFullName = template_package.GetPackageFullName(packageName, packageVersion)
 	var packageDirPath = template_package.GetPackageDir(kpmHomeDir, packageFullName)
 	var parametersFilePath = template_package.GetDefaultParametersFile(packageDirPath)
+	var parametersSchemaFilePath = template_package.GetParametersSchemaFile(packageDirPath)
 
 	// Log resolved values
 	log.Verbosef("====")
 	log.Verbosef("Package name:      %s", packageName)
 	log.Verbosef("Package version:   %s", packageVersion)
 	log.Verbosef("Parameters file:   %s", parametersFilePath)
+	log.Verbosef("Schema file:       %s", parametersSchemaFilePath)
 	log.Verbosef("====")
 
 	// Check local repository for package
@@ -60,18 +64,27 @@ func InspectCmd(
 		return fmt.Errorf("failed to get package \"%s\": %s", packageFullName, err)
 	}
 
-	// Get the contents of the default parameters file
-	var parametersFile *os.File
-	parametersFile, err = os.Open(parametersFilePath)
+	// Choose the file to print
+	var filePath = parametersFilePath
+	if showSchema {
+		if files.FileExists(parametersSchemaFilePath, "parameters schema") != nil {
+			return fmt.Errorf("package \"%s\" does not have a parameters schema", packageFullName)
+		}
+		filePath = parametersSchemaFilePath
+	}
+
+	// Get the contents of the file
+	var file *os.File
+	file, err = os.Open(filePath)
 	if err != nil {
 		return err
 	}
 
 	// Make sure to close the file afterwards
-	defer parametersFile.Close()
+	defer file.Close()
 
-	// Print the contents of the default parameters file to output
-	log.OutputStream(parametersFile)
+	// Print the contents of the file to output
+	log.OutputStream(file)
 
 	return nil
 }
@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+)
+
+func TestInspectCmd(t *testing.T) {
+	Convey("Given packages with and without a parameters schema", t, func() {
+		var kpmHomeDir = t.TempDir()
+		var schema = `{"type": "object", "required": ["name"]}` + "\n"
+		writeTestPackage(kpmHomeDir, "test/schema", "1.0.0", map[string]string{
+			constants.InterfaceFileName:        "name: {{ .name }}\n",
+			constants.ParametersFileName:       "name: app\n",
+			constants.ParametersSchemaFileName: schema,
+		})
+		writeTestPackage(kpmHomeDir, "test/plain", "1.0.0", map[string]string{
+			constants.InterfaceFileName:  "name: {{ .name }}\n",
+			constants.ParametersFileName: "name: plain\n",
+		})
+
+		Convey("The default parameters are printed", func() {
+			var output, err = captureOutput(func() error {
+				return InspectCmd("test/schema", "1.0.0", kpmHomeDir, false)
+			})
+			So(err, ShouldBeNil)
+			So(output, ShouldEqual, "name: app\n")
+		})
+
+		Convey("The parameters schema is printed", func() {
+			var output, err = captureOutput(func() error {
+				return InspectCmd("test/schema", "1.0.0", kpmHomeDir, true)
+			})
+			So(err, ShouldBeNil)
+			So(output, ShouldEqual, schema)
+		})
+
+		Convey("Printing the schema of a package without one fails", func() {
+			var _, err = captureOutput(func() error {
+				return InspectCmd("test/plain", "1.0.0", kpmHomeDir, true)
+			})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "does not have a parameters schema")
+		})
+	})
+}
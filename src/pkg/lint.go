@@ -0,0 +1,45 @@
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+// LintCmd checks a local template package for problems without executing it, and prints each problem that was found
+// with its file and line.  An error is returned if any problems were found.
+func LintCmd(packagePath string) error {
+	var err error
+
+	// Package directory
+	var packageDirAbsPath string
+	packageDirAbsPath, err = files.GetAbsolutePath(packagePath)
+	if err != nil {
+		return err
+	}
+
+	// Log resolved paths
+	log.Verbosef("====")
+	log.Verbosef("Template package directory:  %s", packageDirAbsPath)
+	log.Verbosef("====")
+
+	var diagnostics []*template_package.Diagnostic
+	diagnostics, err = template_package.LintPackage(packageDirAbsPath)
+	if err != nil {
+		return err
+	}
+
+	for _, diagnostic := range diagnostics {
+		log.Outputf("%s", diagnostic)
+	}
+
+	if len(diagnostics) > 0 {
+		return fmt.Errorf("found %d problem(s) in package: %s", len(diagnostics), packageDirAbsPath)
+	}
+
+	log.Infof("No problems found in package: %s", packageDirAbsPath)
+
+	return nil
+}
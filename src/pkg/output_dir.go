@@ -0,0 +1,292 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/diff"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+// writeOutputFiles writes output files to the given directory, and records them in the directory's manifest.  Only
+// files whose contents have changed are rewritten, and only files which were generated by a previous run (according to
+// the manifest) are deleted.  Any other files are left alone, even if a file with the same path is generated, in which
+// case the generated file is skipped (and not recorded in the manifest) with a warning.
+func writeOutputFiles(outputDirPath string, outputFiles []*template_package.OutputFile) (err error) {
+	// Create the output directory even if there are no files, so it is clear that the package was run
+	if err = os.MkdirAll(outputDirPath, os.ModePerm); err != nil {
+		return err
+	}
+
+	// Get the files which were generated by the previous run
+	var manifestPath = template_package.GetManifestPath(outputDirPath)
+	var oldManifest *template_package.Manifest
+	oldManifest, err = template_package.ReadManifest(manifestPath)
+	if err != nil {
+		return err
+	}
+	var generatedDigests = oldManifest.GetDigests()
+
+	// Write the files which have changed
+	var newPaths = map[string]bool{}
+	var writtenFiles = make([]*template_package.OutputFile, 0, len(outputFiles))
+	var numUnchanged, numSkipped = 0, 0
+	for _, outputFile := range outputFiles {
+		newPaths[outputFile.Path] = true
+		var outputFilePath = filepath.Join(outputDirPath, filepath.FromSlash(outputFile.Path))
+
+		var existingData []byte
+		existingData, err = os.ReadFile(outputFilePath)
+		if err == nil {
+			var generatedDigest, wasGenerated = generatedDigests[outputFile.Path]
+			if !wasGenerated {
+				log.Warningf("Skipping generated file because a file which was not generated by KPM already exists: %s", outputFilePath)
+				numSkipped++
+				continue
+			} else if template_package.GetDataDigest(existingData) != generatedDigest {
+				log.Warningf("Overwriting generated file which was changed since it was generated: %s", outputFilePath)
+			}
+
+			if bytes.Equal(existingData, outputFile.Data) {
+				log.Debugf("Unchanged file: %s", outputFilePath)
+				writtenFiles = append(writtenFiles, outputFile)
+				numUnchanged++
+
+				if err = os.Chmod(outputFilePath, outputFile.Mode); err != nil {
+					return err
+				}
+
+				continue
+			}
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to read existing output file: %s\n%s", outputFilePath, err)
+		}
+
+		if err = os.MkdirAll(filepath.Dir(outputFilePath), os.ModePerm); err != nil {
+			return err
+		}
+
+		writtenFiles = append(writtenFiles, outputFile)
+		log.Verbosef("Writing file: %s", outputFilePath)
+		if err = os.WriteFile(outputFilePath, outputFile.Data, outputFile.Mode); err != nil {
+			return fmt.Errorf("failed to write output file: %s\n%s", outputFilePath, err)
+		}
+
+		// Existing files keep their permissions when they are written, so set them explicitly
+		if err = os.Chmod(outputFilePath, outputFile.Mode); err != nil {
+			return err
+		}
+	}
+
+	// Delete stale files which were generated by the previous run
+	var numDeleted = 0
+	for _, manifestFile := range oldManifest.Files {
+		if newPaths[manifestFile.Path] {
+			continue
+		}
+
+		var staleFilePath = filepath.Join(outputDirPath, filepath.FromSlash(manifestFile.Path))
+		var staleData []byte
+		staleData, err = os.ReadFile(staleFilePath)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to read stale output file: %s\n%s", staleFilePath, err)
+		}
+
+		if template_package.GetDataDigest(staleData) != manifestFile.Digest {
+			log.Warningf("Deleting stale generated file which was changed since it was generated: %s", staleFilePath)
+		}
+
+		log.Verbosef("Deleting stale file: %s", staleFilePath)
+		if err = os.Remove(staleFilePath); err != nil {
+			return err
+		}
+		numDeleted++
+
+		removeEmptyDirs(outputDirPath, filepath.Dir(staleFilePath))
+	}
+
+	// Let the user know about files that KPM didn't generate (other than the ones which were already reported as skipped)
+	var existingPaths []string
+	existingPaths, err = listOutputDir(outputDirPath)
+	if err != nil {
+		return err
+	}
+	for _, existingPath := range existingPaths {
+		if !newPaths[existingPath] {
+			log.Warningf("Leaving file which was not generated by KPM: %s", filepath.Join(outputDirPath, filepath.FromSlash(existingPath)))
+		}
+	}
+
+	// Record the generated files
+	if err = template_package.WriteManifest(manifestPath, template_package.NewManifest(writtenFiles)); err != nil {
+		return err
+	}
+
+	log.Verbosef(
+		"Generated %d files in output directory (%d unchanged, %d skipped, %d stale files deleted): %s",
+		len(writtenFiles),
+		numUnchanged,
+		numSkipped,
+		numDeleted,
+		outputDirPath,
+	)
+
+	return nil
+}
+
+// removeEmptyDirs removes the given directory and its parents while they are empty, stopping at the root directory.
+func removeEmptyDirs(rootDirPath string, dirPath string) {
+	for dirPath != rootDirPath && strings.HasPrefix(dirPath, rootDirPath+string(filepath.Separator)) {
+		// Removing a directory fails if it isn't empty
+		if os.Remove(dirPath) != nil {
+			return
+		}
+
+		dirPath = filepath.Dir(dirPath)
+	}
+}
+
+// listOutputDir returns the paths of the files in an existing output directory relative to the directory (using forward
+// slashes), excluding the manifest.  If the directory doesn't exist, there are no files.
+func listOutputDir(outputDirPath string) ([]string, error) {
+	var result = []string{}
+
+	if _, err := os.Stat(outputDirPath); os.IsNotExist(err) {
+		return result, nil
+	}
+
+	var err = filepath.WalkDir(outputDirPath, func(filePath string, dirEntry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if dirEntry.IsDir() {
+			return nil
+		}
+
+		var relativePath, err = filepath.Rel(outputDirPath, filePath)
+		if err != nil {
+			log.Panicf("Failed to get relative path of output file: %s", err)
+		}
+
+		relativePath = filepath.ToSlash(relativePath)
+		if relativePath != constants.ManifestFileName {
+			result = append(result, relativePath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output directory: %s\n%s", outputDirPath, err)
+	}
+
+	return result, nil
+}
+
+// diffOutputDir prints a unified diff between the files in an existing output directory and the given output files.
+// Paths in the diff are prefixed with the output name.  Files which were not generated by KPM are ignored, since they
+// wouldn't be changed (even if a file with the same path is generated).  An error is returned if there are any
+// differences.
+func diffOutputDir(outputDirPath string, outputName string, outputFiles []*template_package.OutputFile) error {
+	var existingPaths, err = listOutputDir(outputDirPath)
+	if err != nil {
+		return err
+	}
+
+	var manifest *template_package.Manifest
+	manifest, err = template_package.ReadManifest(template_package.GetManifestPath(outputDirPath))
+	if err != nil {
+		return err
+	}
+	var generatedDigests = manifest.GetDigests()
+
+	var newFiles = map[string][]byte{}
+	for _, outputFile := range outputFiles {
+		newFiles[outputFile.Path] = outputFile.Data
+	}
+
+	// Compare the files in a stable order
+	var allPaths = []string{}
+	var existingFiles = map[string]bool{}
+	for _, existingPath := range existingPaths {
+		existingFiles[existingPath] = true
+
+		var _, wasGenerated = generatedDigests[existingPath]
+		if !wasGenerated {
+			log.Warningf("Ignoring file which was not generated by KPM: %s", filepath.Join(outputDirPath, filepath.FromSlash(existingPath)))
+			delete(newFiles, existingPath)
+			continue
+		}
+
+		allPaths = append(allPaths, existingPath)
+	}
+	for relativePath := range newFiles {
+		if !existingFiles[relativePath] {
+			allPaths = append(allPaths, relativePath)
+		}
+	}
+	sort.Strings(allPaths)
+
+	var numAdded, numRemoved, numChanged int
+	for _, relativePath := range allPaths {
+		var displayPath = path.Join(outputName, relativePath)
+		var oldName, newName = "a/" + displayPath, "b/" + displayPath
+
+		var oldData, newData []byte
+		var existed, exists = existingFiles[relativePath], false
+		if existed {
+			var existingFilePath = filepath.Join(outputDirPath, filepath.FromSlash(relativePath))
+			if oldData, err = os.ReadFile(existingFilePath); err != nil {
+				return fmt.Errorf("failed to read existing output file: %s\n%s", existingFilePath, err)
+			}
+		}
+		newData, exists = newFiles[relativePath]
+
+		switch {
+		case !existed:
+			numAdded++
+			oldName = diff.NullFileName
+		case !exists:
+			numRemoved++
+			newName = diff.NullFileName
+		}
+
+		var fileDiff = diff.Unified(oldName, newName, oldData, newData)
+		if fileDiff == "" {
+			if existed && exists {
+				continue
+			}
+
+			// Empty files have no lines to show, but they are still added or removed
+			fileDiff = fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName)
+		}
+		if existed && exists {
+			numChanged++
+		}
+
+		log.Outputf("%s", strings.TrimSuffix(fileDiff, "\n"))
+	}
+
+	if numAdded+numRemoved+numChanged == 0 {
+		log.Infof("No differences in output directory: %s", outputDirPath)
+		return nil
+	}
+
+	return fmt.Errorf(
+		"output directory would change (%d added, %d removed, %d changed): %s",
+		numAdded,
+		numRemoved,
+		numChanged,
+		outputDirPath,
+	)
+}
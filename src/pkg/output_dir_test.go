@@ -0,0 +1,113 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+func TestWriteOutputFiles(t *testing.T) {
+	Convey("Given an output directory which was generated before", t, func() {
+		var outputDir = t.TempDir()
+		So(writeOutputFiles(outputDir, []*template_package.OutputFile{
+			{Path: "same.txt", Mode: 0644, Data: []byte("same")},
+			{Path: "changed.txt", Mode: 0644, Data: []byte("old")},
+			{Path: "stale/nested/stale.txt", Mode: 0644, Data: []byte("stale")},
+		}), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(outputDir, "manual.txt"), []byte("manual"), 0644), ShouldBeNil)
+
+		// Make it possible to tell whether a file was rewritten
+		var oldTime = time.Now().Add(-time.Hour)
+		So(os.Chtimes(filepath.Join(outputDir, "same.txt"), oldTime, oldTime), ShouldBeNil)
+
+		Convey("Only changed files are rewritten and only stale generated files are deleted", func() {
+			So(writeOutputFiles(outputDir, []*template_package.OutputFile{
+				{Path: "same.txt", Mode: 0644, Data: []byte("same")},
+				{Path: "changed.txt", Mode: 0644, Data: []byte("new")},
+				{Path: "added.txt", Mode: 0644, Data: []byte("added")},
+			}), ShouldBeNil)
+
+			var fileInfo, err = os.Stat(filepath.Join(outputDir, "same.txt"))
+			So(err, ShouldBeNil)
+			So(fileInfo.ModTime().Unix(), ShouldEqual, oldTime.Unix())
+
+			var data []byte
+			data, err = os.ReadFile(filepath.Join(outputDir, "changed.txt"))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "new")
+
+			data, err = os.ReadFile(filepath.Join(outputDir, "added.txt"))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "added")
+
+			data, err = os.ReadFile(filepath.Join(outputDir, "manual.txt"))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "manual")
+
+			_, err = os.Stat(filepath.Join(outputDir, "stale"))
+			So(os.IsNotExist(err), ShouldBeTrue)
+
+			var manifest *template_package.Manifest
+			manifest, err = template_package.ReadManifest(template_package.GetManifestPath(outputDir))
+			So(err, ShouldBeNil)
+			So(manifest.Files, ShouldHaveLength, 3)
+			So(manifest.Files[0].Path, ShouldEqual, "added.txt")
+		})
+
+		Convey("Files which were not generated by KPM are not overwritten", func() {
+			var outputFiles = []*template_package.OutputFile{
+				{Path: "same.txt", Mode: 0644, Data: []byte("same")},
+				{Path: "manual.txt", Mode: 0644, Data: []byte("generated")},
+			}
+			So(writeOutputFiles(outputDir, outputFiles), ShouldBeNil)
+
+			var data, err = os.ReadFile(filepath.Join(outputDir, "manual.txt"))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "manual")
+
+			// The skipped file isn't recorded, so it isn't treated as generated by later runs
+			var manifest *template_package.Manifest
+			manifest, err = template_package.ReadManifest(template_package.GetManifestPath(outputDir))
+			So(err, ShouldBeNil)
+			So(manifest.Files, ShouldHaveLength, 1)
+			So(manifest.Files[0].Path, ShouldEqual, "same.txt")
+
+			So(writeOutputFiles(outputDir, outputFiles), ShouldBeNil)
+			data, err = os.ReadFile(filepath.Join(outputDir, "manual.txt"))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "manual")
+			So(diffOutputDir(outputDir, "output", outputFiles), ShouldBeNil)
+		})
+
+		Convey("Manifests with paths outside the output directory are rejected", func() {
+			var outsideFilePath = filepath.Join(filepath.Dir(outputDir), "outside.txt")
+			So(os.WriteFile(outsideFilePath, []byte("outside"), 0644), ShouldBeNil)
+			So(template_package.WriteManifest(template_package.GetManifestPath(outputDir), &template_package.Manifest{
+				ApiVersion: template_package.ManifestApiVersion,
+				Files:      []*template_package.ManifestFile{{Path: "../outside.txt", Digest: "sha256:aaa"}},
+			}), ShouldBeNil)
+
+			So(writeOutputFiles(outputDir, []*template_package.OutputFile{}), ShouldNotBeNil)
+
+			var _, err = os.Stat(outsideFilePath)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("A dry run only reports differences in generated files", func() {
+			So(diffOutputDir(outputDir, "output", []*template_package.OutputFile{
+				{Path: "same.txt", Mode: 0644, Data: []byte("same")},
+				{Path: "changed.txt", Mode: 0644, Data: []byte("old")},
+				{Path: "stale/nested/stale.txt", Mode: 0644, Data: []byte("stale")},
+			}), ShouldBeNil)
+
+			So(diffOutputDir(outputDir, "output", []*template_package.OutputFile{
+				{Path: "same.txt", Mode: 0644, Data: []byte("same")},
+			}), ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,155 @@
+package pkg
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+// OutputFormatDir writes the output files to the output directory.
+const OutputFormatDir = "dir"
+
+// OutputFormatYaml writes the output files to stdout as a single stream of YAML documents.
+const OutputFormatYaml = "yaml"
+
+// OutputFormatTar writes the output files to stdout as a tar archive.
+const OutputFormatTar = "tar"
+
+// OutputFormats is the list of supported output formats.
+var OutputFormats = []string{OutputFormatDir, OutputFormatYaml, OutputFormatTar}
+
+// outputSink receives the files generated by the packages in a dependency tree.
+type outputSink interface {
+	// AddFile adds a generated file to the output.  Its path is relative to the root package's output directory.
+	AddFile(outputFile *template_package.OutputFile) error
+
+	// Close is called once every package has been executed successfully, and finishes writing the output.  Sinks
+	// shouldn't write anything before this, so nothing is changed if a package fails.
+	Close() error
+}
+
+// newOutputSink creates the output sink for the given output format.  Files written to a stream are named relative to
+// the output directory, so they are prefixed with the output name.
+func newOutputSink(
+	outputFormat string,
+	packageOutputDirPath string,
+	outputName string,
+	writer io.Writer,
+	dryRun bool,
+) (outputSink, error) {
+	switch outputFormat {
+	case OutputFormatDir:
+		return &dirOutputSink{outputDirPath: packageOutputDirPath, outputName: outputName, dryRun: dryRun}, nil
+	case OutputFormatYaml:
+		return &yamlOutputSink{outputName: outputName, writer: writer}, nil
+	case OutputFormatTar:
+		return &tarOutputSink{outputName: outputName, writer: writer}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format \"%s\" (must be one of: %s)", outputFormat, strings.Join(OutputFormats, ", "))
+	}
+}
+
+// dirOutputSink writes output files to a package's output directory, or prints the differences from the existing files.
+type dirOutputSink struct {
+	outputDirPath string
+	outputName    string
+	dryRun        bool
+	outputFiles   []*template_package.OutputFile
+}
+
+func (sink *dirOutputSink) AddFile(outputFile *template_package.OutputFile) error {
+	// The manifest is written next to the output files, so it can't be replaced by one of them
+	if outputFile.Path == constants.ManifestFileName {
+		return fmt.Errorf("'%s' can't generate the output file '%s', since it is reserved for KPM's manifest", outputFile.Source, outputFile.Path)
+	}
+
+	sink.outputFiles = append(sink.outputFiles, outputFile)
+
+	return nil
+}
+
+func (sink *dirOutputSink) Close() error {
+	if sink.dryRun {
+		return diffOutputDir(sink.outputDirPath, sink.outputName, sink.outputFiles)
+	}
+
+	return writeOutputFiles(sink.outputDirPath, sink.outputFiles)
+}
+
+// yamlOutputSink writes output files to a stream as YAML documents separated by "---", each preceded by a comment with
+// the file's path.
+type yamlOutputSink struct {
+	outputName  string
+	writer      io.Writer
+	outputFiles []*template_package.OutputFile
+}
+
+func (sink *yamlOutputSink) AddFile(outputFile *template_package.OutputFile) error {
+	sink.outputFiles = append(sink.outputFiles, outputFile)
+
+	return nil
+}
+
+func (sink *yamlOutputSink) Close() error {
+	var builder = new(strings.Builder)
+	for _, outputFile := range sink.outputFiles {
+		fmt.Fprintf(builder, "---\n# Source: %s\n", path.Join(sink.outputName, outputFile.Path))
+		builder.Write(outputFile.Data)
+		if len(outputFile.Data) > 0 && !strings.HasSuffix(string(outputFile.Data), "\n") {
+			builder.WriteString("\n")
+		}
+	}
+
+	if _, err := io.WriteString(sink.writer, builder.String()); err != nil {
+		return fmt.Errorf("failed to write output: %s", err)
+	}
+
+	return nil
+}
+
+// tarOutputSink writes output files to a stream as a tar archive.
+type tarOutputSink struct {
+	outputName  string
+	writer      io.Writer
+	outputFiles []*template_package.OutputFile
+}
+
+func (sink *tarOutputSink) AddFile(outputFile *template_package.OutputFile) error {
+	sink.outputFiles = append(sink.outputFiles, outputFile)
+
+	return nil
+}
+
+func (sink *tarOutputSink) Close() (err error) {
+	var tarWriter = tar.NewWriter(sink.writer)
+	for _, outputFile := range sink.outputFiles {
+		// Use a fixed modification time, so the same output always produces the same archive
+		var header = &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Join(sink.outputName, outputFile.Path),
+			Mode:     int64(outputFile.Mode.Perm()),
+			Size:     int64(len(outputFile.Data)),
+			ModTime:  time.Unix(0, 0),
+			Format:   tar.FormatPAX,
+		}
+
+		if err = tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write output file to tar archive: %s\n%s", header.Name, err)
+		}
+		if _, err = tarWriter.Write(outputFile.Data); err != nil {
+			return fmt.Errorf("failed to write output file to tar archive: %s\n%s", header.Name, err)
+		}
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write tar archive: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package pkg
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+func TestOutputSinks(t *testing.T) {
+	Convey("Given some output files", t, func() {
+		var outputFiles = []*template_package.OutputFile{
+			{Path: "a.yaml", Mode: 0644, Data: []byte("a: 1\n")},
+			{Path: "dep/b.yaml", Mode: 0755, Data: []byte("b: 2")},
+		}
+		var buffer = new(bytes.Buffer)
+
+		var addFiles = func(sink outputSink) {
+			for _, outputFile := range outputFiles {
+				So(sink.AddFile(outputFile), ShouldBeNil)
+			}
+		}
+
+		Convey("The YAML sink writes nothing until it is closed, and then writes separated documents", func() {
+			var sink, err = newOutputSink(OutputFormatYaml, "", "out", buffer, false)
+			So(err, ShouldBeNil)
+
+			addFiles(sink)
+			So(buffer.Len(), ShouldEqual, 0)
+
+			So(sink.Close(), ShouldBeNil)
+			So(buffer.String(), ShouldEqual, "---\n# Source: out/a.yaml\na: 1\n---\n# Source: out/dep/b.yaml\nb: 2\n")
+		})
+
+		Convey("The tar sink writes an archive with the file paths and modes", func() {
+			var sink, err = newOutputSink(OutputFormatTar, "", "out", buffer, false)
+			So(err, ShouldBeNil)
+
+			addFiles(sink)
+			So(sink.Close(), ShouldBeNil)
+
+			var tarReader = tar.NewReader(buffer)
+			for _, outputFile := range outputFiles {
+				var header *tar.Header
+				header, err = tarReader.Next()
+				So(err, ShouldBeNil)
+				So(header.Name, ShouldEqual, "out/"+outputFile.Path)
+				So(header.Mode, ShouldEqual, int64(outputFile.Mode))
+
+				var data []byte
+				data, err = io.ReadAll(tarReader)
+				So(err, ShouldBeNil)
+				So(data, ShouldResemble, outputFile.Data)
+			}
+
+			_, err = tarReader.Next()
+			So(err, ShouldEqual, io.EOF)
+		})
+
+		Convey("The directory sink writes the files to the output directory when it is closed", func() {
+			var outputDir = filepath.Join(t.TempDir(), "out")
+			var sink, err = newOutputSink(OutputFormatDir, outputDir, "out", buffer, false)
+			So(err, ShouldBeNil)
+
+			addFiles(sink)
+			_, err = os.Stat(outputDir)
+			So(os.IsNotExist(err), ShouldBeTrue)
+
+			So(sink.Close(), ShouldBeNil)
+
+			var data []byte
+			data, err = os.ReadFile(filepath.Join(outputDir, "dep", "b.yaml"))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "b: 2")
+			So(buffer.Len(), ShouldEqual, 0)
+		})
+
+		Convey("The directory sink rejects files which would replace the manifest", func() {
+			var sink, err = newOutputSink(OutputFormatDir, t.TempDir(), "out", buffer, false)
+			So(err, ShouldBeNil)
+
+			So(sink.AddFile(&template_package.OutputFile{Path: constants.ManifestFileName, Mode: 0644}), ShouldNotBeNil)
+		})
+
+		Convey("Unknown output formats are rejected", func() {
+			var _, err = newOutputSink("json", "", "out", buffer, false)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -1,14 +1,25 @@
 package pkg
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/archive"
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
 	"github.com/rohitramu/kpm/src/pkg/utils/files"
 	"github.com/rohitramu/kpm/src/pkg/utils/log"
 	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/user_prompts"
 )
 
-// PackCmd packs a local template package so it is available for use in the given local KPM repository.
+// PackCmd packs a local template package so it is available for use in the given local KPM repository.  The package
+// may be a directory or a package archive.  If an archive path is provided, the package is written to a package
+// archive at that path instead.
 func PackCmd(
-	packageDirPath string,
+	packagePath string,
+	archivePath string,
 	kpmHomeDirPath string,
 	userHasConfirmed bool,
 ) error {
@@ -16,11 +27,28 @@ func PackCmd(
 
 	// Package directory
 	var packageDirAbsPath string
-	packageDirAbsPath, err = files.GetAbsolutePath(packageDirPath)
+	packageDirAbsPath, err = files.GetAbsolutePath(packagePath)
 	if err != nil {
 		return err
 	}
 
+	// If the package is an archive, extract it so it can be validated.
+	if strings.HasSuffix(packageDirAbsPath, constants.PackageArchiveExtension) {
+		var tempDir string
+		tempDir, err = os.MkdirTemp("", "kpm-pack-")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tempDir)
+
+		log.Debugf("Extracting package archive: %s", packageDirAbsPath)
+		if _, err = archive.ExtractPackageArchiveFile(packageDirAbsPath, tempDir); err != nil {
+			return err
+		}
+
+		packageDirAbsPath = tempDir
+	}
+
 	// Get KPM home directory
 	var kpmHomeDir string
 	kpmHomeDir, err = files.GetAbsolutePath(kpmHomeDirPath)
@@ -41,6 +69,28 @@ func PackCmd(
 		return err
 	}
 
+	// Check which static files will be copied to the output
+	var staticFilePaths []string
+	staticFilePaths, err = template_package.GetStaticFilePaths(packageDirAbsPath)
+	if err != nil {
+		return err
+	}
+	log.Verbosef("Found %d static files", len(staticFilePaths))
+
+	var unmatchedIncludes []string
+	unmatchedIncludes, err = template_package.GetUnmatchedStaticFileIncludes(packageDirAbsPath)
+	if err != nil {
+		return err
+	}
+	for _, pattern := range unmatchedIncludes {
+		log.Warningf("Static file include pattern doesn't match any files in the \"%s\" directory: %s", constants.StaticFilesDirName, pattern)
+	}
+
+	// Write the archive instead if one was requested.
+	if archivePath != "" {
+		return writePackageArchive(packageDirAbsPath, packageInfo, archivePath, userHasConfirmed)
+	}
+
 	// Get package name with version and output path
 	var packageNameWithVersion = template_package.GetPackageFullName(packageInfo.Name, packageInfo.Version)
 	var outputDir = template_package.GetPackageDir(kpmHomeDir, packageNameWithVersion)
@@ -62,3 +112,44 @@ func PackCmd(
 
 	return nil
 }
+
+func writePackageArchive(
+	packageDir string,
+	packageInfo *template_package.PackageInfo,
+	archivePath string,
+	userHasConfirmed bool,
+) (err error) {
+	var archiveAbsPath string
+	archiveAbsPath, err = files.GetAbsolutePath(archivePath)
+	if err != nil {
+		return err
+	}
+
+	// Confirm before overwriting an existing archive.
+	if files.FileExists(archiveAbsPath, "package archive") == nil && !userHasConfirmed {
+		if userHasConfirmed, err = user_prompts.ConfirmWithUser("Package archive exists, so it will be overwritten: %s", archiveAbsPath); err != nil {
+			return err
+		}
+
+		if !userHasConfirmed {
+			return fmt.Errorf("operation cancelled - user did not confirm overwriting of pre-existing package archive")
+		}
+	}
+
+	if err = os.MkdirAll(filepath.Dir(archiveAbsPath), os.ModePerm); err != nil {
+		return err
+	}
+
+	log.Debugf("Writing package archive to: %s", archiveAbsPath)
+	if err = archive.WritePackageArchiveFile(packageDir, archiveAbsPath); err != nil {
+		return err
+	}
+
+	log.Verbosef("====")
+	log.Verbosef("Template package name:    %s", packageInfo.Name)
+	log.Verbosef("Template package version: %s", packageInfo.Version)
+	log.Verbosef("Package archive:          %s", archiveAbsPath)
+	log.Verbosef("====")
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_repository"
+)
+
+var _ template_package.PackageFetcher = &repositoryPackageFetcher{}
+
+// repositoryPackageFetcher pulls packages which are missing from the KPM home directory from repositories, preferring
+// the repositories that were recorded in the lock file.
+type repositoryPackageFetcher struct {
+	kpmHomeDir     string
+	repos          *template_repository.RepositoryCollection
+	lockedOutput   *template_package.LockedOutput
+	frozenLockfile bool
+
+	// pulledFrom maps the full names of the packages which were pulled to the repositories they were pulled from.
+	pulledFrom map[string]string
+}
+
+func (fetcher *repositoryPackageFetcher) GetPackageVersions(packageName string) ([]string, error) {
+	return fetcher.repos.GetPackageVersions(fetcher.kpmHomeDir, packageName)
+}
+
+func (fetcher *repositoryPackageFetcher) FetchPackage(packageInfo *template_package.PackageInfo) (err error) {
+	var lockedRepoName, isLocked = fetcher.lockedOutput.GetRepository(packageInfo)
+	if fetcher.frozenLockfile {
+		if !isLocked {
+			return fmt.Errorf("package '%s' is not in the lock file", packageInfo)
+		}
+		if lockedRepoName == "" {
+			return fmt.Errorf("the lock file does not record a repository for package '%s'", packageInfo)
+		}
+	}
+
+	var repoName string
+	repoName, err = PullPackage(fetcher.kpmHomeDir, fetcher.repos, lockedRepoName, packageInfo.Name, packageInfo.Version)
+	if err != nil && lockedRepoName != "" && !fetcher.frozenLockfile {
+		log.Warningf("Failed to pull package '%s' from locked repository '%s': %s", packageInfo, lockedRepoName, err)
+		repoName, err = PullPackage(fetcher.kpmHomeDir, fetcher.repos, "", packageInfo.Name, packageInfo.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	fetcher.pulledFrom[packageInfo.String()] = repoName
+
+	return nil
+}
+
+// getRepository returns the repository that a package was pulled from, or the repository that it was originally
+// pulled from according to the lock file.
+func (fetcher *repositoryPackageFetcher) getRepository(packageInfo *template_package.PackageInfo) string {
+	if repoName, found := fetcher.pulledFrom[packageInfo.String()]; found {
+		return repoName
+	}
+
+	var repoName, _ = fetcher.lockedOutput.GetRepository(packageInfo)
+	return repoName
+}
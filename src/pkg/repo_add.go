@@ -0,0 +1,23 @@
+package pkg
+
+import (
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_repository"
+)
+
+// AddRepository adds a repository to the end of the list of repositories in a KPM config file.
+func AddRepository(
+	configFilePath string,
+	repoName string,
+	repoType string,
+	connection string,
+) (err error) {
+	err = template_repository.AddRepositoryToConfigFile(configFilePath, repoName, repoType, connection)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Added repository '%s' to config file: %s", repoName, configFilePath)
+
+	return nil
+}
@@ -26,7 +26,7 @@ func FindPackages(
 			return err
 		}
 
-		err = repo.FindPackages(ch, searchTerm)
+		err = repo.FindPackages(kpmHomeDir, ch, searchTerm)
 		if err != nil && !errors.Is(err, template_repository.PackageNotFoundError{}) {
 			return err
 		}
@@ -0,0 +1,53 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_repository"
+	"github.com/rohitramu/kpm/src/pkg/utils/yaml"
+)
+
+// IndexRepository generates the index file for a directory of package archives, so it can be served as an HTTP repository.
+func IndexRepository(repoDirPath string) (err error) {
+	var repoDir string
+	repoDir, err = files.GetAbsolutePath(repoDirPath)
+	if err != nil {
+		return err
+	}
+
+	if err = files.DirExists(repoDir, "repository"); err != nil {
+		return err
+	}
+
+	var index *template_repository.PackageIndex
+	index, err = template_repository.GeneratePackageIndex(repoDir)
+	if err != nil {
+		return fmt.Errorf("failed to index repository directory '%s': %s", repoDir, err)
+	}
+
+	var indexBytes []byte
+	indexBytes, err = yaml.ObjectToBytes(index)
+	if err != nil {
+		return err
+	}
+
+	// Overwrite the previous index, if there is one.
+	var indexFilePath = filepath.Join(repoDir, constants.PackageIndexFileName)
+	err = os.WriteFile(indexFilePath, indexBytes, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write index file '%s': %s", indexFilePath, err)
+	}
+
+	var numArchives = 0
+	for _, entries := range index.Packages {
+		numArchives += len(entries)
+	}
+	log.Infof("Indexed %d package archive(s): %s", numArchives, indexFilePath)
+
+	return nil
+}
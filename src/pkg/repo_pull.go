@@ -7,16 +7,18 @@ import (
 	"github.com/rohitramu/kpm/src/pkg/utils/template_repository"
 )
 
+// PullPackage pulls a package from the given repository, or from the first repository which has the package if no
+// repository name is provided.  It returns the name of the repository that the package was pulled from.
 func PullPackage(
 	kpmHomeDir string,
 	repos *template_repository.RepositoryCollection,
 	repoName string,
 	packageName string,
 	packageVersion string,
-) (err error) {
+) (pulledFrom string, err error) {
 	var repoNames = repos.GetRepositoryNames()
 	if len(repoNames) == 0 {
-		return errors.New("no repositories configured")
+		return "", errors.New("no repositories configured")
 	}
 
 	var packageInfo = &template_package.PackageInfo{
@@ -29,10 +31,10 @@ func PullPackage(
 		var repo template_repository.Repository
 		repo, err = repos.GetRepository(repoName)
 		if err != nil {
-			return err
+			return "", err
 		}
 
-		return repo.Pull(kpmHomeDir, packageInfo)
+		return repoName, repo.Pull(kpmHomeDir, packageInfo)
 	}
 
 	// If repo name wasn't provided, check all repos for the package.
@@ -40,13 +42,13 @@ func PullPackage(
 		var repo template_repository.Repository
 		repo, err = repos.GetRepository(repoName)
 		if err != nil {
-			return err
+			return "", err
 		}
 
 		err = repo.Pull(kpmHomeDir, packageInfo)
 		if err == nil {
 			// Found the package, so return.
-			return nil
+			return repoName, nil
 		}
 
 		// If the package wasn't found, continue looking in other repos.
@@ -54,8 +56,8 @@ func PullPackage(
 			continue
 		}
 
-		return err
+		return "", err
 	}
 
-	return template_repository.PackageNotFoundError{PackageInfo: *packageInfo}
+	return "", template_repository.PackageNotFoundError{PackageInfo: *packageInfo}
 }
@@ -22,7 +22,7 @@ func PushPackage(
 	}
 
 	if packageVersion == "" {
-		packageVersion, err = template_package.GetHighestPackageVersion(kpmHomeDir, packageName)
+		packageVersion, err = template_package.GetHighestPackageVersion(kpmHomeDir, packageName, false)
 		if err != nil {
 			return err
 		}
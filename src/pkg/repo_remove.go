@@ -0,0 +1,39 @@
+package pkg
+
+import (
+	"fmt"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_repository"
+	"github.com/rohitramu/kpm/src/pkg/utils/user_prompts"
+)
+
+// RemoveRepository removes a repository from the list of repositories in a KPM config file.
+func RemoveRepository(
+	configFilePath string,
+	repoName string,
+	userHasConfirmed bool,
+) (err error) {
+	if !userHasConfirmed {
+		if userHasConfirmed, err = user_prompts.ConfirmWithUser(
+			"Removing repository '%s' from config file: %s",
+			repoName,
+			configFilePath,
+		); err != nil {
+			return err
+		}
+
+		if !userHasConfirmed {
+			return fmt.Errorf("operation cancelled - user did not confirm removal of repository '%s'", repoName)
+		}
+	}
+
+	err = template_repository.RemoveRepositoryFromConfigFile(configFilePath, repoName)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Removed repository '%s' from config file: %s", repoName, configFilePath)
+
+	return nil
+}
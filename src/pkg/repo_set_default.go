@@ -0,0 +1,22 @@
+package pkg
+
+import (
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_repository"
+)
+
+// SetDefaultRepository makes a repository the default one, by moving it to the start of the list of repositories in
+// a KPM config file.
+func SetDefaultRepository(
+	configFilePath string,
+	repoName string,
+) (err error) {
+	err = template_repository.SetDefaultRepositoryInConfigFile(configFilePath, repoName)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("Repository '%s' is now the default repository in config file: %s", repoName, configFilePath)
+
+	return nil
+}
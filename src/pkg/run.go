@@ -2,31 +2,75 @@ package pkg
 
 import (
 	"fmt"
-	"os"
+	"path"
 	"path/filepath"
 	"strings"
-	"text/template"
 
 	"github.com/rohitramu/kpm/src/pkg/utils/files"
 	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/params"
 	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
-	"github.com/rohitramu/kpm/src/pkg/utils/templates"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_repository"
 	"github.com/rohitramu/kpm/src/pkg/utils/validation"
+	"github.com/rohitramu/kpm/src/pkg/utils/versions"
 )
 
-// RunCmd runs the given template package directory and parameters file,
-// and then writes the output files to the given output directory.
+// RunOptions are the options for running a template package with "RunCmd".
+type RunOptions struct {
+	// ParametersFilePaths are deep-merged in order on top of the package's default parameters.
+	ParametersFilePaths []string
+
+	// ParameterOverrides are applied in order on top of the parameters files.
+	ParameterOverrides []*params.Override
+
+	// OutputDirPath is the directory which the package's output directory is created in, and which contains the lock file.
+	OutputDirPath string
+
+	// OutputName is the name of the package's output directory, or nil to use the default output name.
+	OutputName *string
+
+	// Offline stops packages which are missing from the KPM home directory from being pulled from repositories.
+	Offline bool
+
+	// FrozenLockfile requires the packages to exactly match the lock file, and stops the lock file from being updated.
+	FrozenLockfile bool
+
+	// IncludePrerelease allows prerelease versions to be chosen when resolving versions.
+	IncludePrerelease bool
+
+	// DryRun prints the differences from the existing output instead of writing any files, and returns an error if there
+	// are any.  Dry runs are only supported with the "dir" output format.
+	DryRun bool
+
+	// OutputFormat is one of "OutputFormats".
+	OutputFormat string
+
+	// ExplainValues prints the parameters and values of each package in the dependency tree, along with where each
+	// parameter came from, instead of writing any files.
+	ExplainValues bool
+}
+
+// RunCmd runs the given template package with the given options (see "RunOptions"), and then writes the output files in
+// the chosen output format.
+// With the "dir" format, the files are written to the output directory.  Only files which have changed are written, and
+// only stale files which were generated by a previous run are deleted.  With the other formats, the files are written to
+// stdout instead, and the lock file is read but not written.
+// Unless running offline, packages which are missing from the KPM home directory are pulled from the given repositories.
+// The packages which were used are recorded in the lock file in the output directory, which is reused by later runs.
 func RunCmd(
 	packageName string,
 	packageVersion string,
-	optionalParametersFilePath *string,
-	outputDirPath string,
-	optionalOutputName *string,
 	kpmHomeDirPath string,
-	userHasConfirmed bool,
+	repos *template_repository.RepositoryCollection,
+	options *RunOptions,
 ) error {
 	var err error
 
+	// Validate the output format
+	if options.DryRun && options.OutputFormat != OutputFormatDir {
+		return fmt.Errorf("a dry run can only be done with the \"%s\" output format", OutputFormatDir)
+	}
+
 	// Get KPM home directory
 	var kpmHomeDir string
 	kpmHomeDir, err = files.GetAbsolutePath(kpmHomeDirPath)
@@ -40,6 +84,49 @@ func RunCmd(
 		return err
 	}
 
+	// Read the lock file, if there is one
+	var lockFilePath = template_package.GetLockFilePath(options.OutputDirPath)
+	var lockFile *template_package.LockFile
+	if files.FileExists(lockFilePath, "lock file") == nil {
+		lockFile, err = template_package.ReadLockFile(lockFilePath)
+		if err != nil {
+			return err
+		}
+	} else if options.FrozenLockfile {
+		return fmt.Errorf("lock file does not exist: %s", lockFilePath)
+	} else {
+		lockFile = template_package.NewLockFile()
+	}
+
+	// Get the fetcher for missing packages
+	var repoFetcher = &repositoryPackageFetcher{
+		kpmHomeDir:     kpmHomeDir,
+		repos:          repos,
+		frozenLockfile: options.FrozenLockfile,
+		pulledFrom:     map[string]string{},
+	}
+	var fetcher template_package.PackageFetcher
+	if !options.Offline {
+		fetcher = repoFetcher
+	}
+
+	// If an exact package version was not provided, use the locked version or resolve the version constraint
+	if validation.ValidatePackageVersion(packageVersion) != nil {
+		packageVersion, err = resolveRootPackageVersion(
+			kpmHomeDir,
+			lockFile,
+			packageName,
+			packageVersion,
+			options.OutputName,
+			fetcher,
+			options.FrozenLockfile,
+			options.IncludePrerelease,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Validate package version
 	err = validation.ValidatePackageVersion(packageVersion)
 	if err != nil {
@@ -49,83 +136,310 @@ func RunCmd(
 	// Resolve generation paths
 	var packageFullName = template_package.GetPackageFullName(packageName, packageVersion)
 	var packageDirPath = template_package.GetPackageDir(kpmHomeDir, packageFullName)
-	var outputName = validation.GetStringOrDefault(optionalOutputName, template_package.GetDefaultOutputName(packageName, packageVersion))
-	var parametersFilePath string
-	parametersFilePath, err = files.GetAbsolutePathOrDefault(optionalParametersFilePath, template_package.GetDefaultParametersFile(packageDirPath))
-	if err != nil {
-		return err
+	var outputName = validation.GetStringOrDefault(options.OutputName, template_package.GetDefaultOutputName(packageName, packageVersion))
+	var absoluteParametersFilePaths = make([]string, len(options.ParametersFilePaths))
+	for i, parametersFilePath := range options.ParametersFilePaths {
+		if absoluteParametersFilePaths[i], err = files.GetAbsolutePath(parametersFilePath); err != nil {
+			return err
+		}
 	}
 
-	var packageOutputDirPath = filepath.Join(outputDirPath, outputName)
+	var packageOutputDirPath = filepath.Join(options.OutputDirPath, outputName)
+
+	// Get the packages which were previously used to generate this output
+	var lockedOutput = lockFile.Outputs[outputName]
+	if options.FrozenLockfile && lockedOutput == nil {
+		return fmt.Errorf("output '%s' is not in the lock file: %s", outputName, lockFilePath)
+	}
+
+	repoFetcher.lockedOutput = lockedOutput
 
 	// Log resolved values
 	log.Verbosef("====")
 	log.Verbosef("Package name:              %s", packageName)
 	log.Verbosef("Package version:           %s", packageVersion)
 	log.Verbosef("Package directory:         %s", packageDirPath)
-	log.Verbosef("Parameters file:           %s", parametersFilePath)
+	log.Verbosef("Parameters files:          %s", strings.Join(absoluteParametersFilePaths, ", "))
+	log.Verbosef("Parameter overrides:       %d", len(options.ParameterOverrides))
 	log.Verbosef("Output name:               %s", outputName)
-	log.Verbosef("Output directory:          %s", outputDirPath)
+	log.Verbosef("Output directory:          %s", options.OutputDirPath)
 	log.Verbosef("Package output directory:  %s", packageOutputDirPath)
+	log.Verbosef("Offline:                   %t", options.Offline)
+	log.Verbosef("Lock file:                 %s", lockFilePath)
+	log.Verbosef("Frozen lock file:          %t", options.FrozenLockfile)
+	log.Verbosef("Dry run:                   %t", options.DryRun)
+	log.Verbosef("Output format:             %s", options.OutputFormat)
+	log.Verbosef("Explain values:            %t", options.ExplainValues)
 	log.Verbosef("====")
 
-	// Get the default parameters
+	// Fetch the package if it is missing
+	if files.DirExists(packageDirPath, "template package") != nil {
+		if fetcher == nil {
+			return fmt.Errorf("failed to get package \"%s\": it is not in the local KPM repository", packageFullName)
+		}
+
+		log.Infof("Fetching missing package: %s", packageFullName)
+		if err = fetcher.FetchPackage(&template_package.PackageInfo{Name: packageName, Version: packageVersion}); err != nil {
+			return fmt.Errorf("failed to fetch package \"%s\": %s", packageFullName, err)
+		}
+	}
+
+	// Get the parameters provided by the user
 	var packageParameters *map[string]any
-	packageParameters, err = template_package.GetPackageParameters(parametersFilePath)
+	var parameterLayers []*params.Layer
+	packageParameters, parameterLayers, err = getUserParameters(absoluteParametersFilePaths, options.ParameterOverrides)
 	if err != nil {
 		return err
 	}
 
 	// Get the dependency tree
 	var dependencyTree *template_package.DependencyTree
-	if dependencyTree, err = template_package.GetDependencyTree(kpmHomeDir, packageName, packageVersion, outputName, packageParameters); err != nil {
+	if dependencyTree, err = template_package.GetDependencyTree(kpmHomeDir, packageName, packageVersion, outputName, packageParameters, lockedOutput, fetcher, options.IncludePrerelease, nil); err != nil {
 		return err
 	}
 
-	// Delete the output directory in case it isn't empty
-	if err = files.DeleteDirIfExists(packageOutputDirPath, "output", userHasConfirmed); err != nil {
+	// Make sure that the packages match the lock file
+	var newLockedOutput *template_package.LockedOutput
+	newLockedOutput, err = dependencyTree.GetLockedOutput(repoFetcher.getRepository)
+	if err != nil {
+		return err
+	}
+	if lockedOutput != nil {
+		var differences = lockedOutput.GetDifferences(newLockedOutput)
+		if len(differences) > 0 {
+			if options.FrozenLockfile {
+				return fmt.Errorf("packages do not match the lock file '%s':\n%s", lockFilePath, strings.Join(differences, "\n"))
+			}
+
+			for _, difference := range differences {
+				if options.DryRun {
+					log.Warningf("Lock file would be updated: %s", difference)
+				} else if options.ExplainValues {
+					log.Warningf("Lock file is not updated when explaining values: %s", difference)
+				} else if options.OutputFormat != OutputFormatDir {
+					log.Warningf("Lock file is not updated when writing to stdout: %s", difference)
+				} else {
+					log.Warningf("Updating lock file: %s", difference)
+				}
+			}
+		}
+	}
+
+	// Print the parameters and values instead of the output, if requested
+	if options.ExplainValues {
+		return printParameterReports(dependencyTree, parameterLayers)
+	}
+
+	// Get the destination of the output files
+	var sink outputSink
+	sink, err = newOutputSink(options.OutputFormat, packageOutputDirPath, outputName, log.WriterOut, options.DryRun)
+	if err != nil {
 		return err
 	}
 
-	// Execute template packages in the dependency tree and write the output to the filesystem
+	// Execute template packages in the dependency tree
 	var numPackages int
-	numPackages, err = dependencyTree.VisitNodesDepthFirst(func(
+	numPackages, err = executeDependencyTree(dependencyTree, sink)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("Executed %d packages", numPackages)
+
+	// Write the output, now that all of the packages have executed successfully
+	if err = sink.Close(); err != nil {
+		return err
+	}
+
+	// Record the packages which were used, if the output was written to the output directory
+	if options.DryRun || options.OutputFormat != OutputFormatDir {
+		return nil
+	}
+	if !options.FrozenLockfile {
+		lockFile.Outputs[outputName] = newLockedOutput
+		if err = template_package.WriteLockFile(lockFilePath, lockFile); err != nil {
+			return err
+		}
+		log.Verbosef("Wrote lock file: %s", lockFilePath)
+	}
+
+	return nil
+}
+
+// executeDependencyTree executes each package in the dependency tree, and adds the files that they generate to the sink.
+// It returns the number of packages that were executed.
+func executeDependencyTree(dependencyTree *template_package.DependencyTree, sink outputSink) (int, error) {
+	return dependencyTree.VisitNodesDepthFirst(func(
 		relativeFilePath []string,
 		friendlyNamePath []string,
-		executableTemplates []*template.Template,
+		executableTemplates []*template_package.ExecutableTemplate,
+		staticFiles []*template_package.OutputFile,
 		templateInput *map[string]any,
 	) error {
-		// Get the output directory
-		var outputDir = filepath.Join(outputDirPath, filepath.Join(relativeFilePath...))
-
-		// Create the output directory if it doesn't exist
-		err = os.MkdirAll(outputDir, os.ModePerm)
+		// Execute the templates in the package with the provided input data
+		var packageOutputFiles, err = template_package.GetOutputFiles(executableTemplates, staticFiles, templateInput)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to execute package: %s\n%s", strings.Join(friendlyNamePath, " -> "), err)
 		}
 
-		// Get the templates in the package
-		for _, tmpl := range executableTemplates {
-			// Execute the template with the provided input data
-			var templateOutput []byte
-			templateOutput, err = templates.ExecuteTemplate(tmpl, templateInput)
+		// Make the paths relative to the root package's output directory (the first segment is the root's output name)
+		var packageOutputPath = path.Join(relativeFilePath[1:]...)
+		for _, outputFile := range packageOutputFiles {
+			err = sink.AddFile(&template_package.OutputFile{
+				Path:   path.Join(packageOutputPath, outputFile.Path),
+				Source: outputFile.Source,
+				Mode:   outputFile.Mode,
+				Data:   outputFile.Data,
+			})
 			if err != nil {
-				return fmt.Errorf("failed to execute package: %s\n%s", strings.Join(friendlyNamePath, " -> "), err)
+				return err
 			}
-
-			// Write the data to the filesystem
-			var outputFilePath = filepath.Join(outputDir, tmpl.Name())
-			log.Verbosef("Writing file: %s", outputFilePath)
-			os.WriteFile(outputFilePath, templateOutput, 0755)
 		}
 
 		return nil
 	})
+}
+
+// getUserParameters merges the given parameters files in order and then applies the overrides.  Null values are kept, so
+// they can remove the package's default parameters.  The layers which describe where each of the parameters came from are
+// also returned, in the order that they were merged.
+func getUserParameters(parametersFilePaths []string, parameterOverrides []*params.Override) (*map[string]any, []*params.Layer, error) {
+	var result = map[string]any{}
+	var layers = []*params.Layer{}
+	for _, parametersFilePath := range parametersFilePaths {
+		var fileParameters, err = template_package.GetPackageParameters(parametersFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if *fileParameters == nil {
+			*fileParameters = map[string]any{}
+		}
+
+		var fileBytes []byte
+		if fileBytes, err = files.ReadBytes(parametersFilePath); err != nil {
+			return nil, nil, err
+		}
+		layers = append(layers, params.NewFileLayer("user file", parametersFilePath, *fileParameters, fileBytes))
+
+		result = params.Merge(result, *fileParameters, true)
+	}
+
+	if err := params.ApplyOverrides(result, parameterOverrides); err != nil {
+		return nil, nil, err
+	}
+	for _, override := range parameterOverrides {
+		layers = append(layers, params.NewOverrideLayer(override))
+	}
+
+	return &result, layers, nil
+}
+
+// printParameterReports prints the parameters and values of each package in the dependency tree as YAML documents, with
+// comments that describe where each parameter came from.
+func printParameterReports(dependencyTree *template_package.DependencyTree, parameterLayers []*params.Layer) error {
+	var reports, err = dependencyTree.GetParameterReports(parameterLayers)
 	if err != nil {
 		return err
 	}
 
-	log.Debugf("Executed %d packages", numPackages)
+	for _, report := range reports {
+		var reportBytes []byte
+		if reportBytes, err = report.ToAnnotatedYaml(); err != nil {
+			return fmt.Errorf("failed to explain values for package: %s\n%s", strings.Join(report.FriendlyNamePath, " -> "), err)
+		}
+
+		log.Outputf("---\n%s", strings.TrimSuffix(string(reportBytes), "\n"))
+	}
 
 	return nil
 }
+
+// resolveRootPackageVersion returns the locked version of the package which is being run if it satisfies the version
+// constraint, or otherwise resolves the constraint.  If there is no constraint, the highest version in the local KPM
+// repository is used.
+func resolveRootPackageVersion(
+	kpmHomeDir string,
+	lockFile *template_package.LockFile,
+	packageName string,
+	versionConstraint string,
+	optionalOutputName *string,
+	fetcher template_package.PackageFetcher,
+	frozenLockfile bool,
+	includePrerelease bool,
+) (version string, err error) {
+	var constraint *versions.Constraint
+	if versionConstraint != "" {
+		constraint, err = versions.ParseConstraint(versionConstraint)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var isLocked bool
+	version, isLocked, err = getLockedPackageVersion(lockFile, packageName, constraint, optionalOutputName, includePrerelease)
+	if err != nil || isLocked {
+		return version, err
+	}
+
+	if frozenLockfile {
+		return "", fmt.Errorf("package '%s' is not in the lock file", packageName)
+	}
+
+	if constraint == nil {
+		if version, err = template_package.GetHighestPackageVersion(kpmHomeDir, packageName, includePrerelease); err != nil {
+			return "", fmt.Errorf("could not find package '%s' in the local KPM repository: %s", packageName, err)
+		}
+
+		return version, nil
+	}
+
+	version, err = template_package.ResolvePackageVersion(kpmHomeDir, packageName, versionConstraint, "", fetcher, includePrerelease)
+	if err != nil {
+		return "", err
+	}
+	log.Infof("Resolved version of package '%s@%s': %s", packageName, constraint, version)
+
+	return version, nil
+}
+
+// getLockedPackageVersion returns the version of a package which was locked for an output, if there is one which
+// satisfies the constraint (if it is not nil).
+func getLockedPackageVersion(
+	lockFile *template_package.LockFile,
+	packageName string,
+	constraint *versions.Constraint,
+	optionalOutputName *string,
+	includePrerelease bool,
+) (version string, isLocked bool, err error) {
+	var lockedVersions = []string{}
+	for outputName, lockedOutput := range lockFile.Outputs {
+		if lockedOutput.Package.Name != packageName {
+			continue
+		}
+
+		if constraint != nil && !constraint.Matches(lockedOutput.Package.Version, includePrerelease) {
+			continue
+		}
+
+		// If the output name wasn't provided, the output name depends on the version.
+		var defaultOutputName = template_package.GetDefaultOutputName(packageName, lockedOutput.Package.Version)
+		if outputName == validation.GetStringOrDefault(optionalOutputName, defaultOutputName) {
+			lockedVersions = append(lockedVersions, lockedOutput.Package.Version)
+		}
+	}
+
+	switch len(lockedVersions) {
+	case 0:
+		return "", false, nil
+	case 1:
+		log.Verbosef("Using locked version of package '%s': %s", packageName, lockedVersions[0])
+		return lockedVersions[0], true, nil
+	default:
+		versions.Sort(lockedVersions)
+		return "", false, fmt.Errorf(
+			"multiple versions of package '%s' are in the lock file, so a version must be provided: %s",
+			packageName,
+			strings.Join(lockedVersions, ", "),
+		)
+	}
+}
@@ -0,0 +1,222 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/yaml.v3"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_repository"
+)
+
+// writeTestPackage writes a template package with the given files (relative to the package directory) into a KPM home
+// directory or filesystem repository.
+func writeTestPackage(repoDir string, packageName string, packageVersion string, packageFiles map[string]string) {
+	var packageDir = template_package.GetPackageDir(repoDir, template_package.GetPackageFullName(packageName, packageVersion))
+	packageFiles[constants.PackageInfoFileName] = fmt.Sprintf("name: %s\nversion: %s\n", packageName, packageVersion)
+	writeTestFiles(packageDir, packageFiles)
+}
+
+// writeTestFiles writes the given files (relative to the given directory), creating directories as needed.
+func writeTestFiles(dirPath string, testFiles map[string]string) {
+	for relativePath, content := range testFiles {
+		var filePath = filepath.Join(dirPath, filepath.FromSlash(relativePath))
+		So(os.MkdirAll(filepath.Dir(filePath), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(filePath, []byte(content), 0644), ShouldBeNil)
+	}
+}
+
+func TestRunFetchesMissingPackages(t *testing.T) {
+	Convey("Given a package whose dependency is only in a filesystem repository", t, func() {
+		var kpmHomeDir = t.TempDir()
+		var repoDir = t.TempDir()
+		var outputDir = t.TempDir()
+
+		writeTestPackage(kpmHomeDir, "test/parent", "1.0.0", map[string]string{
+			constants.InterfaceFileName:  "{}\n",
+			constants.ParametersFileName: "{}\n",
+			"dependencies/child.yaml":    "package:\n  name: test/child\n  version: \"^1.0.0\"\nparameters:\n  greeting: hi\n",
+		})
+		writeTestPackage(repoDir, "test/child", "1.1.0", map[string]string{
+			constants.InterfaceFileName:  "greeting: {{ .greeting }}\n",
+			constants.ParametersFileName: "greeting: hello\n",
+			"templates/greeting.txt":     "{{ .values.greeting }}\n",
+		})
+
+		var repos = template_repository.NewRepositoryCollection()
+		So(yaml.Unmarshal([]byte(fmt.Sprintf("- name: team\n  type: filesystem\n  connection: %s\n", repoDir)), repos), ShouldBeNil)
+
+		var childDir = template_package.GetPackageDir(kpmHomeDir, template_package.GetPackageFullName("test/child", "1.1.0"))
+
+		Convey("The dependency is pulled from the repository", func() {
+			var err = RunCmd("test/parent", "1.0.0", kpmHomeDir, repos, &RunOptions{
+				OutputDirPath: outputDir,
+				OutputFormat:  OutputFormatDir,
+			})
+			So(err, ShouldBeNil)
+			So(files.DirExists(childDir, "template package"), ShouldBeNil)
+
+			var data []byte
+			data, err = os.ReadFile(filepath.Join(outputDir, "test", "parent-1.0.0", "child", "greeting.txt"))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "hi\n")
+		})
+
+		Convey("Running offline fails", func() {
+			var err = RunCmd("test/parent", "1.0.0", kpmHomeDir, repos, &RunOptions{
+				OutputDirPath: outputDir,
+				Offline:       true,
+				OutputFormat:  OutputFormatDir,
+			})
+			So(err, ShouldNotBeNil)
+			So(files.DirExists(childDir, "template package"), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestRunLockFile(t *testing.T) {
+	Convey("Given a package whose dependency is resolved from a version constraint", t, func() {
+		var kpmHomeDir = t.TempDir()
+		var repoDir = t.TempDir()
+		var outputDir = t.TempDir()
+		var lockFilePath = template_package.GetLockFilePath(outputDir)
+
+		var writeParentPackage = func(version string) {
+			writeTestPackage(kpmHomeDir, "test/parent", version, map[string]string{
+				constants.InterfaceFileName:  "{}\n",
+				constants.ParametersFileName: "{}\n",
+				"dependencies/child.yaml":    "package:\n  name: test/child\n  version: \"^1.0.0\"\nparameters: {}\n",
+			})
+		}
+		var writeChildPackage = func(version string) {
+			writeTestPackage(repoDir, "test/child", version, map[string]string{
+				constants.InterfaceFileName:  "{}\n",
+				constants.ParametersFileName: "{}\n",
+				"templates/version.txt":      version + "\n",
+			})
+		}
+		var readChildOutput = func(outputName string) string {
+			var data, err = os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(outputName), "child", "version.txt"))
+			So(err, ShouldBeNil)
+			return string(data)
+		}
+
+		writeParentPackage("1.0.0")
+		writeChildPackage("1.1.0")
+
+		var repos = template_repository.NewRepositoryCollection()
+		So(yaml.Unmarshal([]byte(fmt.Sprintf("- name: team\n  type: filesystem\n  connection: %s\n", repoDir)), repos), ShouldBeNil)
+
+		Convey("Running the package writes the lock file", func() {
+			So(RunCmd("test/parent", "", kpmHomeDir, repos, &RunOptions{
+				OutputDirPath: outputDir,
+				OutputFormat:  OutputFormatDir,
+			}), ShouldBeNil)
+
+			var lockFile, err = template_package.ReadLockFile(lockFilePath)
+			So(err, ShouldBeNil)
+			So(lockFile.Outputs, ShouldContainKey, "test/parent-1.0.0")
+
+			var lockedOutput = lockFile.Outputs["test/parent-1.0.0"]
+			So(lockedOutput.Package, ShouldResemble, template_package.PackageInfo{Name: "test/parent", Version: "1.0.0"})
+			So(lockedOutput.Nodes, ShouldHaveLength, 2)
+			So(lockedOutput.Nodes[1].Name, ShouldEqual, "test/child")
+			So(lockedOutput.Nodes[1].Version, ShouldEqual, "1.1.0")
+			So(lockedOutput.Nodes[1].Constraint, ShouldEqual, "^1.0.0")
+			So(lockedOutput.Nodes[1].Repository, ShouldEqual, "team")
+			So(lockedOutput.Nodes[1].Digest, ShouldStartWith, "sha256:")
+
+			Convey("Running it again reuses the locked versions instead of the newest versions", func() {
+				writeParentPackage("1.1.0")
+				writeChildPackage("1.2.0")
+
+				So(RunCmd("test/parent", "", kpmHomeDir, repos, &RunOptions{
+					OutputDirPath: outputDir,
+					OutputFormat:  OutputFormatDir,
+				}), ShouldBeNil)
+				So(readChildOutput("test/parent-1.0.0"), ShouldEqual, "1.1.0\n")
+				So(files.DirExists(filepath.Join(outputDir, "test", "parent-1.1.0"), "output"), ShouldNotBeNil)
+
+				var newLockFile, err = template_package.ReadLockFile(lockFilePath)
+				So(err, ShouldBeNil)
+				So(newLockFile, ShouldResemble, lockFile)
+			})
+
+			Convey("Running it with a frozen lock file succeeds if nothing has changed", func() {
+				So(RunCmd("test/parent", "", kpmHomeDir, repos, &RunOptions{
+					OutputDirPath:  outputDir,
+					FrozenLockfile: true,
+					OutputFormat:   OutputFormatDir,
+				}), ShouldBeNil)
+			})
+
+			Convey("Running it with a frozen lock file fails if a locked package's contents have changed", func() {
+				var childDir = template_package.GetPackageDir(kpmHomeDir, template_package.GetPackageFullName("test/child", "1.1.0"))
+				So(os.WriteFile(filepath.Join(childDir, "templates", "version.txt"), []byte("changed\n"), 0644), ShouldBeNil)
+
+				err = RunCmd("test/parent", "", kpmHomeDir, repos, &RunOptions{
+					OutputDirPath:  outputDir,
+					FrozenLockfile: true,
+					OutputFormat:   OutputFormatDir,
+				})
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "package 'test/child-1.1.0' has changed")
+				So(readChildOutput("test/parent-1.0.0"), ShouldEqual, "1.1.0\n")
+			})
+
+			Convey("Running a different version with a frozen lock file fails", func() {
+				writeParentPackage("1.1.0")
+
+				var outputName = "test/parent-1.0.0"
+				err = RunCmd("test/parent", "1.1.0", kpmHomeDir, repos, &RunOptions{
+					OutputDirPath:  outputDir,
+					OutputName:     &outputName,
+					FrozenLockfile: true,
+					OutputFormat:   OutputFormatDir,
+				})
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "package 'test/parent-1.0.0' was locked, but 'test/parent-1.1.0' was used")
+			})
+
+			Convey("A dry run doesn't write the lock file", func() {
+				So(os.Remove(lockFilePath), ShouldBeNil)
+
+				So(RunCmd("test/parent", "1.0.0", kpmHomeDir, repos, &RunOptions{
+					OutputDirPath: outputDir,
+					DryRun:        true,
+					OutputFormat:  OutputFormatDir,
+				}), ShouldBeNil)
+				So(files.FileExists(lockFilePath, "lock file"), ShouldNotBeNil)
+			})
+		})
+
+		Convey("Writing the output to stdout doesn't write the lock file", func() {
+			for _, outputFormat := range []string{OutputFormatYaml, OutputFormatTar} {
+				var _, err = captureOutput(func() error {
+					return RunCmd("test/parent", "1.0.0", kpmHomeDir, repos, &RunOptions{
+						OutputDirPath: outputDir,
+						OutputFormat:  outputFormat,
+					})
+				})
+				So(err, ShouldBeNil)
+				So(files.FileExists(lockFilePath, "lock file"), ShouldNotBeNil)
+			}
+		})
+
+		Convey("Running it with a frozen lock file fails if there is no lock file", func() {
+			var err = RunCmd("test/parent", "1.0.0", kpmHomeDir, repos, &RunOptions{
+				OutputDirPath:  outputDir,
+				FrozenLockfile: true,
+				OutputFormat:   OutputFormatDir,
+			})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "lock file does not exist")
+		})
+	})
+}
@@ -0,0 +1,521 @@
+package pkg
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/diff"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_repository"
+	"github.com/rohitramu/kpm/src/pkg/utils/templates"
+	"github.com/rohitramu/kpm/src/pkg/utils/validation"
+)
+
+// testCase is a directory in a package's tests directory, which contains the parameters to run the package with and the
+// output that it is expected to generate.
+type testCase struct {
+	// name is the name of the test case's directory, which is also used as the output name.
+	name string
+
+	// parametersFilePath is empty if the test case uses the package's default parameters.
+	parametersFilePath string
+
+	// expectedDirPath is the directory which contains the expected output files.
+	expectedDirPath string
+
+	// snapshotFilePath is the file which contains the expected output as YAML documents.
+	snapshotFilePath string
+}
+
+// testResult is the outcome of running a test case.
+type testResult struct {
+	name     string
+	duration time.Duration
+
+	// failure describes why the test case failed (e.g. a diff), or is empty if it passed.
+	failure string
+
+	// isError is true if the package failed to run, rather than generating unexpected output.
+	isError bool
+
+	// updated is true if the expected output was rewritten.
+	updated bool
+}
+
+// TestOptions are the options for running a package's test cases with "TestCmd".
+type TestOptions struct {
+	// Offline stops dependencies which are missing from the KPM home directory from being pulled from repositories.
+	Offline bool
+
+	// IncludePrerelease allows prerelease versions to be chosen when resolving the versions of dependencies.
+	IncludePrerelease bool
+
+	// Update rewrites the expected output of each test case instead of comparing it.  Test cases without any expected
+	// output get a snapshot.
+	Update bool
+
+	// ReportFormat is one of "TestReportFormats".
+	ReportFormat string
+
+	// EnableCoverage records the actions and branches which are executed in each template file across all test cases,
+	// and logs a summary.  Coverage is also enabled if "CoverageOutPath" is set.
+	EnableCoverage bool
+
+	// CoverageOutPath is the file which a coverage report is written to, or empty to only log the summary.
+	CoverageOutPath string
+
+	// CoverageFormat is the format of the coverage report, which is one of "CoverageFormats".
+	CoverageFormat string
+}
+
+// TestCmd runs the test cases in a local template package with the given options (see "TestOptions"), and prints a
+// report.  Each test case is a directory in the package's "tests" directory, which contains an optional parameters file
+// and either an "expected" directory with the expected output files or a "snapshot.yaml" file with the expected output as
+// YAML documents.  The package is run with the same dependency resolution as "kpm run", using packages from the KPM home
+// directory (or pulled from the given repositories, unless running offline).
+// An error is returned if any test case fails.
+func TestCmd(
+	packagePath string,
+	kpmHomeDirPath string,
+	repos *template_repository.RepositoryCollection,
+	options *TestOptions,
+) error {
+	var err error
+
+	// Package directory
+	var packageDirAbsPath string
+	packageDirAbsPath, err = files.GetAbsolutePath(packagePath)
+	if err != nil {
+		return err
+	}
+
+	// Get KPM home directory
+	var kpmHomeDir string
+	kpmHomeDir, err = files.GetAbsolutePath(kpmHomeDirPath)
+	if err != nil {
+		return err
+	}
+
+	// Validate package and get package info
+	var packageInfo *template_package.PackageInfo
+	packageInfo, err = template_package.GetPackageInfo(packageDirAbsPath)
+	if err != nil {
+		return err
+	}
+
+	var testsDirPath = filepath.Join(packageDirAbsPath, constants.TestsDirName)
+
+	// Log resolved values
+	log.Verbosef("====")
+	log.Verbosef("Template package directory:  %s", packageDirAbsPath)
+	log.Verbosef("Tests directory:             %s", testsDirPath)
+	log.Verbosef("Offline:                     %t", options.Offline)
+	log.Verbosef("Update:                      %t", options.Update)
+	log.Verbosef("Report format:               %s", options.ReportFormat)
+	log.Verbosef("Coverage:                    %t", options.EnableCoverage || options.CoverageOutPath != "")
+	log.Verbosef("====")
+
+	var testCases []*testCase
+	testCases, err = getTestCases(testsDirPath)
+	if err != nil {
+		return err
+	}
+
+	// Run the package from a temporary KPM home directory, so the package doesn't need to be packed first
+	var testKpmHomeDir string
+	testKpmHomeDir, err = os.MkdirTemp("", "kpm-test-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(testKpmHomeDir)
+
+	var packageFullName = template_package.GetPackageFullName(packageInfo.Name, packageInfo.Version)
+	if err = files.CopyDir(packageDirAbsPath, template_package.GetPackageDir(testKpmHomeDir, packageFullName)); err != nil {
+		return fmt.Errorf("failed to copy package to temporary KPM home directory: %s", err)
+	}
+
+	// Dependencies are copied from the KPM home directory when they are needed
+	var fetcher = &kpmHomePackageFetcher{
+		kpmHomeDir:     kpmHomeDir,
+		testKpmHomeDir: testKpmHomeDir,
+	}
+	if !options.Offline {
+		fetcher.repoFetcher = &repositoryPackageFetcher{
+			kpmHomeDir: kpmHomeDir,
+			repos:      repos,
+			pulledFrom: map[string]string{},
+		}
+	}
+
+	// Record which parts of the template files are executed by all of the test cases
+	var coverage *templates.Coverage
+	if options.EnableCoverage || options.CoverageOutPath != "" {
+		coverage = templates.NewCoverage()
+	}
+
+	var results = make([]*testResult, 0, len(testCases))
+	for _, currentTestCase := range testCases {
+		var startTime = time.Now()
+		var result = currentTestCase.run(testKpmHomeDir, packageInfo, fetcher, options.IncludePrerelease, options.Update, coverage)
+		result.duration = time.Since(startTime)
+		results = append(results, result)
+
+		if result.failure != "" {
+			log.Verbosef("Test case failed: %s", result.name)
+		} else if result.updated {
+			log.Infof("Updated expected output for test case: %s", result.name)
+		}
+	}
+
+	var report []byte
+	report, err = getTestReport(packageInfo, results, options.ReportFormat)
+	if err != nil {
+		return err
+	}
+	log.Outputf("%s", strings.TrimSuffix(string(report), "\n"))
+
+	if coverage != nil {
+		var reporter = &coverageReporter{
+			coverage:        coverage,
+			packageFullName: packageFullName,
+			packageDir:      packageDirAbsPath,
+			kpmHomeDir:      kpmHomeDir,
+			testKpmHomeDir:  testKpmHomeDir,
+		}
+		reporter.printSummary()
+
+		if options.CoverageOutPath != "" {
+			if err = reporter.writeReport(options.CoverageOutPath, options.CoverageFormat); err != nil {
+				return err
+			}
+		}
+	}
+
+	var numFailed = 0
+	for _, result := range results {
+		if result.failure != "" {
+			numFailed++
+		}
+	}
+	if numFailed > 0 {
+		return fmt.Errorf("%d of %d test case(s) failed in package: %s", numFailed, len(results), packageFullName)
+	}
+
+	return nil
+}
+
+// getTestCases returns the test cases in a package's tests directory, sorted by name.
+func getTestCases(testsDirPath string) ([]*testCase, error) {
+	if err := files.DirExists(testsDirPath, "tests"); err != nil {
+		return nil, err
+	}
+
+	var entries, err = os.ReadDir(testsDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var result = []*testCase{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			log.Warningf("Ignoring file in tests directory: %s", entry.Name())
+			continue
+		}
+
+		// The name is used as the output name, so it needs to be valid
+		if err = validation.ValidateOutputName(entry.Name()); err != nil {
+			return nil, fmt.Errorf("invalid test case name \"%s\": %s", entry.Name(), err)
+		}
+
+		var testCaseDirPath = filepath.Join(testsDirPath, entry.Name())
+		var currentTestCase = &testCase{
+			name:             entry.Name(),
+			expectedDirPath:  filepath.Join(testCaseDirPath, constants.TestExpectedDirName),
+			snapshotFilePath: filepath.Join(testCaseDirPath, constants.TestSnapshotFileName),
+		}
+
+		var parametersFilePath = filepath.Join(testCaseDirPath, constants.ParametersFileName)
+		if files.FileExists(parametersFilePath, "parameters") == nil {
+			currentTestCase.parametersFilePath = parametersFilePath
+		}
+
+		result = append(result, currentTestCase)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no test cases were found in directory: %s", testsDirPath)
+	}
+
+	return result, nil
+}
+
+// run runs the package with the test case's parameters, and compares the output with the expected output (or replaces
+// the expected output if "update" is true).  If "coverage" is not nil, the blocks which are executed are recorded in it.
+func (currentTestCase *testCase) run(
+	testKpmHomeDir string,
+	packageInfo *template_package.PackageInfo,
+	fetcher template_package.PackageFetcher,
+	includePrerelease bool,
+	update bool,
+	coverage *templates.Coverage,
+) *testResult {
+	var result = &testResult{name: currentTestCase.name}
+
+	var outputFiles, err = currentTestCase.render(testKpmHomeDir, packageInfo, fetcher, includePrerelease, coverage)
+	if err != nil {
+		result.failure = err.Error()
+		result.isError = true
+		return result
+	}
+
+	// Compare with the expected output directory if there is one, otherwise the snapshot
+	var useExpectedDir = files.DirExists(currentTestCase.expectedDirPath, "expected output") == nil
+	if update {
+		if useExpectedDir {
+			err = writeExpectedDir(currentTestCase.expectedDirPath, outputFiles)
+		} else {
+			err = os.WriteFile(currentTestCase.snapshotFilePath, getSnapshot(currentTestCase.name, outputFiles), 0644)
+		}
+		if err != nil {
+			result.failure = fmt.Sprintf("failed to update expected output: %s", err)
+			result.isError = true
+			return result
+		}
+
+		result.updated = true
+		return result
+	}
+
+	if useExpectedDir {
+		result.failure, err = diffExpectedDir(currentTestCase.expectedDirPath, outputFiles)
+	} else if files.FileExists(currentTestCase.snapshotFilePath, "snapshot") == nil {
+		result.failure, err = diffSnapshot(currentTestCase.snapshotFilePath, getSnapshot(currentTestCase.name, outputFiles))
+	} else {
+		result.failure = fmt.Sprintf(
+			"test case has no \"%s\" directory or \"%s\" file (run with \"--update\" to create a snapshot)",
+			constants.TestExpectedDirName,
+			constants.TestSnapshotFileName,
+		)
+		result.isError = true
+	}
+	if err != nil {
+		result.failure = err.Error()
+		result.isError = true
+	}
+
+	return result
+}
+
+// render runs the package with the test case's parameters, and returns the generated files.
+func (currentTestCase *testCase) render(
+	testKpmHomeDir string,
+	packageInfo *template_package.PackageInfo,
+	fetcher template_package.PackageFetcher,
+	includePrerelease bool,
+	coverage *templates.Coverage,
+) ([]*template_package.OutputFile, error) {
+	var parametersFilePaths = []string{}
+	if currentTestCase.parametersFilePath != "" {
+		parametersFilePaths = append(parametersFilePaths, currentTestCase.parametersFilePath)
+	}
+
+	var packageParameters, _, err = getUserParameters(parametersFilePaths, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dependencyTree *template_package.DependencyTree
+	dependencyTree, err = template_package.GetDependencyTree(
+		testKpmHomeDir,
+		packageInfo.Name,
+		packageInfo.Version,
+		currentTestCase.name,
+		packageParameters,
+		nil,
+		fetcher,
+		includePrerelease,
+		coverage,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var sink = &memoryOutputSink{}
+	if _, err = executeDependencyTree(dependencyTree, sink); err != nil {
+		return nil, err
+	}
+
+	return sink.outputFiles, nil
+}
+
+// getSnapshot returns the output files as YAML documents, in the same format as the "yaml" output format.
+func getSnapshot(outputName string, outputFiles []*template_package.OutputFile) []byte {
+	var buffer = new(bytes.Buffer)
+	var sink = &yamlOutputSink{outputName: outputName, writer: buffer, outputFiles: outputFiles}
+	if err := sink.Close(); err != nil {
+		log.Panicf("Failed to write snapshot to buffer: %s", err)
+	}
+
+	return buffer.Bytes()
+}
+
+// diffSnapshot returns a unified diff between the snapshot file and the generated snapshot, or an empty string if they
+// are the same.
+func diffSnapshot(snapshotFilePath string, snapshot []byte) (string, error) {
+	var expectedSnapshot, err = os.ReadFile(snapshotFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read snapshot: %s\n%s", snapshotFilePath, err)
+	}
+
+	var name = filepath.Base(snapshotFilePath)
+	return diff.Unified("expected/"+name, "actual/"+name, expectedSnapshot, snapshot), nil
+}
+
+// diffExpectedDir returns a unified diff between the files in the expected output directory and the generated files, or
+// an empty string if they are the same.
+func diffExpectedDir(expectedDirPath string, outputFiles []*template_package.OutputFile) (string, error) {
+	var expectedPaths, err = listOutputDir(expectedDirPath)
+	if err != nil {
+		return "", err
+	}
+
+	var actualFiles = map[string][]byte{}
+	for _, outputFile := range outputFiles {
+		actualFiles[outputFile.Path] = outputFile.Data
+	}
+
+	var allPaths = append([]string{}, expectedPaths...)
+	var expectedFiles = map[string]bool{}
+	for _, expectedPath := range expectedPaths {
+		expectedFiles[expectedPath] = true
+	}
+	for actualPath := range actualFiles {
+		if !expectedFiles[actualPath] {
+			allPaths = append(allPaths, actualPath)
+		}
+	}
+	sort.Strings(allPaths)
+
+	var builder = new(strings.Builder)
+	for _, relativePath := range allPaths {
+		var expectedName, actualName = "expected/" + relativePath, "actual/" + relativePath
+
+		var expectedData []byte
+		if expectedFiles[relativePath] {
+			var expectedFilePath = filepath.Join(expectedDirPath, filepath.FromSlash(relativePath))
+			if expectedData, err = os.ReadFile(expectedFilePath); err != nil {
+				return "", fmt.Errorf("failed to read expected output file: %s\n%s", expectedFilePath, err)
+			}
+		} else {
+			expectedName = diff.NullFileName
+		}
+
+		var actualData, isGenerated = actualFiles[relativePath]
+		if !isGenerated {
+			actualName = diff.NullFileName
+		}
+
+		var fileDiff = diff.Unified(expectedName, actualName, expectedData, actualData)
+		if fileDiff == "" && expectedFiles[relativePath] != isGenerated {
+			// Empty files have no lines to show, but they are still missing or unexpected
+			fileDiff = fmt.Sprintf("--- %s\n+++ %s\n", expectedName, actualName)
+		}
+		builder.WriteString(fileDiff)
+	}
+
+	return builder.String(), nil
+}
+
+// writeExpectedDir replaces the files in the expected output directory with the generated files.
+func writeExpectedDir(expectedDirPath string, outputFiles []*template_package.OutputFile) (err error) {
+	if err = os.RemoveAll(expectedDirPath); err != nil {
+		return err
+	}
+
+	for _, outputFile := range outputFiles {
+		var filePath = filepath.Join(expectedDirPath, filepath.FromSlash(outputFile.Path))
+		if err = os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			return err
+		}
+
+		var mode = fs.FileMode(0644)
+		if outputFile.Mode != 0 {
+			mode = outputFile.Mode.Perm()
+		}
+		if err = os.WriteFile(filePath, outputFile.Data, mode); err != nil {
+			return err
+		}
+	}
+
+	// Keep the directory even if there are no files, so it is still used for comparisons
+	return os.MkdirAll(expectedDirPath, os.ModePerm)
+}
+
+// memoryOutputSink keeps the output files in memory.
+type memoryOutputSink struct {
+	outputFiles []*template_package.OutputFile
+}
+
+func (sink *memoryOutputSink) AddFile(outputFile *template_package.OutputFile) error {
+	sink.outputFiles = append(sink.outputFiles, outputFile)
+
+	return nil
+}
+
+func (sink *memoryOutputSink) Close() error {
+	return nil
+}
+
+var _ template_package.PackageFetcher = &kpmHomePackageFetcher{}
+
+// kpmHomePackageFetcher copies packages from the KPM home directory into a temporary KPM home directory.  Packages which
+// are missing from the KPM home directory are pulled from repositories first, if a repository fetcher is provided.
+type kpmHomePackageFetcher struct {
+	kpmHomeDir     string
+	testKpmHomeDir string
+	repoFetcher    *repositoryPackageFetcher
+}
+
+func (fetcher *kpmHomePackageFetcher) GetPackageVersions(packageName string) ([]string, error) {
+	var result, err = template_package.GetLocalPackageVersions(fetcher.kpmHomeDir, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	if fetcher.repoFetcher != nil {
+		// Keep the local versions even if some repositories can't be checked
+		var fetchableVersions []string
+		fetchableVersions, err = fetcher.repoFetcher.GetPackageVersions(packageName)
+		result = append(result, fetchableVersions...)
+	}
+
+	return result, err
+}
+
+func (fetcher *kpmHomePackageFetcher) FetchPackage(packageInfo *template_package.PackageInfo) (err error) {
+	var packageFullName = template_package.GetPackageFullName(packageInfo.Name, packageInfo.Version)
+	var packageDirPath = template_package.GetPackageDir(fetcher.kpmHomeDir, packageFullName)
+
+	if files.DirExists(packageDirPath, "template package") != nil {
+		if fetcher.repoFetcher == nil {
+			return fmt.Errorf("failed to get package \"%s\": it is not in the local KPM repository", packageFullName)
+		}
+
+		log.Infof("Fetching missing package: %s", packageFullName)
+		if err = fetcher.repoFetcher.FetchPackage(packageInfo); err != nil {
+			return fmt.Errorf("failed to fetch package \"%s\": %s", packageFullName, err)
+		}
+	}
+
+	log.Debugf("Copying package to temporary KPM home directory: %s", packageFullName)
+	return files.CopyDir(packageDirPath, template_package.GetPackageDir(fetcher.testKpmHomeDir, packageFullName))
+}
@@ -0,0 +1,266 @@
+package pkg
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/templates"
+)
+
+// CoverageFormatLcov writes the coverage report as an LCOV tracefile.
+const CoverageFormatLcov = "lcov"
+
+// CoverageFormatListing writes the coverage report as the text of each template file, annotated with how many times
+// each line was executed.
+const CoverageFormatListing = "listing"
+
+// CoverageFormats is the list of supported coverage report formats.
+var CoverageFormats = []string{CoverageFormatLcov, CoverageFormatListing}
+
+// coverageReporter formats the coverage of the template files which were used by a package's test cases.
+type coverageReporter struct {
+	coverage *templates.Coverage
+
+	// packageFullName is the package which is being tested.
+	packageFullName string
+
+	// packageDir is the package's source directory.
+	packageDir string
+
+	// kpmHomeDir is the KPM home directory which dependencies were copied from.
+	kpmHomeDir string
+
+	// testKpmHomeDir is the temporary KPM home directory which the test cases were run from.
+	testKpmHomeDir string
+}
+
+// printSummary logs the percentage of blocks that were executed in each template file, and in total.
+func (reporter *coverageReporter) printSummary() {
+	var coverageFiles = reporter.coverage.GetFiles()
+	var names = make([]string, 0, len(coverageFiles))
+	var nameWidth = len("total")
+	for _, file := range coverageFiles {
+		var name = reporter.getDisplayPath(file.FilePath)
+		names = append(names, name)
+		if len(name) > nameWidth {
+			nameWidth = len(name)
+		}
+	}
+
+	var numBlocks, numCovered = 0, 0
+	log.Infof("Template coverage:")
+	for i, file := range coverageFiles {
+		numBlocks += len(file.Blocks)
+		numCovered += file.GetNumCovered()
+		log.Infof("  %-*s  %s", nameWidth, names[i], formatCoverage(file.GetNumCovered(), len(file.Blocks)))
+	}
+	log.Infof("  %-*s  %s", nameWidth, "total", formatCoverage(numCovered, numBlocks))
+}
+
+// writeReport writes the coverage of each template file to a file in the given format (see "CoverageFormats").
+func (reporter *coverageReporter) writeReport(outputFilePath string, coverageFormat string) (err error) {
+	var report string
+	switch coverageFormat {
+	case CoverageFormatLcov:
+		report = reporter.getLcovReport()
+	case CoverageFormatListing:
+		report = reporter.getListingReport()
+	default:
+		return fmt.Errorf("unknown coverage format \"%s\", expected one of: %s", coverageFormat, strings.Join(CoverageFormats, ", "))
+	}
+
+	if err = os.MkdirAll(filepath.Dir(outputFilePath), os.ModePerm); err != nil {
+		return err
+	}
+	if err = os.WriteFile(outputFilePath, []byte(report), 0644); err != nil {
+		return fmt.Errorf("failed to write coverage report: %s\n%s", outputFilePath, err)
+	}
+
+	log.Infof("Wrote coverage report: %s", outputFilePath)
+	return nil
+}
+
+// getLcovReport formats the coverage as an LCOV tracefile, with a record for each template file.  Each line with an
+// action or branch is recorded with the highest count on the line, and each "if", "range" or "with" action is recorded
+// as a branch.
+func (reporter *coverageReporter) getLcovReport() string {
+	var builder = new(strings.Builder)
+	for _, file := range reporter.coverage.GetFiles() {
+		fmt.Fprintf(builder, "TN:%s\n", reporter.packageFullName)
+		fmt.Fprintf(builder, "SF:%s\n", reporter.getSourcePath(file.FilePath))
+
+		// Branches are numbered in the order of their first block
+		var branchIds = map[int]int{}
+		var branchIndexes = map[int]int{}
+		var branchCounts = map[int]int{}
+		for _, block := range file.Blocks {
+			if block.BranchPos >= 0 {
+				branchCounts[block.BranchPos] += block.Count
+			}
+		}
+
+		var numBranches, numBranchesHit = 0, 0
+		for _, block := range file.Blocks {
+			if block.BranchPos < 0 {
+				continue
+			}
+
+			var branchId, found = branchIds[block.BranchPos]
+			if !found {
+				branchId = len(branchIds)
+				branchIds[block.BranchPos] = branchId
+			}
+			var branchIndex = branchIndexes[block.BranchPos]
+			branchIndexes[block.BranchPos]++
+
+			// Branches are "-" if the action was never executed at all
+			var taken = "-"
+			if branchCounts[block.BranchPos] > 0 {
+				taken = fmt.Sprintf("%d", block.Count)
+			}
+			fmt.Fprintf(builder, "BRDA:%d,%d,%d,%s\n", block.Line, branchId, branchIndex, taken)
+
+			numBranches++
+			if block.Count > 0 {
+				numBranchesHit++
+			}
+		}
+		fmt.Fprintf(builder, "BRF:%d\nBRH:%d\n", numBranches, numBranchesHit)
+
+		var lines, lineCounts = getLineCounts(file)
+		var numLinesHit = 0
+		for _, line := range lines {
+			fmt.Fprintf(builder, "DA:%d,%d\n", line, lineCounts[line].maxCount)
+			if lineCounts[line].maxCount > 0 {
+				numLinesHit++
+			}
+		}
+		fmt.Fprintf(builder, "LF:%d\nLH:%d\n", len(lines), numLinesHit)
+
+		builder.WriteString("end_of_record\n")
+	}
+
+	return builder.String()
+}
+
+// getListingReport formats the coverage as the text of each template file, with each line prefixed by the number of
+// times it was executed.  Lines without any actions are prefixed with "-", lines which were never executed are prefixed
+// with "#####", and lines which contain a block that was never executed are marked with "*".
+func (reporter *coverageReporter) getListingReport() string {
+	var builder = new(strings.Builder)
+	for _, file := range reporter.coverage.GetFiles() {
+		fmt.Fprintf(builder, "==> %s (%s) <==\n", reporter.getDisplayPath(file.FilePath), formatCoverage(file.GetNumCovered(), len(file.Blocks)))
+
+		var _, lineCounts = getLineCounts(file)
+		for i, text := range strings.Split(strings.TrimSuffix(file.Text, "\n"), "\n") {
+			var line = i + 1
+			var count = "-"
+			if lineCount, found := lineCounts[line]; found {
+				if lineCount.maxCount == 0 {
+					count = "#####"
+				} else if lineCount.minCount == 0 {
+					count = fmt.Sprintf("%d*", lineCount.maxCount)
+				} else {
+					count = fmt.Sprintf("%d", lineCount.maxCount)
+				}
+			}
+			fmt.Fprintf(builder, "%9s:%5d: %s\n", count, line, text)
+		}
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
+// lineCount is the lowest and highest count of the blocks which start on a line.
+type lineCount struct {
+	minCount int
+	maxCount int
+}
+
+// getLineCounts returns the lines in a template file which contain blocks (in order), and the counts of the blocks on
+// each of those lines.
+func getLineCounts(file *templates.FileCoverage) ([]int, map[int]*lineCount) {
+	var lines = []int{}
+	var lineCounts = map[int]*lineCount{}
+	for _, block := range file.Blocks {
+		var currentLineCount, found = lineCounts[block.Line]
+		if !found {
+			lines = append(lines, block.Line)
+			lineCounts[block.Line] = &lineCount{minCount: block.Count, maxCount: block.Count}
+			continue
+		}
+
+		if block.Count < currentLineCount.minCount {
+			currentLineCount.minCount = block.Count
+		}
+		if block.Count > currentLineCount.maxCount {
+			currentLineCount.maxCount = block.Count
+		}
+	}
+
+	return lines, lineCounts
+}
+
+// getDisplayPath returns the path of a template file relative to the package which is being tested, or prefixed with the
+// full name of the dependency which it belongs to.
+func (reporter *coverageReporter) getDisplayPath(filePath string) string {
+	var packageFullName, relativePath, found = reporter.splitPackagePath(filePath)
+	if !found {
+		return filePath
+	}
+	if packageFullName == reporter.packageFullName {
+		return relativePath
+	}
+
+	return packageFullName + "/" + relativePath
+}
+
+// getSourcePath returns the path of a template file in the package's source directory, or in the KPM home directory for
+// dependencies.
+func (reporter *coverageReporter) getSourcePath(filePath string) string {
+	var packageFullName, relativePath, found = reporter.splitPackagePath(filePath)
+	if !found {
+		return filePath
+	}
+	if packageFullName == reporter.packageFullName {
+		return filepath.Join(reporter.packageDir, filepath.FromSlash(relativePath))
+	}
+
+	return filepath.Join(template_package.GetPackageDir(reporter.kpmHomeDir, packageFullName), filepath.FromSlash(relativePath))
+}
+
+// splitPackagePath splits the path of a template file in the temporary KPM home directory into the full name of the
+// package that it belongs to and its path relative to that package.  The package's directory is the closest parent
+// directory which contains a package info file, since package names may have any number of namespace segments.
+func (reporter *coverageReporter) splitPackagePath(filePath string) (packageFullName string, relativePath string, found bool) {
+	var packagesDir = template_package.GetRepoPackagesDir(reporter.testKpmHomeDir)
+	for packageDir := filepath.Dir(filePath); packageDir != filepath.Dir(packageDir); packageDir = filepath.Dir(packageDir) {
+		var fullName, err = filepath.Rel(packagesDir, packageDir)
+		if err != nil || fullName == "." || strings.HasPrefix(fullName, "..") {
+			return "", "", false
+		}
+
+		if files.FileExists(filepath.Join(packageDir, constants.PackageInfoFileName), "package info") == nil {
+			var pathInPackage, _ = filepath.Rel(packageDir, filePath)
+			return filepath.ToSlash(fullName), filepath.ToSlash(pathInPackage), true
+		}
+	}
+
+	return "", "", false
+}
+
+// formatCoverage formats the number of blocks which were executed as a percentage.
+func formatCoverage(numCovered int, numBlocks int) string {
+	if numBlocks == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.1f%% (%d/%d)", 100*float64(numCovered)/float64(numBlocks), numCovered, numBlocks)
+}
@@ -0,0 +1,126 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_repository"
+)
+
+func TestCoverageReports(t *testing.T) {
+	Convey("Given a package with a dependency and a test case", t, func() {
+		var kpmHomeDir = t.TempDir()
+		var packageDir = t.TempDir()
+		writeTestFiles(packageDir, map[string]string{
+			constants.PackageInfoFileName: "name: test/app\nversion: 1.0.0\n",
+			constants.InterfaceFileName:   "enabled: true\nitems: []\n",
+			constants.ParametersFileName:  "{}\n",
+			"templates/main.txt": "" +
+				"{{- if .values.enabled }}\n" +
+				"on\n" +
+				"{{- else }}\n" +
+				"off\n" +
+				"{{- end }}\n" +
+				"{{- range .values.items }}\n" +
+				"- {{ . }}\n" +
+				"{{- end }}\n",
+			"dependencies/child.yaml":                    "package:\n  name: test/child\n  version: 1.0.0\nparameters: {}\n",
+			"tests/defaults/expected/main.txt":           "\non\n",
+			"tests/defaults/expected/child/greeting.txt": "hello\n",
+		})
+		writeTestPackage(kpmHomeDir, "test/child", "1.0.0", map[string]string{
+			constants.InterfaceFileName:  "{}\n",
+			constants.ParametersFileName: "{}\n",
+			"templates/greeting.txt":     "{{ \"hello\" }}\n",
+		})
+
+		var reportPath = filepath.Join(t.TempDir(), "coverage")
+		var writeReport = func(coverageFormat string) string {
+			var _, err = captureOutput(func() error {
+				return TestCmd(packageDir, kpmHomeDir, template_repository.NewRepositoryCollection(), &TestOptions{
+					Offline:         true,
+					ReportFormat:    TestReportFormatTap,
+					CoverageOutPath: reportPath,
+					CoverageFormat:  coverageFormat,
+				})
+			})
+			So(err, ShouldBeNil)
+
+			var data []byte
+			data, err = os.ReadFile(reportPath)
+			So(err, ShouldBeNil)
+			return string(data)
+		}
+
+		var childDir = template_package.GetPackageDir(kpmHomeDir, "test/child-1.0.0")
+
+		Convey("LCOV reports record lines and branches, with dependencies mapped to the KPM home directory", func() {
+			So(writeReport(CoverageFormatLcov), ShouldEqual, ""+
+				"TN:test/app-1.0.0\n"+
+				"SF:"+filepath.Join(packageDir, "dependencies", "child.yaml")+"\n"+
+				"BRF:0\nBRH:0\nLF:0\nLH:0\nend_of_record\n"+
+				"TN:test/app-1.0.0\n"+
+				"SF:"+filepath.Join(packageDir, "interface.yaml")+"\n"+
+				"BRF:0\nBRH:0\nLF:0\nLH:0\nend_of_record\n"+
+				"TN:test/app-1.0.0\n"+
+				"SF:"+filepath.Join(packageDir, "templates", "main.txt")+"\n"+
+				"BRDA:1,0,0,1\n"+
+				"BRDA:3,0,1,0\n"+
+				"BRDA:6,1,0,0\n"+
+				"BRDA:6,1,1,1\n"+
+				"BRF:4\nBRH:2\n"+
+				"DA:1,1\nDA:3,0\nDA:6,1\nDA:7,0\n"+
+				"LF:4\nLH:2\nend_of_record\n"+
+				"TN:test/app-1.0.0\n"+
+				"SF:"+filepath.Join(childDir, "interface.yaml")+"\n"+
+				"BRF:0\nBRH:0\nLF:0\nLH:0\nend_of_record\n"+
+				"TN:test/app-1.0.0\n"+
+				"SF:"+filepath.Join(childDir, "templates", "greeting.txt")+"\n"+
+				"BRF:0\nBRH:0\n"+
+				"DA:1,1\n"+
+				"LF:1\nLH:1\nend_of_record\n")
+		})
+
+		Convey("Paths which aren't in a package are left unchanged", func() {
+			var reporter = &coverageReporter{packageFullName: "test/app-1.0.0", testKpmHomeDir: t.TempDir()}
+			var filePath = filepath.Join(packageDir, "templates", "main.txt")
+			So(reporter.getDisplayPath(filePath), ShouldEqual, filePath)
+			So(reporter.getSourcePath(filePath), ShouldEqual, filePath)
+		})
+
+		Convey("Listings annotate each line with its count", func() {
+			So(writeReport(CoverageFormatListing), ShouldEqual, ""+
+				"==> dependencies/child.yaml (-) <==\n"+
+				"        -:    1: package:\n"+
+				"        -:    2:   name: test/child\n"+
+				"        -:    3:   version: 1.0.0\n"+
+				"        -:    4: parameters: {}\n"+
+				"\n"+
+				"==> interface.yaml (-) <==\n"+
+				"        -:    1: enabled: true\n"+
+				"        -:    2: items: []\n"+
+				"\n"+
+				"==> templates/main.txt (40.0% (2/5)) <==\n"+
+				"        1:    1: {{- if .values.enabled }}\n"+
+				"        -:    2: on\n"+
+				"    #####:    3: {{- else }}\n"+
+				"        -:    4: off\n"+
+				"        -:    5: {{- end }}\n"+
+				"       1*:    6: {{- range .values.items }}\n"+
+				"    #####:    7: - {{ . }}\n"+
+				"        -:    8: {{- end }}\n"+
+				"\n"+
+				"==> test/child-1.0.0/interface.yaml (-) <==\n"+
+				"        -:    1: {}\n"+
+				"\n"+
+				"==> test/child-1.0.0/templates/greeting.txt (100.0% (1/1)) <==\n"+
+				"        1:    1: {{ \"hello\" }}\n"+
+				"\n")
+		})
+	})
+}
@@ -0,0 +1,127 @@
+package pkg
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+// TestReportFormatTap prints the test report in the Test Anything Protocol (TAP) format.
+const TestReportFormatTap = "tap"
+
+// TestReportFormatJunit prints the test report as JUnit XML.
+const TestReportFormatJunit = "junit"
+
+// TestReportFormats is the list of supported test report formats.
+var TestReportFormats = []string{TestReportFormatTap, TestReportFormatJunit}
+
+// getTestReport formats the results of a package's test cases.
+func getTestReport(packageInfo *template_package.PackageInfo, results []*testResult, reportFormat string) ([]byte, error) {
+	switch reportFormat {
+	case TestReportFormatTap:
+		return getTapReport(results), nil
+	case TestReportFormatJunit:
+		return getJunitReport(packageInfo, results)
+	default:
+		return nil, fmt.Errorf("unknown test report format \"%s\", expected one of: %s", reportFormat, strings.Join(TestReportFormats, ", "))
+	}
+}
+
+// getTapReport formats test results as TAP version 13.  Failures are written as diagnostic lines after the test line.
+func getTapReport(results []*testResult) []byte {
+	var builder = new(strings.Builder)
+	fmt.Fprintf(builder, "TAP version 13\n1..%d\n", len(results))
+	for i, result := range results {
+		if result.failure == "" {
+			fmt.Fprintf(builder, "ok %d - %s\n", i+1, result.name)
+			if result.updated {
+				builder.WriteString("# updated expected output\n")
+			}
+			continue
+		}
+
+		fmt.Fprintf(builder, "not ok %d - %s\n", i+1, result.name)
+		for _, line := range strings.Split(strings.TrimSuffix(result.failure, "\n"), "\n") {
+			fmt.Fprintf(builder, "# %s\n", line)
+		}
+	}
+
+	return []byte(builder.String())
+}
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName    xml.Name          `xml:"testsuites"`
+	TestSuites []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Errors    int              `xml:"errors,attr"`
+	Time      string           `xml:"time,attr"`
+	TestCases []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",cdata"`
+}
+
+// getJunitReport formats test results as JUnit XML, with a test suite for the package.  Test cases which generated
+// unexpected output are failures, and test cases where the package failed to run are errors.
+func getJunitReport(packageInfo *template_package.PackageInfo, results []*testResult) ([]byte, error) {
+	var suite = &junitTestSuite{
+		Name:      template_package.GetPackageFullName(packageInfo.Name, packageInfo.Version),
+		Tests:     len(results),
+		TestCases: make([]*junitTestCase, 0, len(results)),
+	}
+
+	var totalDuration time.Duration
+	for _, result := range results {
+		totalDuration += result.duration
+
+		var junitCase = &junitTestCase{
+			Name:      result.name,
+			ClassName: packageInfo.Name,
+			Time:      formatJunitDuration(result.duration),
+		}
+		if result.failure != "" {
+			var message = strings.SplitN(result.failure, "\n", 2)[0]
+			if result.isError {
+				suite.Errors++
+				junitCase.Error = &junitFailure{Message: message, Contents: result.failure}
+			} else {
+				suite.Failures++
+				junitCase.Failure = &junitFailure{Message: "output does not match the expected output", Contents: result.failure}
+			}
+		}
+
+		suite.TestCases = append(suite.TestCases, junitCase)
+	}
+	suite.Time = formatJunitDuration(totalDuration)
+
+	var result, err = xml.MarshalIndent(&junitTestSuites{TestSuites: []*junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize test report to xml: %s", err)
+	}
+
+	return append([]byte(xml.Header), result...), nil
+}
+
+// formatJunitDuration formats a duration in seconds, which is how JUnit reports record time.
+func formatJunitDuration(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
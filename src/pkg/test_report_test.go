@@ -0,0 +1,84 @@
+package pkg
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+func TestTestReports(t *testing.T) {
+	Convey("Given the results of some test cases", t, func() {
+		var results = []*testResult{
+			{name: "passed"},
+			{name: "updated", updated: true},
+			{name: "changed", failure: "--- expected/a.txt\n+++ actual/a.txt\n"},
+			{name: "broken", failure: "failed to execute package\ndetails", isError: true},
+		}
+
+		Convey("TAP reports show failures as diagnostic lines", func() {
+			var report, err = getTestReport(nil, results, TestReportFormatTap)
+			So(err, ShouldBeNil)
+			So(string(report), ShouldEqual, `TAP version 13
+1..4
+ok 1 - passed
+ok 2 - updated
+# updated expected output
+not ok 3 - changed
+# --- expected/a.txt
+# +++ actual/a.txt
+not ok 4 - broken
+# failed to execute package
+# details
+`)
+		})
+
+		Convey("JUnit reports separate failures from errors", func() {
+			var report, err = getTestReport(&template_package.PackageInfo{Name: "test/package", Version: "1.0.0"}, results, TestReportFormatJunit)
+			So(err, ShouldBeNil)
+			So(string(report), ShouldContainSubstring, `<testsuite name="test/package-1.0.0" tests="4" failures="1" errors="1"`)
+			So(string(report), ShouldContainSubstring, `<failure message="output does not match the expected output"><![CDATA[--- expected/a.txt`)
+			So(string(report), ShouldContainSubstring, `<error message="failed to execute package"><![CDATA[failed to execute package`)
+		})
+	})
+}
+
+func TestExpectedOutput(t *testing.T) {
+	Convey("Given an expected output directory which was written from generated files", t, func() {
+		var expectedDir = t.TempDir()
+		So(writeExpectedDir(expectedDir, []*template_package.OutputFile{
+			{Path: "a.txt", Mode: 0644, Data: []byte("a\n")},
+			{Path: "nested/b.txt", Mode: 0644, Data: []byte("b\n")},
+		}), ShouldBeNil)
+
+		Convey("The same files have no differences", func() {
+			var differences, err = diffExpectedDir(expectedDir, []*template_package.OutputFile{
+				{Path: "a.txt", Data: []byte("a\n")},
+				{Path: "nested/b.txt", Data: []byte("b\n")},
+			})
+			So(err, ShouldBeNil)
+			So(differences, ShouldBeEmpty)
+		})
+
+		Convey("Changed, missing and unexpected files are shown in the diff", func() {
+			var differences, err = diffExpectedDir(expectedDir, []*template_package.OutputFile{
+				{Path: "a.txt", Data: []byte("changed\n")},
+				{Path: "c.txt", Data: []byte{}},
+			})
+			So(err, ShouldBeNil)
+			So(differences, ShouldEqual, `--- expected/a.txt
++++ actual/a.txt
+@@ -1,1 +1,1 @@
+-a
++changed
+--- /dev/null
++++ actual/c.txt
+--- expected/nested/b.txt
++++ /dev/null
+@@ -1,1 +0,0 @@
+-b
+`)
+		})
+	})
+}
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_repository"
+)
+
+func TestTestCmd(t *testing.T) {
+	Convey("Given a package with test cases", t, func() {
+		var kpmHomeDir = t.TempDir()
+		var packageDir = t.TempDir()
+		writeTestFiles(packageDir, map[string]string{
+			constants.PackageInfoFileName: "name: test/app\nversion: 1.0.0\n",
+			constants.InterfaceFileName:   "greeting: {{ .greeting }}\n",
+			constants.ParametersFileName:  "greeting: hello\n",
+			"templates/greeting.txt":      "{{ .values.greeting }}\n",
+
+			// Compared with an expected output directory
+			"tests/defaults/expected/greeting.txt": "hello\n",
+
+			// Compared with a snapshot
+			"tests/custom/parameters.yaml": "greeting: hi\n",
+			"tests/custom/snapshot.yaml":   "---\n# Source: custom/greeting.txt\nhi\n",
+		})
+
+		var testsDir = filepath.Join(packageDir, constants.TestsDirName)
+		var runTests = func(update bool) (string, error) {
+			return captureOutput(func() error {
+				return TestCmd(packageDir, kpmHomeDir, template_repository.NewRepositoryCollection(), &TestOptions{
+					Offline:      true,
+					Update:       update,
+					ReportFormat: TestReportFormatTap,
+				})
+			})
+		}
+
+		var readTestFile = func(relativePath string) string {
+			var data, err = os.ReadFile(filepath.Join(testsDir, filepath.FromSlash(relativePath)))
+			So(err, ShouldBeNil)
+			return string(data)
+		}
+
+		Convey("Test cases which match their expected output pass", func() {
+			var output, err = runTests(false)
+			So(err, ShouldBeNil)
+			So(output, ShouldEqual, "TAP version 13\n1..2\nok 1 - custom\nok 2 - defaults\n")
+		})
+
+		Convey("Given test cases which don't match their expected output", func() {
+			writeTestFiles(testsDir, map[string]string{
+				"defaults/expected/greeting.txt": "goodbye\n",
+				"custom/snapshot.yaml":           "---\n# Source: custom/greeting.txt\nbye\n",
+			})
+
+			Convey("The test cases fail with a diff", func() {
+				var output, err = runTests(false)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "2 of 2 test case(s) failed")
+				So(output, ShouldContainSubstring, "not ok 1 - custom\n# --- expected/snapshot.yaml\n# +++ actual/snapshot.yaml\n")
+				So(output, ShouldContainSubstring, "# -bye\n# +hi\n")
+				So(output, ShouldContainSubstring, "not ok 2 - defaults\n# --- expected/greeting.txt\n# +++ actual/greeting.txt\n")
+				So(output, ShouldContainSubstring, "# -goodbye\n# +hello\n")
+			})
+
+			Convey("Updating rewrites the expected output", func() {
+				var _, err = runTests(true)
+				So(err, ShouldBeNil)
+				So(readTestFile("defaults/expected/greeting.txt"), ShouldEqual, "hello\n")
+				So(readTestFile("custom/snapshot.yaml"), ShouldEqual, "---\n# Source: custom/greeting.txt\nhi\n")
+
+				_, err = runTests(false)
+				So(err, ShouldBeNil)
+			})
+		})
+
+		Convey("Given a test case without any expected output", func() {
+			writeTestFiles(testsDir, map[string]string{
+				"new/parameters.yaml": "greeting: hey\n",
+			})
+
+			Convey("The test case is reported as an error", func() {
+				var output, err = runTests(false)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, "1 of 3 test case(s) failed")
+				So(output, ShouldContainSubstring, "not ok 3 - new\n# test case has no \"expected\" directory or \"snapshot.yaml\" file")
+			})
+
+			Convey("Updating creates a snapshot", func() {
+				var _, err = runTests(true)
+				So(err, ShouldBeNil)
+				So(readTestFile("new/snapshot.yaml"), ShouldEqual, "---\n# Source: new/greeting.txt\nhey\n")
+				So(files.DirExists(filepath.Join(testsDir, "new", constants.TestExpectedDirName), "expected output"), ShouldNotBeNil)
+
+				var output string
+				output, err = runTests(false)
+				So(err, ShouldBeNil)
+				So(output, ShouldEqual, "TAP version 13\n1..3\nok 1 - custom\nok 2 - defaults\nok 3 - new\n")
+			})
+		})
+
+		Convey("A package without test cases fails", func() {
+			So(os.RemoveAll(testsDir), ShouldBeNil)
+			So(os.MkdirAll(testsDir, os.ModePerm), ShouldBeNil)
+
+			var _, err = runTests(false)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "no test cases were found")
+		})
+	})
+}
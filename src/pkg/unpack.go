@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/rohitramu/kpm/src/pkg/utils/archive"
 	"github.com/rohitramu/kpm/src/pkg/utils/files"
 	"github.com/rohitramu/kpm/src/pkg/utils/log"
 	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
@@ -56,6 +57,72 @@ func UnpackCmd(
 	log.Verbosef("Package name:      %s", packageName)
 	log.Verbosef("Package version:   %s", packageVersion)
 	log.Verbosef("Package directory: %s", packageDir)
+	log.Verbosef("====")
+
+	return exportPackage(packageDir, packageFullName, exportDir, exportName, userHasConfirmed)
+}
+
+// UnpackArchiveCmd exports the template package in a package archive to the specified path.  If the export name is
+// empty, the default export name is used.
+func UnpackArchiveCmd(
+	archivePath string,
+	exportDir string,
+	exportName string,
+	userHasConfirmed bool,
+) (err error) {
+	// Get archive path
+	var archiveAbsPath string
+	archiveAbsPath, err = files.GetAbsolutePath(archivePath)
+	if err != nil {
+		return err
+	}
+
+	// Extract the archive (which also verifies it) to a temporary directory.
+	var tempDir string
+	tempDir, err = os.MkdirTemp("", "kpm-unpack-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	log.Debugf("Extracting package archive: %s", archiveAbsPath)
+	if _, err = archive.ExtractPackageArchiveFile(archiveAbsPath, tempDir); err != nil {
+		return err
+	}
+
+	// Validate package and get package info
+	var packageInfo *template_package.PackageInfo
+	packageInfo, err = template_package.GetPackageInfo(tempDir)
+	if err != nil {
+		return fmt.Errorf("package archive does not contain a valid template package: %s", err)
+	}
+
+	if exportName == "" {
+		exportName = template_package.GetDefaultExportName(packageInfo.Name, packageInfo.Version)
+	}
+
+	// Log resolved values
+	log.Verbosef("====")
+	log.Verbosef("Package name:      %s", packageInfo.Name)
+	log.Verbosef("Package version:   %s", packageInfo.Version)
+	log.Verbosef("Package archive:   %s", archiveAbsPath)
+	log.Verbosef("====")
+
+	return exportPackage(tempDir, packageInfo.String(), exportDir, exportName, userHasConfirmed)
+}
+
+// exportPackage copies a template package directory to the specified path.
+func exportPackage(
+	packageDir string,
+	packageFullName string,
+	exportDir string,
+	exportName string,
+	userHasConfirmed bool,
+) error {
+	var err error
+
+	// Log resolved values
+	log.Verbosef("====")
 	log.Verbosef("Export name:       %s", exportName)
 	log.Verbosef("Export directory:  %s", exportDir)
 	log.Verbosef("====")
@@ -63,7 +130,7 @@ func UnpackCmd(
 	// Get full export path
 	var exportPath = filepath.Join(exportDir, exportName)
 
-	if err = files.DeleteDirIfExists(exportDir, "export", userHasConfirmed); err != nil {
+	if err = files.DeleteDirIfExists(exportPath, "export", userHasConfirmed); err != nil {
 		return err
 	}
 
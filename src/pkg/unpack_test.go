@@ -0,0 +1,40 @@
+package pkg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestExportPackage(t *testing.T) {
+	Convey("Given an export directory which already contains other exports", t, func() {
+		var packageDir = t.TempDir()
+		So(os.WriteFile(filepath.Join(packageDir, "package.yaml"), []byte("name: test/hello\nversion: 1.0.0\n"), 0644), ShouldBeNil)
+
+		var exportDir = t.TempDir()
+		var siblingFilePath = filepath.Join(exportDir, "other-1.0.0", "package.yaml")
+		So(os.MkdirAll(filepath.Dir(siblingFilePath), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(siblingFilePath, []byte("name: test/other\nversion: 1.0.0\n"), 0644), ShouldBeNil)
+
+		var staleFilePath = filepath.Join(exportDir, "hello-1.0.0", "stale.txt")
+		So(os.MkdirAll(filepath.Dir(staleFilePath), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(staleFilePath, []byte("stale\n"), 0644), ShouldBeNil)
+
+		Convey("Exporting a package only replaces its own export", func() {
+			So(exportPackage(packageDir, "test/hello-1.0.0", exportDir, "hello-1.0.0", true), ShouldBeNil)
+
+			var data, err = os.ReadFile(filepath.Join(exportDir, "hello-1.0.0", "package.yaml"))
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "name: test/hello\nversion: 1.0.0\n")
+
+			_, err = os.Stat(staleFilePath)
+			So(os.IsNotExist(err), ShouldBeTrue)
+
+			data, err = os.ReadFile(siblingFilePath)
+			So(err, ShouldBeNil)
+			So(string(data), ShouldEqual, "name: test/other\nversion: 1.0.0\n")
+		})
+	})
+}
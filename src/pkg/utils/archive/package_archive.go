@@ -0,0 +1,265 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+)
+
+// PackageArchiveManifestApiVersion is the version of the package archive manifest format.
+const PackageArchiveManifestApiVersion = "v1"
+
+// packageArchiveManifestName is the name of the manifest entry, which must be the first entry in a package archive.
+const packageArchiveManifestName = "manifest.json"
+
+// packageArchiveContentPrefix is the prefix of the names of entries which contain the package's files.
+const packageArchiveContentPrefix = "package/"
+
+// maxManifestSize is the largest manifest that we are willing to read.
+const maxManifestSize = 16 * 1024 * 1024
+
+// PackageArchiveManifest lists the digest of every file in a package archive.
+type PackageArchiveManifest struct {
+	ApiVersion string `json:"apiVersion"`
+
+	// Files maps the path of each file in the package (using forward slashes) to its digest, in the form "sha256:<hex>".
+	Files map[string]string `json:"files"`
+}
+
+// WritePackageArchive writes a template package directory to the given writer as a package archive.  A package
+// archive is a gzip-compressed tar stream which contains a manifest, followed by the package's files.
+func WritePackageArchive(packageDir string, writer io.Writer) (err error) {
+	// Create the manifest.
+	var manifest = &PackageArchiveManifest{ApiVersion: PackageArchiveManifestApiVersion, Files: map[string]string{}}
+	err = walkFiles(packageDir, func(relativePath string, path string, fileInfo fs.FileInfo) error {
+		if fileInfo.IsDir() {
+			return nil
+		}
+
+		var digest, err = getFileDigest(path)
+		if err != nil {
+			return err
+		}
+
+		manifest.Files[relativePath] = digest
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create manifest for package archive: %s", err)
+	}
+
+	var manifestBytes []byte
+	manifestBytes, err = json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize package archive manifest: %s", err)
+	}
+
+	// Write the manifest and then the files.
+	var gzipWriter = gzip.NewWriter(writer)
+	var tarWriter = tar.NewWriter(gzipWriter)
+
+	var manifestHeader = &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     packageArchiveManifestName,
+		Mode:     0644,
+		Size:     int64(len(manifestBytes)),
+	}
+	normalizeHeader(manifestHeader)
+	if err = tarWriter.WriteHeader(manifestHeader); err != nil {
+		return err
+	}
+	if _, err = tarWriter.Write(manifestBytes); err != nil {
+		return err
+	}
+
+	err = writeDirToTar(tarWriter, packageDir, packageArchiveContentPrefix)
+	if err != nil {
+		return err
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
+
+// WritePackageArchiveFile writes a template package directory to a package archive file, replacing it if it exists.
+func WritePackageArchiveFile(packageDir string, archivePath string) (err error) {
+	var file *os.File
+	file, err = os.Create(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to create package archive: %s", err)
+	}
+	defer func() {
+		err = errors.Join(err, file.Close())
+	}()
+
+	return WritePackageArchive(packageDir, file)
+}
+
+// ExtractPackageArchive extracts a package archive into the given directory, which is created if it doesn't exist.
+// Every file is checked against the archive's manifest.  If an error is returned, the directory may contain some of
+// the archive's files, so callers should extract into a temporary directory.
+func ExtractPackageArchive(reader io.Reader, dstDir string) (manifest *PackageArchiveManifest, err error) {
+	var gzipReader *gzip.Reader
+	gzipReader, err = gzip.NewReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("package archive is not gzip-compressed: %s", err)
+	}
+	defer gzipReader.Close()
+
+	var tarReader = tar.NewReader(gzipReader)
+
+	// The manifest must come first.
+	manifest, err = readPackageArchiveManifest(tarReader)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.MkdirAll(dstDir, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	var extractedFiles = map[string]bool{}
+	for {
+		var header *tar.Header
+		header, err = tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package archive: %s", err)
+		}
+
+		var relativePath, isPackageFile = strings.CutPrefix(header.Name, packageArchiveContentPrefix)
+		if !isPackageFile {
+			return nil, fmt.Errorf("unexpected entry in package archive: %s", header.Name)
+		}
+
+		var dstPath string
+		dstPath, err = GetSafeExtractionPath(dstDir, relativePath)
+		if err != nil {
+			return nil, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(dstPath, os.ModePerm)
+		case tar.TypeReg:
+			var expectedDigest, found = manifest.Files[relativePath]
+			if !found {
+				return nil, fmt.Errorf("file in package archive is not in the manifest: %s", relativePath)
+			}
+
+			// Calculate the digest while writing the file.
+			var hash = sha256.New()
+			err = writeFileFrom(dstPath, io.TeeReader(tarReader, hash))
+			if err == nil && formatDigest(hash.Sum(nil)) != expectedDigest {
+				err = fmt.Errorf("file in package archive does not match the digest in the manifest: %s", relativePath)
+			}
+
+			extractedFiles[relativePath] = true
+		default:
+			err = fmt.Errorf("unsupported entry type in package archive: %s", header.Name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Make sure nothing is missing.
+	var missingFiles []string
+	for relativePath := range manifest.Files {
+		if !extractedFiles[relativePath] {
+			missingFiles = append(missingFiles, relativePath)
+		}
+	}
+	if len(missingFiles) > 0 {
+		sort.Strings(missingFiles)
+		return nil, fmt.Errorf("package archive is missing files which are in the manifest: %q", missingFiles)
+	}
+
+	return manifest, nil
+}
+
+// ExtractPackageArchiveFile extracts a package archive file into the given directory.
+func ExtractPackageArchiveFile(archivePath string, dstDir string) (manifest *PackageArchiveManifest, err error) {
+	var file *os.File
+	file, err = os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open package archive: %s", err)
+	}
+	defer file.Close()
+
+	manifest, err = ExtractPackageArchive(file, dstDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid package archive '%s': %s", archivePath, err)
+	}
+
+	return manifest, nil
+}
+
+func readPackageArchiveManifest(tarReader *tar.Reader) (*PackageArchiveManifest, error) {
+	var header, err = tarReader.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package archive: %s", err)
+	}
+	if header.Name != packageArchiveManifestName || header.Typeflag != tar.TypeReg {
+		return nil, fmt.Errorf("package archive must start with '%s', found: %s", packageArchiveManifestName, header.Name)
+	}
+	if header.Size > maxManifestSize {
+		return nil, fmt.Errorf("package archive manifest is too large: %d bytes", header.Size)
+	}
+
+	var manifestBytes = new(bytes.Buffer)
+	if _, err = io.Copy(manifestBytes, tarReader); err != nil {
+		return nil, fmt.Errorf("failed to read package archive manifest: %s", err)
+	}
+
+	var manifest = new(PackageArchiveManifest)
+	if err = json.Unmarshal(manifestBytes.Bytes(), manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse package archive manifest: %s", err)
+	}
+
+	if manifest.ApiVersion != PackageArchiveManifestApiVersion {
+		return nil, fmt.Errorf(
+			"package archive manifest has unsupported apiVersion '%s' (expected '%s')",
+			manifest.ApiVersion,
+			PackageArchiveManifestApiVersion,
+		)
+	}
+
+	return manifest, nil
+}
+
+func getFileDigest(path string) (string, error) {
+	var file, err = os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var hash = sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return formatDigest(hash.Sum(nil)), nil
+}
+
+func formatDigest(hash []byte) string {
+	return "sha256:" + hex.EncodeToString(hash)
+}
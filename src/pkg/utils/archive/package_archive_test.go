@@ -0,0 +1,120 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testEntry struct {
+	name    string
+	content string
+}
+
+// writeTestArchive creates a gzip-compressed tar stream with the given regular files, in order.
+func writeTestArchive(t *testing.T, entries []testEntry) *bytes.Buffer {
+	t.Helper()
+
+	var result = new(bytes.Buffer)
+	var gzipWriter = gzip.NewWriter(result)
+	var tarWriter = tar.NewWriter(gzipWriter)
+	for _, entry := range entries {
+		var header = &tar.Header{Typeflag: tar.TypeReg, Name: entry.name, Mode: 0644, Size: int64(len(entry.content))}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tarWriter.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return result
+}
+
+func TestPackageArchive(t *testing.T) {
+	Convey("Given a package directory", t, func() {
+		var packageDir = t.TempDir()
+		So(os.MkdirAll(filepath.Join(packageDir, "templates"), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(packageDir, "package.yaml"), []byte("name: kpmtool/hello\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(packageDir, "templates", "hello.txt"), []byte("hello\n"), 0644), ShouldBeNil)
+
+		var archiveBytes = new(bytes.Buffer)
+		So(WritePackageArchive(packageDir, archiveBytes), ShouldBeNil)
+
+		Convey("The archive can be extracted", func() {
+			var dstDir = t.TempDir()
+			var manifest, err = ExtractPackageArchive(archiveBytes, dstDir)
+			So(err, ShouldBeNil)
+			So(manifest.Files, ShouldContainKey, "templates/hello.txt")
+
+			var content []byte
+			content, err = os.ReadFile(filepath.Join(dstDir, "templates", "hello.txt"))
+			So(err, ShouldBeNil)
+			So(string(content), ShouldEqual, "hello\n")
+		})
+
+		Convey("Archives are reproducible", func() {
+			var secondArchiveBytes = new(bytes.Buffer)
+			So(WritePackageArchive(packageDir, secondArchiveBytes), ShouldBeNil)
+			So(secondArchiveBytes.Bytes(), ShouldResemble, archiveBytes.Bytes())
+		})
+	})
+
+	Convey("Invalid archives are rejected", t, func() {
+		var manifest = `{"apiVersion": "v1", "files": {"package.yaml": "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}}`
+
+		Convey("Files must match their digests", func() {
+			var _, err = ExtractPackageArchive(writeTestArchive(t, []testEntry{
+				{name: "manifest.json", content: manifest},
+				{name: "package/package.yaml", content: "goodbye"},
+			}), t.TempDir())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "does not match the digest")
+		})
+
+		Convey("Files must be in the manifest", func() {
+			var _, err = ExtractPackageArchive(writeTestArchive(t, []testEntry{
+				{name: "manifest.json", content: manifest},
+				{name: "package/package.yaml", content: "hello"},
+				{name: "package/extra.yaml", content: "hello"},
+			}), t.TempDir())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "not in the manifest")
+		})
+
+		Convey("Files in the manifest must be present", func() {
+			var _, err = ExtractPackageArchive(writeTestArchive(t, []testEntry{
+				{name: "manifest.json", content: manifest},
+			}), t.TempDir())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "missing files")
+		})
+
+		Convey("The manifest must come first", func() {
+			var _, err = ExtractPackageArchive(writeTestArchive(t, []testEntry{
+				{name: "package/package.yaml", content: "hello"},
+				{name: "manifest.json", content: manifest},
+			}), t.TempDir())
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Files can't be written outside the destination directory", func() {
+			var _, err = ExtractPackageArchive(writeTestArchive(t, []testEntry{
+				{name: "manifest.json", content: manifest},
+				{name: "package/../../evil.yaml", content: "hello"},
+			}), t.TempDir())
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,191 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WriteTarGz writes the contents of a directory to the given writer as a gzip-compressed tar stream.
+// Paths in the archive are relative to the directory, and always use forward slashes.
+func WriteTarGz(srcDir string, writer io.Writer) (err error) {
+	var gzipWriter = gzip.NewWriter(writer)
+	var tarWriter = tar.NewWriter(gzipWriter)
+
+	err = writeDirToTar(tarWriter, srcDir, "")
+	if err != nil {
+		return err
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
+
+// ExtractTarGz extracts a gzip-compressed tar stream into the given directory, which is created if it doesn't exist.
+// Entries which would be written outside the directory are rejected.
+func ExtractTarGz(reader io.Reader, dstDir string) (err error) {
+	var gzipReader *gzip.Reader
+	gzipReader, err = gzip.NewReader(reader)
+	if err != nil {
+		return fmt.Errorf("archive is not gzip-compressed: %s", err)
+	}
+	defer gzipReader.Close()
+
+	err = os.MkdirAll(dstDir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	var tarReader = tar.NewReader(gzipReader)
+	for {
+		var header *tar.Header
+		header, err = tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %s", err)
+		}
+
+		var dstPath string
+		dstPath, err = GetSafeExtractionPath(dstDir, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(dstPath, os.ModePerm)
+		case tar.TypeReg:
+			err = writeFileFrom(dstPath, tarReader)
+		default:
+			err = fmt.Errorf("unsupported entry type in archive: %s", header.Name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetSafeExtractionPath returns the path that an archive entry should be extracted to, or an error if the entry would escape the destination directory.
+func GetSafeExtractionPath(dstDir string, entryName string) (string, error) {
+	var cleanName = filepath.Clean(filepath.FromSlash(entryName))
+	if filepath.IsAbs(cleanName) || cleanName == ".." || strings.HasPrefix(cleanName, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("archive entry points outside the destination directory: %s", entryName)
+	}
+
+	return filepath.Join(dstDir, cleanName), nil
+}
+
+// writeDirToTar adds the contents of a directory to a tar stream, with the given prefix on each entry's name.
+func writeDirToTar(tarWriter *tar.Writer, srcDir string, namePrefix string) error {
+	var err = walkFiles(srcDir, func(relativePath string, path string, fileInfo fs.FileInfo) (err error) {
+		var header *tar.Header
+		header, err = tar.FileInfoHeader(fileInfo, "")
+		if err != nil {
+			return err
+		}
+		header.Name = namePrefix + relativePath
+		if fileInfo.IsDir() {
+			header.Name += "/"
+		}
+		normalizeHeader(header)
+
+		if err = tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if fileInfo.IsDir() {
+			return nil
+		}
+
+		return copyFileTo(path, tarWriter)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive directory: %s\n%s", srcDir, err)
+	}
+
+	return nil
+}
+
+// walkFiles calls the given function for each file and directory inside a directory (but not the directory itself).
+// The relative path always uses forward slashes.  Only regular files and directories are allowed.
+func walkFiles(srcDir string, fn func(relativePath string, path string, fileInfo fs.FileInfo) error) error {
+	return filepath.WalkDir(srcDir, func(path string, dirEntry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		// Skip the root directory itself.
+		if path == srcDir {
+			return nil
+		}
+
+		var relativePath, err = filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		var fileInfo fs.FileInfo
+		fileInfo, err = dirEntry.Info()
+		if err != nil {
+			return err
+		}
+
+		// Only regular files and directories are allowed in template packages.
+		if !fileInfo.Mode().IsRegular() && !fileInfo.IsDir() {
+			return fmt.Errorf("unsupported file type in directory: %s", path)
+		}
+
+		return fn(filepath.ToSlash(relativePath), path, fileInfo)
+	})
+}
+
+// normalizeHeader makes archives reproducible - the same files should always produce the same bytes.
+func normalizeHeader(header *tar.Header) {
+	header.ModTime = time.Unix(0, 0).UTC()
+	header.AccessTime, header.ChangeTime = time.Time{}, time.Time{}
+	header.Uid, header.Gid, header.Uname, header.Gname = 0, 0, "", ""
+}
+
+func copyFileTo(path string, writer io.Writer) error {
+	var file, err = os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(writer, file)
+
+	return err
+}
+
+func writeFileFrom(path string, reader io.Reader) error {
+	var err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	var file *os.File
+	file, err = os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, reader)
+
+	return err
+}
@@ -11,3 +11,15 @@ const HelpersDirName = "helpers"
 
 // PackagesRepositoryDirName is the name of the directory that contains packages available for use.
 const PackagesRepositoryDirName = "packages"
+
+// CacheDirName is the name of the directory in the KPM home directory where cached data (e.g. clones of remote repositories) is stored.
+const CacheDirName = "cache"
+
+// StaticFilesDirName is the name of the directory where files which are copied to the output without being executed as templates are stored.
+const StaticFilesDirName = "files"
+
+// TestsDirName is the name of the directory in a package where test cases are defined.
+const TestsDirName = "tests"
+
+// TestExpectedDirName is the name of the directory in a test case which contains the expected output files.
+const TestExpectedDirName = "expected"
@@ -5,3 +5,6 @@ const TemplateFieldPackage = "package"
 
 // TemplateFieldValues is the name of the "values" field
 const TemplateFieldValues = "values"
+
+// TemplateFieldItem is the name of the "item" field, which holds the current item when a template is executed for each item in a list
+const TemplateFieldItem = "item"
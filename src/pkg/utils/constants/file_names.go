@@ -8,3 +8,21 @@ const PackageInfoFileName = "package.yaml"
 
 // ParametersFileName is the parameters file's name
 const ParametersFileName = "parameters.yaml"
+
+// PackageIndexFileName is the name of the file which lists the packages in an HTTP repository.
+const PackageIndexFileName = "index.yaml"
+
+// PackageArchiveExtension is the file extension of template package archives.
+const PackageArchiveExtension = ".kpm"
+
+// LockFileName is the name of the file which records the exact packages that were used to generate output.
+const LockFileName = "kpm.lock"
+
+// ManifestFileName is the name of the file in a package's output directory which records the files that KPM generated.
+const ManifestFileName = ".kpm_manifest.yaml"
+
+// ParametersSchemaFileName is the name of the optional JSON Schema file which describes a package's parameters.
+const ParametersSchemaFileName = "parameters.schema.json"
+
+// TestSnapshotFileName is the name of the file in a test case which contains the expected output as YAML documents.
+const TestSnapshotFileName = "snapshot.yaml"
@@ -0,0 +1,310 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// NullFileName is the name used in diffs for a file which doesn't exist.
+const NullFileName = "/dev/null"
+
+// contextLines is the number of unchanged lines which are shown around each change.
+const contextLines = 3
+
+type editKind int
+
+const (
+	editEqual editKind = iota
+	editDelete
+	editInsert
+)
+
+// edit is a single line of an edit script which transforms one list of lines into another.
+type edit struct {
+	kind editKind
+	line string
+
+	// The indexes of the line in the old and new lists before this edit is applied.
+	oldIndex int
+	newIndex int
+}
+
+// IsBinary returns true if the data doesn't look like text, so it shouldn't be diffed line by line.
+func IsBinary(data []byte) bool {
+	return bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data)
+}
+
+// Unified returns the differences between two files in the unified diff format, or an empty string if they are the
+// same.  Binary files are only reported as different.
+func Unified(oldName string, newName string, oldData []byte, newData []byte) string {
+	if bytes.Equal(oldData, newData) {
+		return ""
+	}
+
+	if IsBinary(oldData) || IsBinary(newData) {
+		return fmt.Sprintf("Binary files %s and %s differ\n", oldName, newName)
+	}
+
+	var edits = getEdits(splitLines(string(oldData)), splitLines(string(newData)))
+
+	var result = new(strings.Builder)
+	fmt.Fprintf(result, "--- %s\n+++ %s\n", oldName, newName)
+	for _, hunk := range getHunks(edits) {
+		writeHunk(result, hunk)
+	}
+
+	return result.String()
+}
+
+// splitLines splits text into lines, keeping the line endings.
+func splitLines(text string) []string {
+	var result = strings.SplitAfter(text, "\n")
+	if result[len(result)-1] == "" {
+		result = result[:len(result)-1]
+	}
+
+	return result
+}
+
+// getEdits returns the shortest edit script which transforms the old lines into the new lines, using the linear space
+// variant of Myers' algorithm.
+func getEdits(oldLines []string, newLines []string) []*edit {
+	var builder = &editScriptBuilder{
+		oldLines: oldLines,
+		newLines: newLines,
+		edits:    make([]*edit, 0, len(oldLines)+len(newLines)),
+	}
+	builder.compare(0, len(oldLines), 0, len(newLines))
+
+	return builder.edits
+}
+
+// editScriptBuilder builds an edit script by splitting the lines into smaller parts around the middle of the shortest
+// edit script, so that only the furthest points reached on each diagonal need to be kept.
+type editScriptBuilder struct {
+	oldLines []string
+	newLines []string
+	edits    []*edit
+}
+
+// compare adds the edits which transform "oldLines[oldStart:oldEnd]" into "newLines[newStart:newEnd]".
+func (builder *editScriptBuilder) compare(oldStart int, oldEnd int, newStart int, newEnd int) {
+	// Lines at the start and end which are the same don't need to be searched
+	for oldStart < oldEnd && newStart < newEnd && builder.oldLines[oldStart] == builder.newLines[newStart] {
+		builder.add(editEqual, oldStart, newStart)
+		oldStart++
+		newStart++
+	}
+	var commonSuffixLength = 0
+	for oldStart < oldEnd-commonSuffixLength && newStart < newEnd-commonSuffixLength &&
+		builder.oldLines[oldEnd-commonSuffixLength-1] == builder.newLines[newEnd-commonSuffixLength-1] {
+		commonSuffixLength++
+	}
+	oldEnd -= commonSuffixLength
+	newEnd -= commonSuffixLength
+
+	if oldStart < oldEnd && newStart < newEnd {
+		if oldSplit, newSplit, found := builder.bisect(oldStart, oldEnd, newStart, newEnd); found {
+			builder.compare(oldStart, oldSplit, newStart, newSplit)
+			builder.compare(oldSplit, oldEnd, newSplit, newEnd)
+		} else {
+			builder.replace(oldStart, oldEnd, newStart, newEnd)
+		}
+	} else {
+		builder.replace(oldStart, oldEnd, newStart, newEnd)
+	}
+
+	for i := 0; i < commonSuffixLength; i++ {
+		builder.add(editEqual, oldEnd+i, newEnd+i)
+	}
+}
+
+// bisect finds a point in the middle of the shortest edit script which transforms "oldLines[oldStart:oldEnd]" into
+// "newLines[newStart:newEnd]", by searching forwards from the start and backwards from the end until the searches meet.
+// The first and last lines of each range must be different.
+func (builder *editScriptBuilder) bisect(oldStart int, oldEnd int, newStart int, newEnd int) (oldSplit int, newSplit int, found bool) {
+	var n, m = oldEnd - oldStart, newEnd - newStart
+	var maxD = (n + m + 1) / 2
+
+	// forward[offset+k] is the furthest x reached on diagonal k from the start, and backward[offset+k] is the furthest
+	// x reached on diagonal k from the end (measured from the end), or -1 if the diagonal hasn't been reached
+	var offset = maxD
+	var forward = make([]int, 2*maxD+2)
+	var backward = make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	// If the total number of lines is odd, the forward search reaches the middle first
+	var delta = n - m
+	var forwardMeetsBackward = delta%2 != 0
+
+	// Diagonals which run off the edges of the ranges don't need to be searched again
+	var forwardKStart, forwardKEnd, backwardKStart, backwardKEnd = 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + forwardKStart; k <= d-forwardKEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+
+			var y = x - k
+			for x < n && y < m && builder.oldLines[oldStart+x] == builder.newLines[newStart+y] {
+				x++
+				y++
+			}
+
+			forward[offset+k] = x
+			if x > n {
+				forwardKEnd += 2
+			} else if y > m {
+				forwardKStart += 2
+			} else if forwardMeetsBackward {
+				var backwardIndex = offset + delta - k
+				if backwardIndex >= 0 && backwardIndex < len(backward) && backward[backwardIndex] != -1 && x >= n-backward[backwardIndex] {
+					return oldStart + x, newStart + y, true
+				}
+			}
+		}
+
+		for k := -d + backwardKStart; k <= d-backwardKEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+
+			var y = x - k
+			for x < n && y < m && builder.oldLines[oldEnd-x-1] == builder.newLines[newEnd-y-1] {
+				x++
+				y++
+			}
+
+			backward[offset+k] = x
+			if x > n {
+				backwardKEnd += 2
+			} else if y > m {
+				backwardKStart += 2
+			} else if !forwardMeetsBackward {
+				var forwardIndex = offset + delta - k
+				if forwardIndex >= 0 && forwardIndex < len(forward) && forward[forwardIndex] != -1 {
+					var forwardX = forward[forwardIndex]
+					var forwardY = forwardX - (forwardIndex - offset)
+					if forwardX >= n-x {
+						return oldStart + forwardX, newStart + forwardY, true
+					}
+				}
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+// replace adds edits which delete all of the old lines in a range, and then insert all of the new lines.
+func (builder *editScriptBuilder) replace(oldStart int, oldEnd int, newStart int, newEnd int) {
+	for x := oldStart; x < oldEnd; x++ {
+		builder.add(editDelete, x, newStart)
+	}
+	for y := newStart; y < newEnd; y++ {
+		builder.add(editInsert, oldEnd, y)
+	}
+}
+
+// add adds an edit for the line at the given indexes.
+func (builder *editScriptBuilder) add(kind editKind, oldIndex int, newIndex int) {
+	var line string
+	if kind == editInsert {
+		line = builder.newLines[newIndex]
+	} else {
+		line = builder.oldLines[oldIndex]
+	}
+
+	builder.edits = append(builder.edits, &edit{kind: kind, line: line, oldIndex: oldIndex, newIndex: newIndex})
+}
+
+// getHunks groups the changes in an edit script with the unchanged lines around them.
+func getHunks(edits []*edit) [][]*edit {
+	var result = [][]*edit{}
+
+	var hunkStart, hunkEnd = -1, -1
+	for i, e := range edits {
+		if e.kind == editEqual {
+			continue
+		}
+
+		var start = i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		var end = i + contextLines + 1
+		if end > len(edits) {
+			end = len(edits)
+		}
+
+		// Merge changes which are close together into the same hunk
+		if hunkStart >= 0 && start <= hunkEnd {
+			hunkEnd = end
+			continue
+		}
+
+		if hunkStart >= 0 {
+			result = append(result, edits[hunkStart:hunkEnd])
+		}
+		hunkStart, hunkEnd = start, end
+	}
+
+	if hunkStart >= 0 {
+		result = append(result, edits[hunkStart:hunkEnd])
+	}
+
+	return result
+}
+
+// writeHunk writes a hunk in the unified diff format.
+func writeHunk(builder *strings.Builder, hunk []*edit) {
+	var oldCount, newCount = 0, 0
+	for _, e := range hunk {
+		if e.kind != editInsert {
+			oldCount++
+		}
+		if e.kind != editDelete {
+			newCount++
+		}
+	}
+
+	// Empty ranges start at the line before them
+	var oldStart, newStart = hunk[0].oldIndex, hunk[0].newIndex
+	if oldCount > 0 {
+		oldStart++
+	}
+	if newCount > 0 {
+		newStart++
+	}
+
+	fmt.Fprintf(builder, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+	for _, e := range hunk {
+		var prefix = " "
+		switch e.kind {
+		case editDelete:
+			prefix = "-"
+		case editInsert:
+			prefix = "+"
+		}
+
+		builder.WriteString(prefix)
+		builder.WriteString(e.line)
+		if !strings.HasSuffix(e.line, "\n") {
+			builder.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUnified(t *testing.T) {
+	Convey("Identical files have no differences", t, func() {
+		So(Unified("a/x", "b/x", []byte("a\nb\n"), []byte("a\nb\n")), ShouldBeEmpty)
+	})
+
+	Convey("Changed lines are shown with context", t, func() {
+		var oldText = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+		var newText = strings.Replace(strings.Replace(oldText, "2\n", "two\n", 1), "14\n", "", 1) + "16\n"
+
+		So(Unified("a/x", "b/x", []byte(oldText), []byte(newText)), ShouldEqual, `--- a/x
++++ b/x
+@@ -1,5 +1,5 @@
+ 1
+-2
++two
+ 3
+ 4
+ 5
+@@ -11,5 +11,5 @@
+ 11
+ 12
+ 13
+-14
+ 15
++16
+`)
+	})
+
+	Convey("Added and removed files are diffed against empty files", t, func() {
+		So(Unified(NullFileName, "b/x", nil, []byte("a\nb")), ShouldEqual, `--- /dev/null
++++ b/x
+@@ -0,0 +1,2 @@
++a
++b
+\ No newline at end of file
+`)
+
+		So(Unified("a/x", NullFileName, []byte("a\n"), nil), ShouldEqual, `--- a/x
++++ /dev/null
+@@ -1,1 +0,0 @@
+-a
+`)
+	})
+
+	Convey("Binary files are only reported as different", t, func() {
+		So(Unified("a/x", "b/x", []byte{0, 1}, []byte{0, 2}), ShouldEqual, "Binary files a/x and b/x differ\n")
+	})
+
+	Convey("Large files which are completely different are diffed in linear space", t, func() {
+		const numLines = 5000
+		var oldText, newText, expected = new(strings.Builder), new(strings.Builder), new(strings.Builder)
+		fmt.Fprintf(expected, "--- a/x\n+++ b/x\n@@ -1,%d +1,%d @@\n", numLines, numLines)
+		for i := 0; i < numLines; i++ {
+			fmt.Fprintf(oldText, "old %d\n", i)
+			fmt.Fprintf(expected, "-old %d\n", i)
+		}
+		for i := 0; i < numLines; i++ {
+			fmt.Fprintf(newText, "new %d\n", i)
+			fmt.Fprintf(expected, "+new %d\n", i)
+		}
+
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		var result = Unified("a/x", "b/x", []byte(oldText.String()), []byte(newText.String()))
+		runtime.ReadMemStats(&after)
+
+		So(result, ShouldEqual, expected.String())
+
+		// Keeping the furthest points on every diagonal for every step would need hundreds of megabytes
+		So(after.TotalAlloc-before.TotalAlloc, ShouldBeLessThan, 64*1024*1024)
+	})
+}
@@ -0,0 +1,174 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/oci"
+)
+
+// dockerHubApiUrl is the location of the Docker Hub API, which is separate from the registry API.
+const dockerHubApiUrl = "https://hub.docker.com"
+
+// hubHttpClient is used to call the Docker Hub API.
+var hubHttpClient = &http.Client{Timeout: 30 * time.Second}
+
+type hubRepositoriesResponse struct {
+	NextPage string `json:"next"`
+	Results  []struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"results"`
+}
+
+type hubLoginResponse struct {
+	Token string `json:"token"`
+}
+
+// GetRepositories gets the names of the Docker repositories in a remote registry, optionally limited to a namespace.
+// If a password is provided, the username and password are used to authenticate with the registry.
+func GetRepositories(
+	ch chan<- string,
+	dockerRegistry string,
+	namespace string,
+	username string,
+	password string,
+) (err error) {
+	if dockerRegistry == DefaultDockerRegistry {
+		// Docker Hub doesn't implement the catalog API, but it can list the repositories in a namespace.
+		if namespace == "" {
+			return fmt.Errorf("a namespace is required to list repositories on Docker Hub")
+		}
+
+		err = getRepositoriesFromDockerHubApi(ch, dockerHubApiUrl, namespace, username, password)
+		if err != nil {
+			return fmt.Errorf("failed to get repositories from Docker Hub API: %s", err)
+		}
+
+		return nil
+	}
+
+	var client *oci.Client
+	client, err = NewRegistryClient(dockerRegistry, username, password)
+	if err != nil {
+		return err
+	}
+
+	err = getRepositoriesFromCatalogApi(ch, client, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to get repositories from registry catalog API: %s", err)
+	}
+
+	return nil
+}
+
+func getRepositoriesFromDockerHubApi(ch chan<- string, baseUrl string, namespace string, username string, password string) (err error) {
+	// Private repositories are only listed for logged in users
+	var authorization string
+	if password != "" {
+		var token string
+		token, err = loginToDockerHubApi(baseUrl, username, password)
+		if err != nil {
+			return err
+		}
+		authorization = "Bearer " + token
+	}
+
+	// Construct the initial URL
+	var requestUrl = fmt.Sprintf("%s/v2/repositories/%s/?page_size=100", baseUrl, namespace)
+
+	for requestUrl != "" {
+		// Make the HTTP request
+		var response = hubRepositoriesResponse{}
+		err = callDockerHubApi(http.MethodGet, requestUrl, authorization, nil, &response)
+		if err != nil {
+			return err
+		}
+
+		// Extract the repository names
+		for _, obj := range response.Results {
+			ch <- fmt.Sprintf("%s/%s", obj.Namespace, obj.Name)
+		}
+
+		// Set the next URL
+		requestUrl = response.NextPage
+	}
+
+	return nil
+}
+
+// loginToDockerHubApi gets a token for the Docker Hub API.
+func loginToDockerHubApi(baseUrl string, username string, password string) (string, error) {
+	var requestBody, err = json.Marshal(map[string]string{"username": username, "password": password})
+	if err != nil {
+		log.Panicf("failed to serialize Docker Hub login request: %s", err)
+	}
+
+	var response = hubLoginResponse{}
+	err = callDockerHubApi(http.MethodPost, baseUrl+"/v2/users/login", "", requestBody, &response)
+	if err != nil {
+		return "", fmt.Errorf("failed to log in to Docker Hub as '%s': %s", username, err)
+	}
+
+	return response.Token, nil
+}
+
+func getRepositoriesFromCatalogApi(ch chan<- string, client *oci.Client, namespace string) error {
+	var repositoriesCh = make(chan string)
+	var errCh = make(chan error, 1)
+	go func() {
+		defer close(repositoriesCh)
+		errCh <- client.ListRepositories(repositoriesCh)
+	}()
+
+	for repositoryName := range repositoriesCh {
+		if namespace != "" && !strings.HasPrefix(repositoryName, namespace+"/") {
+			continue
+		}
+
+		ch <- repositoryName
+	}
+
+	return <-errCh
+}
+
+func callDockerHubApi(method string, requestUrl string, authorization string, requestBody []byte, result any) error {
+	var httpRequest, err = http.NewRequest(method, requestUrl, bytes.NewReader(requestBody))
+	if err != nil {
+		return err
+	}
+	if requestBody != nil {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+	if authorization != "" {
+		httpRequest.Header.Set("Authorization", authorization)
+	}
+
+	log.Debugf("%s %s", method, requestUrl)
+	httpResponse, err := hubHttpClient.Do(httpRequest)
+	if err != nil {
+		return fmt.Errorf("failed to call the Docker Hub API: %s", err)
+	}
+	defer func() {
+		err := httpResponse.Body.Close()
+		if err != nil {
+			log.Errorf("failed to close response stream: %s", err)
+		}
+	}()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from the Docker Hub API: %s", httpResponse.Status)
+	}
+
+	err = json.NewDecoder(httpResponse.Body).Decode(result)
+	if err != nil {
+		return fmt.Errorf("failed to parse the HTTP response from the Docker Hub API: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/oci/ocitest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// collect runs a function which writes to a channel, and returns everything that it wrote.
+func collect(fn func(ch chan<- string) error) ([]string, error) {
+	var ch = make(chan string, 1)
+
+	var results []string
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for result := range ch {
+			results = append(results, result)
+		}
+	}()
+
+	var err = fn(ch)
+	close(ch)
+	wg.Wait()
+
+	return results, err
+}
+
+func TestGetRepositories(t *testing.T) {
+	Convey("Given a registry which requires credentials", t, func() {
+		var registry = ocitest.NewRegistryWithTokenAuth("user", "secret")
+		defer registry.Close()
+
+		// Add enough repositories that the results are split over multiple pages.
+		var expectedRepositories []string
+		for i := 0; i < 150; i++ {
+			var repository = fmt.Sprintf("team/package%03d", i)
+			registry.PutTag(repository, "1.0.0")
+			expectedRepositories = append(expectedRepositories, repository)
+		}
+		registry.PutTag("other/package", "1.0.0")
+
+		Convey("Repositories on all pages in the namespace are returned", func() {
+			var repositories, err = collect(func(ch chan<- string) error {
+				return GetRepositories(ch, registry.Url(), "team", "user", "secret")
+			})
+			So(err, ShouldBeNil)
+			sort.Strings(repositories)
+			So(repositories, ShouldResemble, expectedRepositories)
+		})
+
+		Convey("Tags are listed with the credentials", func() {
+			var tags, err = collect(func(ch chan<- string) error {
+				return GetImageTags(ch, "team/package000", registry.Url(), "user", "secret")
+			})
+			So(err, ShouldBeNil)
+			So(tags, ShouldResemble, []string{"1.0.0"})
+		})
+
+		Convey("Invalid credentials return an error", func() {
+			var _, err = collect(func(ch chan<- string) error {
+				return GetRepositories(ch, registry.Url(), "team", "user", "wrong")
+			})
+			So(err, ShouldNotBeNil)
+
+			_, err = collect(func(ch chan<- string) error {
+				return GetImageTags(ch, "team/package000", registry.Url(), "user", "wrong")
+			})
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("Given the Docker Hub API", t, func() {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/v2/users/login":
+				var credentials map[string]string
+				var err = json.NewDecoder(r.Body).Decode(&credentials)
+				if err != nil || credentials["username"] != "user" || credentials["password"] != "secret" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_ = json.NewEncoder(w).Encode(map[string]string{"token": "hub-token"})
+
+			case "/v2/repositories/team/":
+				// Private repositories are only listed for logged in users, and results are split over two pages
+				var results = []map[string]string{{"namespace": "team", "name": "public"}}
+				var next = ""
+				if r.URL.Query().Get("page") == "" {
+					next = server.URL + "/v2/repositories/team/?page=2"
+				} else {
+					results = []map[string]string{{"namespace": "team", "name": "second"}}
+					if r.Header.Get("Authorization") == "Bearer hub-token" {
+						results = append(results, map[string]string{"namespace": "team", "name": "private"})
+					}
+				}
+				_ = json.NewEncoder(w).Encode(map[string]any{"next": next, "results": results})
+
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		Convey("Repositories on all pages are returned", func() {
+			var repositories, err = collect(func(ch chan<- string) error {
+				return getRepositoriesFromDockerHubApi(ch, server.URL, "team", "", "")
+			})
+			So(err, ShouldBeNil)
+			So(repositories, ShouldResemble, []string{"team/public", "team/second"})
+		})
+
+		Convey("Private repositories are returned after logging in", func() {
+			var repositories, err = collect(func(ch chan<- string) error {
+				return getRepositoriesFromDockerHubApi(ch, server.URL, "team", "user", "secret")
+			})
+			So(err, ShouldBeNil)
+			So(repositories, ShouldResemble, []string{"team/public", "team/second", "team/private"})
+		})
+
+		Convey("Invalid credentials return an error", func() {
+			var _, err = collect(func(ch chan<- string) error {
+				return getRepositoriesFromDockerHubApi(ch, server.URL, "team", "user", "wrong")
+			})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "failed to log in to Docker Hub as 'user'")
+		})
+	})
+}
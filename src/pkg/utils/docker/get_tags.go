@@ -1,95 +1,78 @@
 package docker
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 
-	"github.com/rohitramu/kpm/src/pkg/utils/log"
-	"github.com/rohitramu/kpm/src/pkg/utils/validation"
+	"github.com/rohitramu/kpm/src/pkg/utils/oci"
 )
 
-type tagsResponse struct {
-	NextPage string `json:"next"`
-	Results  []struct {
-		TagName     string `json:"name"`
-		ContentType string `json:"content_type"`
-		TagStatus   string `json:"tag_status"`
-	} `json:"results"`
-}
+// dockerHubApiHost is the host which serves the registry API for Docker Hub (i.e. "docker.io").
+const dockerHubApiHost = "registry-1.docker.io"
 
-// GetImageTags gets the list of tags on a Docker repository in a remote registry.
+// GetImageTags gets the list of tags on a Docker repository in a remote registry.  Tags are listed using the
+// registry's "/v2/<name>/tags/list" API, so this works with any registry which implements the Distribution API.
+// If a password is provided, the username and password are used to authenticate with the registry.
 func GetImageTags(
 	ch chan<- string,
 	imageName string,
 	dockerRegistry string,
+	username string,
+	password string,
 ) (err error) {
-	// Validate package name.
-	err = validation.ValidatePackageName(imageName)
+	// Validate the repository name.
+	err = oci.ValidateRepositoryName(imageName)
 	if err != nil {
 		return err
 	}
 
-	// Parse and validate the repository name, and get the namespace and image name.
-	dockerRepository := imageName
-	if !strings.Contains(dockerRepository, "/") {
-		// For first-party images, we need to set the namespace to "library".
-		dockerRepository = "library/" + dockerRepository
+	var client *oci.Client
+	client, err = NewRegistryClient(dockerRegistry, username, password)
+	if err != nil {
+		return err
 	}
 
-	// Get image name (combining package name with docker registry)
-	GetImageNameWithoutTag(dockerRegistry, imageName)
-
 	// Make the API call and get the results.
-	err = getTagsFromDockerRegistryV2Api(ch, "hub.docker.com", dockerRepository)
+	var dockerRepository = GetRegistryRepositoryName(dockerRegistry, imageName)
+	err = client.ListTags(ch, dockerRepository)
 	if err != nil {
-		return fmt.Errorf("failed to get tags from Docker V2 API: %s", err)
+		if errors.Is(err, oci.ErrNotFound) {
+			return errors.Join(ErrImageNotFound, err)
+		}
+
+		return fmt.Errorf("failed to get tags of '%s' from registry '%s': %s", dockerRepository, dockerRegistry, err)
 	}
 
-	// Channel will be garbage collected when it drops out of scope.
 	return nil
 }
 
-func getTagsFromDockerRegistryV2Api(ch chan<- string, baseUrl string, dockerRepository string) error {
-	// Construct the initial URL
-	requestUrl := fmt.Sprintf("https://%s/v2/repositories/%s/tags?ordering=-name", baseUrl, dockerRepository)
+// NewRegistryClient creates a client for the registry API of the given registry.  The username is only used if a
+// password is provided, since a username on its own is usually just the namespace of the images, and public images are
+// accessed anonymously.
+func NewRegistryClient(dockerRegistry string, username string, password string) (*oci.Client, error) {
+	if password == "" {
+		username = ""
+	}
 
-	for requestUrl != "" {
-		// Make the HTTP request
-		httpResponse, err := http.Get(requestUrl)
-		if err != nil {
-			return fmt.Errorf("failed to call the Docker registry: %s", err)
-		}
-		defer func() {
-			err := httpResponse.Body.Close()
-			if err != nil {
-				log.Errorf("failed to close response stream: %s", err)
-			}
-		}()
+	return oci.NewClient(GetRegistryApiHost(dockerRegistry), username, password)
+}
 
-		// Parse the response
-		jsonDecoder := json.NewDecoder(httpResponse.Body)
-		response := tagsResponse{}
-		err = jsonDecoder.Decode(&response)
-		if err != nil {
-			return fmt.Errorf("failed to parse the HTTP response from the Docker registry: %s", err)
-		}
+// GetRegistryApiHost returns the host which serves the registry API for the given registry.
+func GetRegistryApiHost(dockerRegistry string) string {
+	if dockerRegistry == DefaultDockerRegistry {
+		return dockerHubApiHost
+	}
 
-		// Extract the tags
-		for _, obj := range response.Results {
-			if obj.ContentType == "image" {
-				if obj.TagStatus == "active" {
-					ch <- obj.TagName
-				} else {
-					log.Debugf("Inactive tag: %s:%s", dockerRepository, obj.TagName)
-				}
-			}
-		}
+	return dockerRegistry
+}
 
-		// Set the next URL
-		requestUrl = response.NextPage
+// GetRegistryRepositoryName returns the name which the registry API uses for a Docker repository.
+func GetRegistryRepositoryName(dockerRegistry string, imageName string) string {
+	// For first-party images on Docker Hub, we need to set the namespace to "library".
+	if dockerRegistry == DefaultDockerRegistry && !strings.Contains(imageName, "/") {
+		return "library/" + imageName
 	}
 
-	return nil
+	return imageName
 }
@@ -1,36 +1,60 @@
 package docker
 
 import (
-	"sync"
+	"fmt"
+	"sort"
 	"testing"
 
-	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/oci/ocitest"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
 func TestGetVersions(t *testing.T) {
 	Convey("Get tags", t, func() {
+		// Anonymous token auth is how public images are accessed on Docker Hub.
+		var registry = ocitest.NewRegistryWithTokenAuth("", "")
+		defer registry.Close()
+
+		// Add enough tags that the results are split over multiple pages.
 		var packageName = "kpmtool/example"
-		var dockerRegistry = DefaultDockerRegistry
-
-		var ch = make(chan string, 1)
-
-		var tags []string
-		var wg sync.WaitGroup
-		go func() {
-			defer wg.Done()
-			wg.Add(1)
-			for tag := range ch {
-				tags = append(tags, tag)
-			}
-		}()
-
-		log.SetLevel(log.LevelDebug)
-
-		var err = GetImageTags(ch, packageName, dockerRegistry)
-		close(ch)
-		wg.Wait()
-		So(err, ShouldBeNil)
-		So(tags, ShouldContain, "1.0.0")
+		var expectedTags []string
+		for i := 0; i < 250; i++ {
+			var tag = fmt.Sprintf("1.0.%d", i)
+			registry.PutTag(packageName, tag)
+			expectedTags = append(expectedTags, tag)
+		}
+		sort.Strings(expectedTags)
+
+		var getTags = func(imageName string) ([]string, error) {
+			return collect(func(ch chan<- string) error {
+				return GetImageTags(ch, imageName, registry.Url(), "", "")
+			})
+		}
+
+		Convey("Tags on all pages are returned", func() {
+			var tags, err = getTags(packageName)
+			So(err, ShouldBeNil)
+			So(tags, ShouldResemble, expectedTags)
+		})
+
+		Convey("Missing repositories return an error", func() {
+			var _, err = getTags("kpmtool/missing")
+			So(err, ShouldWrap, ErrImageNotFound)
+		})
+
+		Convey("Invalid repository names return an error", func() {
+			var _, err = getTags("KpmTool/Example")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestGetRegistryNames(t *testing.T) {
+	Convey("Docker Hub names are mapped to registry API names", t, func() {
+		So(GetRegistryRepositoryName(DefaultDockerRegistry, "ubuntu"), ShouldEqual, "library/ubuntu")
+		So(GetRegistryRepositoryName(DefaultDockerRegistry, "kpmtool/example"), ShouldEqual, "kpmtool/example")
+		So(GetRegistryRepositoryName("ghcr.io", "ubuntu"), ShouldEqual, "ubuntu")
+		So(GetRegistryApiHost(DefaultDockerRegistry), ShouldEqual, "registry-1.docker.io")
+		So(GetRegistryApiHost("ghcr.io"), ShouldEqual, "ghcr.io")
 	})
 }
@@ -1,7 +1,9 @@
 package docker
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/rohitramu/kpm/src/pkg/utils/exec"
 	"github.com/rohitramu/kpm/src/pkg/utils/log"
@@ -15,9 +17,16 @@ func PullImage(imageName string) error {
 
 	var exe = "docker"
 	var args = []string{"pull", imageName}
-	_, err = exec.Exec(exe, args...)
+	var output string
+	output, err = exec.Exec(exe, args...)
 	if err != nil {
-		return fmt.Errorf("failed to pull image: %s\n%s", imageName, err)
+		// Let callers distinguish between images that don't exist and other failures.
+		var lowercaseOutput = strings.ToLower(output)
+		if strings.Contains(lowercaseOutput, "manifest unknown") || strings.Contains(lowercaseOutput, "not found") {
+			err = errors.Join(ErrImageNotFound, err)
+		}
+
+		return fmt.Errorf("failed to pull image: %s\n%w", imageName, err)
 	}
 
 	return nil
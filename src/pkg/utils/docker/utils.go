@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -15,6 +16,9 @@ const DefaultDockerRegistry = "docker.io"
 // DockerfileRootDir is the root directory to use when building or copying from a Docker image.
 const DockerfileRootDir = ".kpm"
 
+// ErrImageNotFound is returned when an image or tag doesn't exist in the registry.
+var ErrImageNotFound = errors.New("image not found")
+
 func GetImageNameWithoutTag(dockerRegistry string, packageName string) string {
 	imageName := packageName
 	if dockerRegistry != DefaultDockerRegistry {
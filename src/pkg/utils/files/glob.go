@@ -0,0 +1,101 @@
+package files
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// globAnyDirs is the glob path segment which matches zero or more path segments.
+const globAnyDirs = "**"
+
+// ValidateGlob checks that a glob pattern is a valid relative path pattern, as used by MatchGlob.
+func ValidateGlob(pattern string) error {
+	if pattern == "" {
+		return fmt.Errorf("glob pattern cannot be empty")
+	}
+
+	if path.IsAbs(pattern) || strings.Contains(pattern, "\\") {
+		return fmt.Errorf("glob pattern must be a relative path which uses forward slashes: %s", pattern)
+	}
+
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == ".." {
+			return fmt.Errorf("glob pattern cannot refer to parent directories: %s", pattern)
+		}
+
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid glob pattern '%s': %s", pattern, err)
+		}
+	}
+
+	return nil
+}
+
+// MatchGlob returns true if a relative path matches a glob pattern.  The path and pattern must use forward slashes.  In
+// addition to the syntax supported by "path.Match", a "**" path segment matches zero or more path segments (e.g.
+// "dashboards/**/*.json").
+func MatchGlob(pattern string, relativePath string) (bool, error) {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(relativePath, "/"))
+}
+
+// MatchAnyGlob returns true if a relative path matches any of the given glob patterns.
+func MatchAnyGlob(patterns []string, relativePath string) (bool, error) {
+	for _, pattern := range patterns {
+		var isMatch, err = MatchGlob(pattern, relativePath)
+		if err != nil || isMatch {
+			return isMatch, err
+		}
+	}
+
+	return false, nil
+}
+
+func matchGlobSegments(patternSegments []string, pathSegments []string) (bool, error) {
+	for len(patternSegments) > 0 {
+		if patternSegments[0] == globAnyDirs {
+			// Try to match the rest of the pattern after skipping any number of path segments
+			for i := 0; i <= len(pathSegments); i++ {
+				var isMatch, err = matchGlobSegments(patternSegments[1:], pathSegments[i:])
+				if err != nil || isMatch {
+					return isMatch, err
+				}
+			}
+
+			return false, nil
+		}
+
+		if len(pathSegments) == 0 {
+			return false, nil
+		}
+
+		var isMatch, err = path.Match(patternSegments[0], pathSegments[0])
+		if err != nil || !isMatch {
+			return false, err
+		}
+
+		patternSegments = patternSegments[1:]
+		pathSegments = pathSegments[1:]
+	}
+
+	return len(pathSegments) == 0, nil
+}
+
+// CleanRelativePath cleans a relative path which uses forward slashes, and makes sure that it doesn't refer to anything
+// outside the directory that it is relative to.
+func CleanRelativePath(relativePath string) (string, error) {
+	if relativePath == "" {
+		return "", fmt.Errorf("path cannot be empty")
+	}
+
+	if path.IsAbs(relativePath) || strings.Contains(relativePath, "\\") {
+		return "", fmt.Errorf("path must be a relative path which uses forward slashes: %s", relativePath)
+	}
+
+	var result = path.Clean(relativePath)
+	if result == "." || result == ".." || strings.HasPrefix(result, "../") {
+		return "", fmt.Errorf("path must be inside the directory: %s", relativePath)
+	}
+
+	return result, nil
+}
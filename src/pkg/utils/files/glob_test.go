@@ -0,0 +1,56 @@
+package files
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGlob(t *testing.T) {
+	Convey("Glob patterns match relative paths", t, func() {
+		var testCases = []struct {
+			pattern string
+			path    string
+			isMatch bool
+		}{
+			{"*.json", "a.json", true},
+			{"*.json", "dashboards/a.json", false},
+			{"dashboards/*.json", "dashboards/a.json", true},
+			{"**/*.json", "a.json", true},
+			{"**/*.json", "dashboards/team/a.json", true},
+			{"dashboards/**", "dashboards/team/a.json", true},
+			{"dashboards/**", "other/a.json", false},
+			{"dashboards/**/a.json", "dashboards/a.json", true},
+			{"**", "a/b/c", true},
+			{"a?.txt", "ab.txt", true},
+			{"[ab].txt", "c.txt", false},
+		}
+
+		for _, testCase := range testCases {
+			var isMatch, err = MatchGlob(testCase.pattern, testCase.path)
+			So(err, ShouldBeNil)
+			So(isMatch, ShouldEqual, testCase.isMatch)
+		}
+	})
+
+	Convey("Invalid glob patterns are rejected", t, func() {
+		for _, pattern := range []string{"", "/abs/*", "../*", "a/[b", "a\\b"} {
+			So(ValidateGlob(pattern), ShouldNotBeNil)
+		}
+
+		for _, pattern := range []string{"*", "**/*.json", "a/[bc]/d"} {
+			So(ValidateGlob(pattern), ShouldBeNil)
+		}
+	})
+
+	Convey("Relative paths must stay inside their directory", t, func() {
+		var cleanPath, err = CleanRelativePath("a/./b/../c.txt")
+		So(err, ShouldBeNil)
+		So(cleanPath, ShouldEqual, "a/c.txt")
+
+		for _, relativePath := range []string{"", ".", "..", "../a", "a/../../b", "/a", "a\\b"} {
+			_, err = CleanRelativePath(relativePath)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
@@ -0,0 +1,210 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/exec"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+)
+
+// RemoteName is the name of the remote which is used for all clones created by KPM.
+const RemoteName = "origin"
+
+// DefaultUserName is the name which commits are made with if git doesn't have a user name configured.
+const DefaultUserName = "KPM"
+
+// DefaultUserEmail is the email address which commits are made with if git doesn't have an email address configured.
+const DefaultUserEmail = "kpm@localhost"
+
+// RefKind identifies what a user-provided git ref resolved to.
+type RefKind int
+
+// RefKind enum
+const (
+	// RefKindNone indicates that the ref could not be found.
+	RefKindNone RefKind = iota
+
+	// RefKindBranch indicates that the ref is a branch on the remote.
+	RefKindBranch
+
+	// RefKindTag indicates that the ref is a tag.
+	RefKindTag
+
+	// RefKindCommit indicates that the ref is a commit hash.
+	RefKindCommit
+)
+
+// Clone clones a remote git repository into the given directory.
+func Clone(url string, destinationDir string) error {
+	log.Verbosef("Cloning git repository '%s' into: %s", url, destinationDir)
+
+	var _, err = run("", "clone", "--origin", RemoteName, url, destinationDir)
+	if err != nil {
+		return fmt.Errorf("failed to clone git repository: %s\n%s", url, err)
+	}
+
+	return nil
+}
+
+// GetRemoteUrl returns the URL of the remote in a local clone.
+func GetRemoteUrl(repoDir string) (string, error) {
+	var output, err = run(repoDir, "remote", "get-url", RemoteName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote URL of git repository: %s\n%s", repoDir, err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// Fetch updates all branches and tags from the remote.
+func Fetch(repoDir string) error {
+	log.Verbosef("Fetching changes in git repository: %s", repoDir)
+
+	var _, err = run(repoDir, "fetch", "--prune", "--tags", "--force", RemoteName)
+	if err != nil {
+		return fmt.Errorf("failed to fetch changes in git repository: %s\n%s", repoDir, err)
+	}
+
+	return nil
+}
+
+// GetDefaultBranch returns the name of the remote's default branch, or an empty string if the remote has no branches.
+func GetDefaultBranch(repoDir string) string {
+	var output, err = run(repoDir, "symbolic-ref", "--quiet", "--short", fmt.Sprintf("refs/remotes/%s/HEAD", RemoteName))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.TrimSpace(output), RemoteName+"/")
+}
+
+// ResolveRef determines whether the given ref is a remote branch, a tag or a commit.
+func ResolveRef(repoDir string, ref string) RefKind {
+	if refExists(repoDir, fmt.Sprintf("refs/remotes/%s/%s", RemoteName, ref)) {
+		return RefKindBranch
+	}
+
+	if refExists(repoDir, fmt.Sprintf("refs/tags/%s", ref)) {
+		return RefKindTag
+	}
+
+	if refExists(repoDir, ref+"^{commit}") {
+		return RefKindCommit
+	}
+
+	return RefKindNone
+}
+
+// CheckoutBranch resets the working tree to the latest commit of a remote branch.
+func CheckoutBranch(repoDir string, branch string) error {
+	var _, err = run(repoDir, "checkout", "--force", "-B", branch, fmt.Sprintf("%s/%s", RemoteName, branch))
+	if err != nil {
+		return fmt.Errorf("failed to check out branch '%s' in git repository: %s\n%s", branch, repoDir, err)
+	}
+
+	return clean(repoDir)
+}
+
+// CheckoutDetached resets the working tree to the given tag or commit.
+func CheckoutDetached(repoDir string, ref string) error {
+	var _, err = run(repoDir, "checkout", "--force", "--detach", ref)
+	if err != nil {
+		return fmt.Errorf("failed to check out '%s' in git repository: %s\n%s", ref, repoDir, err)
+	}
+
+	return clean(repoDir)
+}
+
+// CheckoutOrphan starts a new branch with no history, which is required when the remote is empty.
+func CheckoutOrphan(repoDir string, branch string) error {
+	var _, err = run(repoDir, "checkout", "--force", "--orphan", branch)
+	if err != nil {
+		return fmt.Errorf("failed to create branch '%s' in git repository: %s\n%s", branch, repoDir, err)
+	}
+
+	return nil
+}
+
+// CommitAll stages all changes under the given path and commits them.  The commit is made with the user's git identity
+// if one is configured, otherwise the missing parts of the identity are filled in with "DefaultUserName" and
+// "DefaultUserEmail", so that pushing packages doesn't require any git configuration (e.g. on build agents).
+func CommitAll(repoDir string, pathSpec string, message string) error {
+	var _, err = run(repoDir, "add", "--all", "--", pathSpec)
+	if err != nil {
+		return fmt.Errorf("failed to stage changes in git repository: %s\n%s", repoDir, err)
+	}
+
+	var args = append(getDefaultIdentityArgs(repoDir), "commit", "--message", message)
+	_, err = run(repoDir, args...)
+	if err != nil {
+		return fmt.Errorf("failed to commit changes in git repository: %s\n%s", repoDir, err)
+	}
+
+	return nil
+}
+
+// PushBranch pushes the current commit to a branch on the remote.
+func PushBranch(repoDir string, branch string) error {
+	log.Infof("Pushing branch '%s' from git repository: %s", branch, repoDir)
+
+	var _, err = run(repoDir, "push", RemoteName, fmt.Sprintf("HEAD:refs/heads/%s", branch))
+	if err != nil {
+		return fmt.Errorf("failed to push branch '%s' from git repository: %s\n%s", branch, repoDir, err)
+	}
+
+	return nil
+}
+
+// getDefaultIdentityArgs returns the arguments which set the parts of the commit identity that git can't determine.
+func getDefaultIdentityArgs(repoDir string) []string {
+	var _, authorErr = run(repoDir, "var", "GIT_AUTHOR_IDENT")
+	var _, committerErr = run(repoDir, "var", "GIT_COMMITTER_IDENT")
+	if authorErr == nil && committerErr == nil {
+		return nil
+	}
+
+	log.Verbosef("No git identity is configured, so commits will be made as '%s <%s>'", DefaultUserName, DefaultUserEmail)
+
+	var args []string
+	for _, setting := range []struct{ key, value string }{
+		{"user.name", DefaultUserName},
+		{"user.email", DefaultUserEmail},
+	} {
+		if output, err := run(repoDir, "config", "--get", setting.key); err != nil || strings.TrimSpace(output) == "" {
+			args = append(args, "-c", fmt.Sprintf("%s=%s", setting.key, setting.value))
+		}
+	}
+
+	return args
+}
+
+func clean(repoDir string) error {
+	var _, err = run(repoDir, "clean", "--force", "-d", "-x")
+	if err != nil {
+		return fmt.Errorf("failed to clean git repository: %s\n%s", repoDir, err)
+	}
+
+	return nil
+}
+
+func refExists(repoDir string, ref string) bool {
+	var _, err = run(repoDir, "rev-parse", "--verify", "--quiet", ref)
+
+	return err == nil
+}
+
+func run(repoDir string, args ...string) (string, error) {
+	const exe = "git"
+
+	if repoDir != "" {
+		args = append([]string{"-C", repoDir}, args...)
+	}
+
+	var output, err = exec.Exec(exe, args...)
+	if err != nil {
+		return output, fmt.Errorf("%s\n%s", err, strings.TrimSpace(output))
+	}
+
+	return output, nil
+}
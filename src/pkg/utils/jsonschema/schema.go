@@ -0,0 +1,498 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is a parsed JSON Schema, which can be used to validate values.
+//
+// The validation keywords from JSON Schema draft 2020-12 are supported, except for "format" (which is treated as an
+// annotation).  The keywords from draft-07 which were renamed ("definitions", "dependencies" and "items" as an array)
+// are also supported.  References ("$ref") must point to a location in the same schema (e.g. "#/$defs/port").
+// Annotations (see "annotationKeywords") are ignored, and any other keyword is rejected when the schema is parsed, so
+// that a schema is never silently checked less strictly than its author intended (e.g. with "unevaluatedProperties").
+type Schema struct {
+	root *schemaNode
+}
+
+// schemaNode is a parsed schema or sub-schema.
+type schemaNode struct {
+	// Boolean schemas either match everything or nothing.
+	isBoolean    bool
+	booleanValue bool
+
+	// References to other schemas are resolved when the schema is parsed.
+	ref *schemaNode
+
+	// Any values.
+	types    []string
+	enum     []any
+	constant *any
+
+	// Numbers.
+	minimum          *float64
+	maximum          *float64
+	exclusiveMinimum *float64
+	exclusiveMaximum *float64
+	multipleOf       *float64
+
+	// Strings.
+	minLength *int
+	maxLength *int
+	pattern   *regexp.Regexp
+
+	// Arrays.
+	prefixItems []*schemaNode
+	items       *schemaNode
+	contains    *schemaNode
+	minItems    *int
+	maxItems    *int
+	uniqueItems bool
+
+	// Objects.
+	properties           map[string]*schemaNode
+	patternProperties    []*patternSchema
+	additionalProperties *schemaNode
+	propertyNames        *schemaNode
+	required             []string
+	dependentRequired    map[string][]string
+	dependentSchemas     map[string]*schemaNode
+	minProperties        *int
+	maxProperties        *int
+
+	// Combining schemas.
+	allOf      []*schemaNode
+	anyOf      []*schemaNode
+	oneOf      []*schemaNode
+	not        *schemaNode
+	ifSchema   *schemaNode
+	thenSchema *schemaNode
+	elseSchema *schemaNode
+}
+
+// patternSchema is a schema which applies to the properties whose names match a pattern.
+type patternSchema struct {
+	pattern *regexp.Regexp
+	schema  *schemaNode
+}
+
+// annotationKeywords is the set of keywords which don't affect validation.
+var annotationKeywords = map[string]bool{
+	"$schema":          true,
+	"$comment":         true,
+	"title":            true,
+	"description":      true,
+	"default":          true,
+	"examples":         true,
+	"deprecated":       true,
+	"readOnly":         true,
+	"writeOnly":        true,
+	"format":           true,
+	"contentEncoding":  true,
+	"contentMediaType": true,
+	"contentSchema":    true,
+}
+
+// typeNames is the set of types which may be used in the "type" keyword.
+var typeNames = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+	"number":  true,
+	"integer": true,
+	"string":  true,
+}
+
+// parser parses a schema document, keeping track of the sub-schemas which have been parsed so references to them
+// (including recursive references) resolve to the same node.
+type parser struct {
+	document any
+	nodes    map[string]*schemaNode
+}
+
+// Parse parses a JSON Schema document.
+func Parse(schemaBytes []byte) (*Schema, error) {
+	var document any
+	if err := json.Unmarshal(schemaBytes, &document); err != nil {
+		return nil, fmt.Errorf("schema is not valid JSON: %s", err)
+	}
+
+	var p = &parser{document: document, nodes: map[string]*schemaNode{}}
+	var root, err = p.parseNode(document, "#")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schema{root: root}, nil
+}
+
+// parseNode parses the schema at the given JSON pointer (as a URI fragment) in the document.
+func (p *parser) parseNode(rawSchema any, pointer string) (result *schemaNode, err error) {
+	if existingNode, found := p.nodes[pointer]; found {
+		return existingNode, nil
+	}
+
+	result = &schemaNode{}
+	p.nodes[pointer] = result
+
+	if booleanValue, isBoolean := rawSchema.(bool); isBoolean {
+		result.isBoolean = true
+		result.booleanValue = booleanValue
+		return result, nil
+	}
+
+	var keywords, isObject = rawSchema.(map[string]any)
+	if !isObject {
+		return nil, fmt.Errorf("%s: a schema must be an object or a boolean", pointer)
+	}
+
+	// Parse keywords in a stable order, so the same error is always reported for invalid schemas
+	var keys = make([]string, 0, len(keywords))
+	for key := range keywords {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err = p.parseKeyword(result, key, keywords[key], pointer+"/"+escapePointerToken(key)); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// parseKeyword parses a single keyword in a schema object.
+func (p *parser) parseKeyword(node *schemaNode, keyword string, value any, pointer string) (err error) {
+	switch keyword {
+	case "$id":
+		// Identifiers change how references inside the schema are resolved, so they are only allowed on the root schema
+		if pointer != "#/$id" {
+			return fmt.Errorf("%s: \"$id\" is only supported in the root schema", pointer)
+		}
+
+	case "$ref":
+		var ref, isString = value.(string)
+		if !isString {
+			return fmt.Errorf("%s: must be a string", pointer)
+		}
+		node.ref, err = p.resolveRef(ref, pointer)
+
+	case "type":
+		node.types, err = parseTypes(value, pointer)
+	case "enum":
+		var isArray bool
+		if node.enum, isArray = value.([]any); !isArray {
+			return fmt.Errorf("%s: must be an array", pointer)
+		}
+	case "const":
+		node.constant = &value
+
+	case "minimum":
+		node.minimum, err = parseNumber(value, pointer)
+	case "maximum":
+		node.maximum, err = parseNumber(value, pointer)
+	case "exclusiveMinimum":
+		node.exclusiveMinimum, err = parseNumber(value, pointer)
+	case "exclusiveMaximum":
+		node.exclusiveMaximum, err = parseNumber(value, pointer)
+	case "multipleOf":
+		if node.multipleOf, err = parseNumber(value, pointer); err == nil && *node.multipleOf <= 0 {
+			return fmt.Errorf("%s: must be greater than 0", pointer)
+		}
+
+	case "minLength":
+		node.minLength, err = parseCount(value, pointer)
+	case "maxLength":
+		node.maxLength, err = parseCount(value, pointer)
+	case "pattern":
+		node.pattern, err = parsePattern(value, pointer)
+
+	case "prefixItems":
+		node.prefixItems, err = p.parseNodeList(value, pointer)
+	case "items":
+		// Draft-07 uses an array of schemas in "items" for tuples
+		if _, isArray := value.([]any); isArray {
+			node.prefixItems, err = p.parseNodeList(value, pointer)
+		} else {
+			node.items, err = p.parseNode(value, pointer)
+		}
+	case "additionalItems":
+		// Draft-07 uses "additionalItems" for the items after a tuple, which is what "items" does in draft 2020-12
+		node.items, err = p.parseNode(value, pointer)
+	case "contains":
+		node.contains, err = p.parseNode(value, pointer)
+	case "minItems":
+		node.minItems, err = parseCount(value, pointer)
+	case "maxItems":
+		node.maxItems, err = parseCount(value, pointer)
+	case "uniqueItems":
+		var isBool bool
+		if node.uniqueItems, isBool = value.(bool); !isBool {
+			return fmt.Errorf("%s: must be a boolean", pointer)
+		}
+
+	case "properties":
+		node.properties, err = p.parseNodeMap(value, pointer)
+	case "patternProperties":
+		var patternNodes map[string]*schemaNode
+		if patternNodes, err = p.parseNodeMap(value, pointer); err != nil {
+			return err
+		}
+		for _, pattern := range sortedKeys(patternNodes) {
+			var compiledPattern *regexp.Regexp
+			if compiledPattern, err = parsePattern(pattern, pointer+"/"+escapePointerToken(pattern)); err != nil {
+				return err
+			}
+			node.patternProperties = append(node.patternProperties, &patternSchema{pattern: compiledPattern, schema: patternNodes[pattern]})
+		}
+	case "additionalProperties":
+		node.additionalProperties, err = p.parseNode(value, pointer)
+	case "propertyNames":
+		node.propertyNames, err = p.parseNode(value, pointer)
+	case "required":
+		node.required, err = parseStringList(value, pointer)
+	case "dependentRequired":
+		node.dependentRequired, err = parseStringListMap(value, pointer)
+	case "dependentSchemas":
+		node.dependentSchemas, err = p.parseNodeMap(value, pointer)
+	case "dependencies":
+		// Draft-07 combines "dependentRequired" and "dependentSchemas"
+		var dependencies, isObject = value.(map[string]any)
+		if !isObject {
+			return fmt.Errorf("%s: must be an object", pointer)
+		}
+		for _, propertyName := range sortedKeys(dependencies) {
+			var dependencyPointer = pointer + "/" + escapePointerToken(propertyName)
+			if _, isArray := dependencies[propertyName].([]any); isArray {
+				if node.dependentRequired == nil {
+					node.dependentRequired = map[string][]string{}
+				}
+				if node.dependentRequired[propertyName], err = parseStringList(dependencies[propertyName], dependencyPointer); err != nil {
+					return err
+				}
+			} else {
+				if node.dependentSchemas == nil {
+					node.dependentSchemas = map[string]*schemaNode{}
+				}
+				if node.dependentSchemas[propertyName], err = p.parseNode(dependencies[propertyName], dependencyPointer); err != nil {
+					return err
+				}
+			}
+		}
+	case "minProperties":
+		node.minProperties, err = parseCount(value, pointer)
+	case "maxProperties":
+		node.maxProperties, err = parseCount(value, pointer)
+
+	case "allOf":
+		node.allOf, err = p.parseNodeList(value, pointer)
+	case "anyOf":
+		node.anyOf, err = p.parseNodeList(value, pointer)
+	case "oneOf":
+		node.oneOf, err = p.parseNodeList(value, pointer)
+	case "not":
+		node.not, err = p.parseNode(value, pointer)
+	case "if":
+		node.ifSchema, err = p.parseNode(value, pointer)
+	case "then":
+		node.thenSchema, err = p.parseNode(value, pointer)
+	case "else":
+		node.elseSchema, err = p.parseNode(value, pointer)
+
+	case "$defs", "definitions":
+		// Definitions are only parsed when they are referenced, but they must be schemas
+		var definitions, isObject = value.(map[string]any)
+		if !isObject {
+			return fmt.Errorf("%s: must be an object", pointer)
+		}
+		for _, name := range sortedKeys(definitions) {
+			if _, err = p.parseNode(definitions[name], pointer+"/"+escapePointerToken(name)); err != nil {
+				return err
+			}
+		}
+
+	default:
+		if !annotationKeywords[keyword] {
+			return fmt.Errorf("%s: unsupported keyword: %s", pointer, keyword)
+		}
+	}
+
+	return err
+}
+
+// resolveRef parses the schema which a reference points to.
+func (p *parser) resolveRef(ref string, pointer string) (*schemaNode, error) {
+	if ref != "#" && !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("%s: only references to locations in the same schema are supported (e.g. \"#/$defs/name\"): %s", pointer, ref)
+	}
+
+	// Find the referenced schema by following the JSON pointer
+	var target = p.document
+	if ref != "#" {
+		for _, token := range strings.Split(ref[2:], "/") {
+			token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+
+			var found bool
+			switch container := target.(type) {
+			case map[string]any:
+				target, found = container[token]
+			case []any:
+				var index, err = strconv.Atoi(token)
+				if found = err == nil && index >= 0 && index < len(container); found {
+					target = container[index]
+				}
+			}
+
+			if !found {
+				return nil, fmt.Errorf("%s: reference points to a location which doesn't exist: %s", pointer, ref)
+			}
+		}
+	}
+
+	return p.parseNode(target, ref)
+}
+
+// parseNodeList parses an array of schemas.
+func (p *parser) parseNodeList(value any, pointer string) (result []*schemaNode, err error) {
+	var rawSchemas, isArray = value.([]any)
+	if !isArray {
+		return nil, fmt.Errorf("%s: must be an array", pointer)
+	}
+
+	result = make([]*schemaNode, len(rawSchemas))
+	for i, rawSchema := range rawSchemas {
+		if result[i], err = p.parseNode(rawSchema, fmt.Sprintf("%s/%d", pointer, i)); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// parseNodeMap parses an object whose values are schemas.
+func (p *parser) parseNodeMap(value any, pointer string) (result map[string]*schemaNode, err error) {
+	var rawSchemas, isObject = value.(map[string]any)
+	if !isObject {
+		return nil, fmt.Errorf("%s: must be an object", pointer)
+	}
+
+	result = make(map[string]*schemaNode, len(rawSchemas))
+	for _, key := range sortedKeys(rawSchemas) {
+		if result[key], err = p.parseNode(rawSchemas[key], pointer+"/"+escapePointerToken(key)); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// parseTypes parses the value of the "type" keyword, which may be a single type or a list of types.
+func parseTypes(value any, pointer string) (result []string, err error) {
+	if typeName, isString := value.(string); isString {
+		result = []string{typeName}
+	} else if result, err = parseStringList(value, pointer); err != nil {
+		return nil, fmt.Errorf("%s: must be a string or an array of strings", pointer)
+	}
+
+	for _, typeName := range result {
+		if !typeNames[typeName] {
+			return nil, fmt.Errorf("%s: unknown type: %s", pointer, typeName)
+		}
+	}
+
+	return result, nil
+}
+
+// parseNumber parses the value of a keyword which must be a number.
+func parseNumber(value any, pointer string) (*float64, error) {
+	var number, isNumber = value.(float64)
+	if !isNumber {
+		return nil, fmt.Errorf("%s: must be a number", pointer)
+	}
+
+	return &number, nil
+}
+
+// parseCount parses the value of a keyword which must be a non-negative integer.
+func parseCount(value any, pointer string) (*int, error) {
+	var number, isNumber = value.(float64)
+	if !isNumber || number < 0 || number != float64(int(number)) {
+		return nil, fmt.Errorf("%s: must be a non-negative integer", pointer)
+	}
+
+	var result = int(number)
+	return &result, nil
+}
+
+// parsePattern parses a regular expression.  Patterns are not anchored, so they may match any part of a string.
+func parsePattern(value any, pointer string) (*regexp.Regexp, error) {
+	var pattern, isString = value.(string)
+	if !isString {
+		return nil, fmt.Errorf("%s: must be a string", pointer)
+	}
+
+	var result, err = regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid regular expression: %s", pointer, err)
+	}
+
+	return result, nil
+}
+
+// parseStringList parses an array of strings.
+func parseStringList(value any, pointer string) ([]string, error) {
+	var rawStrings, isArray = value.([]any)
+	if !isArray {
+		return nil, fmt.Errorf("%s: must be an array of strings", pointer)
+	}
+
+	var result = make([]string, len(rawStrings))
+	for i, rawString := range rawStrings {
+		var isString bool
+		if result[i], isString = rawString.(string); !isString {
+			return nil, fmt.Errorf("%s: must be an array of strings", pointer)
+		}
+	}
+
+	return result, nil
+}
+
+// parseStringListMap parses an object whose values are arrays of strings.
+func parseStringListMap(value any, pointer string) (result map[string][]string, err error) {
+	var rawLists, isObject = value.(map[string]any)
+	if !isObject {
+		return nil, fmt.Errorf("%s: must be an object", pointer)
+	}
+
+	result = make(map[string][]string, len(rawLists))
+	for key, rawList := range rawLists {
+		if result[key], err = parseStringList(rawList, pointer+"/"+escapePointerToken(key)); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// escapePointerToken escapes a property name so it can be used in a JSON pointer.
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys[T any](m map[string]T) []string {
+	var result = make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,178 @@
+package jsonschema
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParse(t *testing.T) {
+	Convey("Invalid schemas are rejected with the location of the problem", t, func() {
+		var testCases = map[string]string{
+			`not json`:         "not valid JSON",
+			`"string"`:         "must be an object or a boolean",
+			`{"type": "text"}`: "#/type: unknown type: text",
+			`{"properties": {"a": {"minLength": -1}}}`:     "#/properties/a/minLength: must be a non-negative integer",
+			`{"pattern": "["}`:                             "#/pattern: invalid regular expression",
+			`{"$ref": "other.json"}`:                       "only references to locations in the same schema are supported",
+			`{"$ref": "#/$defs/missing"}`:                  "reference points to a location which doesn't exist",
+			`{"$defs": {"a": 5}}`:                          "#/$defs/a: a schema must be an object or a boolean",
+			`{"items": [{"type": "string"}, {"type": 1}]}`: "#/items/1/type: must be a string or an array of strings",
+			`{"unevaluatedProperties": false}`:             "#/unevaluatedProperties: unsupported keyword: unevaluatedProperties",
+			`{"items": {"unevaluatedItems": false}}`:       "#/items/unevaluatedItems: unsupported keyword: unevaluatedItems",
+			`{"$dynamicRef": "#node"}`:                     "#/$dynamicRef: unsupported keyword: $dynamicRef",
+			`{"$ref": "https://example.com/port.json"}`:    "only references to locations in the same schema are supported",
+			`{"$defs": {"a": {"$id": "a.json"}}}`:          "#/$defs/a/$id: \"$id\" is only supported in the root schema",
+			`{"properties": {"a": {"maxLenght": 3}}}`:      "#/properties/a/maxLenght: unsupported keyword: maxLenght",
+		}
+
+		for schemaString, expectedError := range testCases {
+			var _, err = Parse([]byte(schemaString))
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, expectedError)
+		}
+	})
+
+	Convey("Annotations are allowed", t, func() {
+		var _, err = Parse([]byte(`{
+			"$schema": "https://json-schema.org/draft/2020-12/schema",
+			"$id": "https://example.com/parameters.json",
+			"$comment": "Parameters for the package",
+			"title": "Parameters",
+			"description": "The parameters",
+			"properties": {"name": {"type": "string", "format": "hostname", "default": "web", "examples": ["api"]}}
+		}`))
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Recursive references can be parsed", t, func() {
+		var schema, err = Parse([]byte(`{
+			"$defs": {"node": {"type": "object", "properties": {"children": {"type": "array", "items": {"$ref": "#/$defs/node"}}}}},
+			"$ref": "#/$defs/node"
+		}`))
+		So(err, ShouldBeNil)
+
+		var validationErrors []*ValidationError
+		validationErrors, err = schema.Validate(map[string]any{
+			"children": []any{map[string]any{"children": []any{5}}},
+		})
+		So(err, ShouldBeNil)
+		So(validationErrors, ShouldHaveLength, 1)
+		So(validationErrors[0].Error(), ShouldEqual, ".children[0].children[0]: must be of type object, but is integer")
+	})
+}
+
+func TestValidate(t *testing.T) {
+	Convey("Given a schema for some parameters", t, func() {
+		var schema, err = Parse([]byte(`{
+			"type": "object",
+			"required": ["name", "replicas"],
+			"additionalProperties": false,
+			"properties": {
+				"name": {
+					"type": "object",
+					"required": ["first"],
+					"properties": {
+						"first": {"type": "string", "minLength": 1},
+						"last": {"type": "string"}
+					}
+				},
+				"replicas": {"type": "integer", "minimum": 1, "maximum": 10},
+				"tier": {"enum": ["web", "worker"]},
+				"ports": {
+					"type": "array",
+					"uniqueItems": true,
+					"items": {"$ref": "#/$defs/port"}
+				},
+				"labels": {
+					"type": "object",
+					"propertyNames": {"pattern": "^[a-z.-]+$"},
+					"additionalProperties": {"type": "string"}
+				},
+				"tls": {
+					"type": "object",
+					"if": {"properties": {"enabled": {"const": true}}},
+					"then": {"required": ["secretName"]}
+				}
+			},
+			"$defs": {
+				"port": {"type": "integer", "exclusiveMinimum": 0, "maximum": 65535}
+			}
+		}`))
+		So(err, ShouldBeNil)
+
+		Convey("Valid parameters have no errors, even when numbers aren't float64", func() {
+			var parameters = &map[string]any{
+				"name":     map[string]any{"first": "Foo", "last": "Bar"},
+				"replicas": 3,
+				"tier":     "web",
+				"ports":    []any{80, 443.0},
+				"labels":   map[string]any{"app.kubernetes.io-name": "foo"},
+				"tls":      map[string]any{"enabled": false},
+			}
+
+			var validationErrors, err = schema.Validate(parameters)
+			So(err, ShouldBeNil)
+			So(validationErrors, ShouldBeEmpty)
+		})
+
+		Convey("Every invalid part of the parameters is reported with its path", func() {
+			var parameters = map[string]any{
+				"name":     map[string]any{"last": 5},
+				"replicas": 2.5,
+				"tier":     "db",
+				"ports":    []any{80, 80, 70000},
+				"labels":   map[string]any{"Bad_Key": "x", "my-label": true},
+				"tls":      map[string]any{"enabled": true},
+				"extra":    nil,
+			}
+
+			var validationErrors, err = schema.Validate(parameters)
+			So(err, ShouldBeNil)
+
+			var messages = []string{}
+			for _, validationError := range validationErrors {
+				messages = append(messages, validationError.Error())
+			}
+			So(messages, ShouldResemble, []string{
+				`.extra: is not allowed`,
+				`.labels.Bad_Key: property name must match the pattern "^[a-z.-]+$"`,
+				`.labels["my-label"]: must be of type string, but is boolean`,
+				`.name.first: is required`,
+				`.name.last: must be of type string, but is integer`,
+				`.ports: must not contain duplicate items, but items 0 and 1 are the same`,
+				`.ports[2]: must be less than or equal to 65535`,
+				`.replicas: must be of type integer, but is number`,
+				`.tier: must be one of: "web", "worker"`,
+				`.tls.secretName: is required`,
+			})
+		})
+	})
+
+	Convey("Combining keywords are checked", t, func() {
+		var schema, err = Parse([]byte(`{
+			"oneOf": [{"type": "string"}, {"type": "integer"}, {"type": "number", "multipleOf": 0.1}],
+			"not": {"const": "forbidden"}
+		}`))
+		So(err, ShouldBeNil)
+
+		var validationErrors []*ValidationError
+		validationErrors, err = schema.Validate("ok")
+		So(err, ShouldBeNil)
+		So(validationErrors, ShouldBeEmpty)
+
+		validationErrors, err = schema.Validate(0.3)
+		So(err, ShouldBeNil)
+		So(validationErrors, ShouldBeEmpty)
+
+		validationErrors, err = schema.Validate(3)
+		So(err, ShouldBeNil)
+		So(validationErrors, ShouldHaveLength, 1)
+		So(validationErrors[0].Error(), ShouldEqual, `.: must match exactly one of the schemas in "oneOf", but matches 2`)
+
+		validationErrors, err = schema.Validate("forbidden")
+		So(err, ShouldBeNil)
+		So(validationErrors, ShouldHaveLength, 1)
+		So(validationErrors[0].Error(), ShouldEqual, `.: must not match the schema in "not"`)
+	})
+}
@@ -0,0 +1,392 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ValidationError describes a part of a value which doesn't match a schema.
+type ValidationError struct {
+	// Path is the location of the invalid part of the value, in the same form that it would be referenced in a template
+	// (e.g. ".name.first" or ".ports[0]").  The whole value is ".".
+	Path string
+
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// identifierPattern matches property names which can be referenced in a template with a dot.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate checks a value against the schema, and returns the parts of the value which don't match it.  The value may
+// be anything which can be converted to JSON (e.g. objects parsed from YAML).
+func (schema *Schema) Validate(value any) ([]*ValidationError, error) {
+	// Convert the value to the same types that JSON is parsed into, so all numbers are float64 and all objects are maps
+	var valueBytes, err = json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("value can't be converted to JSON: %s", err)
+	}
+
+	var jsonValue any
+	if err = json.Unmarshal(valueBytes, &jsonValue); err != nil {
+		return nil, fmt.Errorf("value can't be converted to JSON: %s", err)
+	}
+
+	return schema.root.validate(jsonValue, ""), nil
+}
+
+// validate returns the errors for a value at the given path.
+func (node *schemaNode) validate(value any, valuePath string) (result []*ValidationError) {
+	var addError = func(format string, args ...any) {
+		result = append(result, &ValidationError{Path: displayPath(valuePath), Message: fmt.Sprintf(format, args...)})
+	}
+
+	if node.isBoolean {
+		if !node.booleanValue {
+			addError("is not allowed")
+		}
+		return result
+	}
+
+	if node.ref != nil {
+		result = append(result, node.ref.validate(value, valuePath)...)
+	}
+
+	// Stop if the type is wrong, since the other keywords would just report the same problem in a less helpful way
+	if len(node.types) > 0 && !matchesAnyType(value, node.types) {
+		addError("must be of type %s, but is %s", strings.Join(node.types, " or "), getTypeName(value))
+		return result
+	}
+
+	if node.enum != nil {
+		var isMatch = false
+		for _, enumValue := range node.enum {
+			if reflect.DeepEqual(value, enumValue) {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			var enumStrings = make([]string, len(node.enum))
+			for i, enumValue := range node.enum {
+				enumStrings[i] = toJsonString(enumValue)
+			}
+			addError("must be one of: %s", strings.Join(enumStrings, ", "))
+		}
+	}
+
+	if node.constant != nil && !reflect.DeepEqual(value, *node.constant) {
+		addError("must be %s", toJsonString(*node.constant))
+	}
+
+	switch typedValue := value.(type) {
+	case float64:
+		result = append(result, node.validateNumber(typedValue, valuePath)...)
+	case string:
+		result = append(result, node.validateString(typedValue, valuePath)...)
+	case []any:
+		result = append(result, node.validateArray(typedValue, valuePath)...)
+	case map[string]any:
+		result = append(result, node.validateObject(typedValue, valuePath)...)
+	}
+
+	result = append(result, node.validateCombinations(value, valuePath)...)
+
+	return result
+}
+
+// validateNumber checks the keywords which apply to numbers.
+func (node *schemaNode) validateNumber(value float64, valuePath string) (result []*ValidationError) {
+	var addError = func(format string, args ...any) {
+		result = append(result, &ValidationError{Path: displayPath(valuePath), Message: fmt.Sprintf(format, args...)})
+	}
+
+	if node.minimum != nil && value < *node.minimum {
+		addError("must be greater than or equal to %s", formatNumber(*node.minimum))
+	}
+	if node.maximum != nil && value > *node.maximum {
+		addError("must be less than or equal to %s", formatNumber(*node.maximum))
+	}
+	if node.exclusiveMinimum != nil && value <= *node.exclusiveMinimum {
+		addError("must be greater than %s", formatNumber(*node.exclusiveMinimum))
+	}
+	if node.exclusiveMaximum != nil && value >= *node.exclusiveMaximum {
+		addError("must be less than %s", formatNumber(*node.exclusiveMaximum))
+	}
+	if node.multipleOf != nil {
+		// Allow for rounding errors in decimal multiples (e.g. 0.3 is a multiple of 0.1)
+		var quotient = value / *node.multipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			addError("must be a multiple of %s", formatNumber(*node.multipleOf))
+		}
+	}
+
+	return result
+}
+
+// validateString checks the keywords which apply to strings.
+func (node *schemaNode) validateString(value string, valuePath string) (result []*ValidationError) {
+	var addError = func(format string, args ...any) {
+		result = append(result, &ValidationError{Path: displayPath(valuePath), Message: fmt.Sprintf(format, args...)})
+	}
+
+	var length = utf8.RuneCountInString(value)
+	if node.minLength != nil && length < *node.minLength {
+		addError("must be at least %d characters long", *node.minLength)
+	}
+	if node.maxLength != nil && length > *node.maxLength {
+		addError("must be at most %d characters long", *node.maxLength)
+	}
+	if node.pattern != nil && !node.pattern.MatchString(value) {
+		addError("must match the pattern \"%s\"", node.pattern)
+	}
+
+	return result
+}
+
+// validateArray checks the keywords which apply to arrays.
+func (node *schemaNode) validateArray(value []any, valuePath string) (result []*ValidationError) {
+	var addError = func(format string, args ...any) {
+		result = append(result, &ValidationError{Path: displayPath(valuePath), Message: fmt.Sprintf(format, args...)})
+	}
+
+	if node.minItems != nil && len(value) < *node.minItems {
+		addError("must contain at least %d items", *node.minItems)
+	}
+	if node.maxItems != nil && len(value) > *node.maxItems {
+		addError("must contain at most %d items", *node.maxItems)
+	}
+
+	if node.uniqueItems {
+	findDuplicates:
+		for i := range value {
+			for j := i + 1; j < len(value); j++ {
+				if reflect.DeepEqual(value[i], value[j]) {
+					addError("must not contain duplicate items, but items %d and %d are the same", i, j)
+					break findDuplicates
+				}
+			}
+		}
+	}
+
+	for i, item := range value {
+		var itemPath = fmt.Sprintf("%s[%d]", valuePath, i)
+		if i < len(node.prefixItems) {
+			result = append(result, node.prefixItems[i].validate(item, itemPath)...)
+		} else if node.items != nil {
+			result = append(result, node.items.validate(item, itemPath)...)
+		}
+	}
+
+	if node.contains != nil {
+		var isMatch = false
+		for i, item := range value {
+			if len(node.contains.validate(item, fmt.Sprintf("%s[%d]", valuePath, i))) == 0 {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			addError("must contain an item which matches the schema in \"contains\"")
+		}
+	}
+
+	return result
+}
+
+// validateObject checks the keywords which apply to objects.
+func (node *schemaNode) validateObject(value map[string]any, valuePath string) (result []*ValidationError) {
+	var addError = func(format string, args ...any) {
+		result = append(result, &ValidationError{Path: displayPath(valuePath), Message: fmt.Sprintf(format, args...)})
+	}
+
+	if node.minProperties != nil && len(value) < *node.minProperties {
+		addError("must have at least %d properties", *node.minProperties)
+	}
+	if node.maxProperties != nil && len(value) > *node.maxProperties {
+		addError("must have at most %d properties", *node.maxProperties)
+	}
+
+	// Report missing properties at the path where they should be, since that is what needs to be fixed
+	for _, propertyName := range node.required {
+		if _, found := value[propertyName]; !found {
+			result = append(result, &ValidationError{Path: displayPath(getPropertyPath(valuePath, propertyName)), Message: "is required"})
+		}
+	}
+	for _, propertyName := range sortedKeys(node.dependentRequired) {
+		if _, found := value[propertyName]; !found {
+			continue
+		}
+		for _, requiredName := range node.dependentRequired[propertyName] {
+			if _, found := value[requiredName]; !found {
+				result = append(result, &ValidationError{
+					Path:    displayPath(getPropertyPath(valuePath, requiredName)),
+					Message: fmt.Sprintf("is required when \"%s\" is set", propertyName),
+				})
+			}
+		}
+	}
+	for _, propertyName := range sortedKeys(node.dependentSchemas) {
+		if _, found := value[propertyName]; found {
+			result = append(result, node.dependentSchemas[propertyName].validate(value, valuePath)...)
+		}
+	}
+
+	for _, propertyName := range sortedKeys(value) {
+		var propertyValue = value[propertyName]
+		var propertyPath = getPropertyPath(valuePath, propertyName)
+
+		if node.propertyNames != nil {
+			for _, nameError := range node.propertyNames.validate(propertyName, propertyPath) {
+				result = append(result, &ValidationError{Path: nameError.Path, Message: fmt.Sprintf("property name %s", nameError.Message)})
+			}
+		}
+
+		// Properties which aren't matched by "properties" or "patternProperties" are checked by "additionalProperties"
+		var isMatched = false
+		if propertySchema, found := node.properties[propertyName]; found {
+			isMatched = true
+			result = append(result, propertySchema.validate(propertyValue, propertyPath)...)
+		}
+		for _, patternProperty := range node.patternProperties {
+			if patternProperty.pattern.MatchString(propertyName) {
+				isMatched = true
+				result = append(result, patternProperty.schema.validate(propertyValue, propertyPath)...)
+			}
+		}
+		if !isMatched && node.additionalProperties != nil {
+			result = append(result, node.additionalProperties.validate(propertyValue, propertyPath)...)
+		}
+	}
+
+	return result
+}
+
+// validateCombinations checks the keywords which combine schemas.
+func (node *schemaNode) validateCombinations(value any, valuePath string) (result []*ValidationError) {
+	var addError = func(format string, args ...any) {
+		result = append(result, &ValidationError{Path: displayPath(valuePath), Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, subSchema := range node.allOf {
+		result = append(result, subSchema.validate(value, valuePath)...)
+	}
+
+	if node.anyOf != nil {
+		var isMatch = false
+		for _, subSchema := range node.anyOf {
+			if len(subSchema.validate(value, valuePath)) == 0 {
+				isMatch = true
+				break
+			}
+		}
+		if !isMatch {
+			addError("must match at least one of the schemas in \"anyOf\"")
+		}
+	}
+
+	if node.oneOf != nil {
+		var numMatches = 0
+		for _, subSchema := range node.oneOf {
+			if len(subSchema.validate(value, valuePath)) == 0 {
+				numMatches++
+			}
+		}
+		if numMatches != 1 {
+			addError("must match exactly one of the schemas in \"oneOf\", but matches %d", numMatches)
+		}
+	}
+
+	if node.not != nil && len(node.not.validate(value, valuePath)) == 0 {
+		addError("must not match the schema in \"not\"")
+	}
+
+	if node.ifSchema != nil {
+		if len(node.ifSchema.validate(value, valuePath)) == 0 {
+			if node.thenSchema != nil {
+				result = append(result, node.thenSchema.validate(value, valuePath)...)
+			}
+		} else if node.elseSchema != nil {
+			result = append(result, node.elseSchema.validate(value, valuePath)...)
+		}
+	}
+
+	return result
+}
+
+// matchesAnyType returns true if a value has one of the given types.
+func matchesAnyType(value any, types []string) bool {
+	var typeName = getTypeName(value)
+	for _, expectedType := range types {
+		if expectedType == typeName || (expectedType == "number" && typeName == "integer") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getTypeName returns the JSON Schema type of a value.  Numbers without a fractional part are integers.
+func getTypeName(value any) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if typedValue == math.Trunc(typedValue) && !math.IsInf(typedValue, 0) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// getPropertyPath returns the path of a property in an object.
+func getPropertyPath(objectPath string, propertyName string) string {
+	if identifierPattern.MatchString(propertyName) {
+		return objectPath + "." + propertyName
+	}
+
+	return fmt.Sprintf("%s[%s]", objectPath, strconv.Quote(propertyName))
+}
+
+// displayPath returns the path to show for a value, where the whole value is ".".
+func displayPath(valuePath string) string {
+	if valuePath == "" {
+		return "."
+	}
+
+	return valuePath
+}
+
+// formatNumber formats a number from a schema without unnecessary decimal places.
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// toJsonString formats a value from a schema as JSON.
+func toJsonString(value any) string {
+	var valueBytes, err = json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(valueBytes)
+}
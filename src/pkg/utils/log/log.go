@@ -123,6 +123,15 @@ func (result *Level) UnmarshalYAML(unmarshaller *yaml.Node) error {
 	return nil
 }
 
+// SetWriterInfo changes the stream to use when writing info, verbose and debug logs (e.g. so they don't get mixed with
+// program output).
+func SetWriterInfo(writer *os.File) {
+	WriterInfo = writer
+	for _, level := range []Level{LevelInfo, LevelVerbose, LevelDebug} {
+		loggers[level].SetOutput(writer)
+	}
+}
+
 // currentLogLevel is the currently selected log level.
 var currentLogLevel = DefaultLevel
 
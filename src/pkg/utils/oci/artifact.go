@@ -0,0 +1,286 @@
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// MediaTypeImageManifest is the media type of OCI image manifests.
+const MediaTypeImageManifest = "application/vnd.oci.image.manifest.v1+json"
+
+// maxManifestSize is the largest manifest that we are willing to download.
+const maxManifestSize = 4 * 1024 * 1024
+
+// Descriptor references content in the registry.
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is an OCI image manifest.
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Blob is a piece of content in an artifact.
+type Blob struct {
+	MediaType string
+	Data      []byte
+}
+
+// Artifact is a set of blobs which are stored in the registry under a single manifest.
+type Artifact struct {
+	ArtifactType string
+	Config       Blob
+	Layers       []Blob
+	Annotations  map[string]string
+}
+
+// GetDigest returns the digest of some content, in the form "sha256:<hex>".
+func GetDigest(data []byte) string {
+	var hash = sha256.Sum256(data)
+
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
+
+// PushArtifact uploads all blobs in the artifact, and then tags the artifact's manifest.
+func (client *Client) PushArtifact(repository string, tag string, artifact *Artifact) (err error) {
+	var manifest = Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		ArtifactType:  artifact.ArtifactType,
+		Annotations:   artifact.Annotations,
+		Layers:        []Descriptor{},
+	}
+
+	// Upload the blobs.
+	manifest.Config, err = client.pushBlob(repository, artifact.Config)
+	if err != nil {
+		return err
+	}
+	for _, layer := range artifact.Layers {
+		var descriptor Descriptor
+		descriptor, err = client.pushBlob(repository, layer)
+		if err != nil {
+			return err
+		}
+
+		manifest.Layers = append(manifest.Layers, descriptor)
+	}
+
+	// Upload the manifest.
+	var manifestBytes []byte
+	manifestBytes, err = json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to serialize manifest: %s", err)
+	}
+
+	var manifestUrl string
+	manifestUrl, err = client.getUrl(fmt.Sprintf("/v2/%s/manifests/%s", repository, tag))
+	if err != nil {
+		return err
+	}
+
+	var response *http.Response
+	response, err = client.do(&request{
+		method:      http.MethodPut,
+		url:         manifestUrl,
+		scope:       repositoryScope(repository, true),
+		contentType: MediaTypeImageManifest,
+		body:        manifestBytes,
+	})
+	if err != nil {
+		return err
+	}
+	defer drainAndClose(response)
+
+	return checkResponse(response, http.StatusCreated)
+}
+
+// PullArtifact downloads the manifest with the given tag (or digest) and all of the blobs that it references.
+func (client *Client) PullArtifact(repository string, reference string) (result *Artifact, err error) {
+	var manifest *Manifest
+	manifest, err = client.getManifest(repository, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &Artifact{
+		ArtifactType: manifest.ArtifactType,
+		Annotations:  manifest.Annotations,
+	}
+
+	result.Config, err = client.pullBlob(repository, manifest.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layerDescriptor := range manifest.Layers {
+		var layer Blob
+		layer, err = client.pullBlob(repository, layerDescriptor)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Layers = append(result.Layers, layer)
+	}
+
+	return result, nil
+}
+
+func (client *Client) getManifest(repository string, reference string) (*Manifest, error) {
+	var manifestUrl, err = client.getUrl(fmt.Sprintf("/v2/%s/manifests/%s", repository, reference))
+	if err != nil {
+		return nil, err
+	}
+
+	var response *http.Response
+	response, err = client.do(&request{
+		method: http.MethodGet,
+		url:    manifestUrl,
+		scope:  repositoryScope(repository, false),
+		accept: []string{MediaTypeImageManifest},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer drainAndClose(response)
+
+	if err = checkResponse(response, http.StatusOK); err != nil {
+		return nil, err
+	}
+
+	var manifestBytes []byte
+	manifestBytes, err = io.ReadAll(io.LimitReader(response.Body, maxManifestSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest '%s:%s': %s", repository, reference, err)
+	}
+
+	var manifest = new(Manifest)
+	err = json.Unmarshal(manifestBytes, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest '%s:%s': %s", repository, reference, err)
+	}
+
+	if manifest.MediaType != "" && manifest.MediaType != MediaTypeImageManifest {
+		return nil, fmt.Errorf("unsupported manifest type '%s' for '%s:%s'", manifest.MediaType, repository, reference)
+	}
+
+	return manifest, nil
+}
+
+func (client *Client) pushBlob(repository string, blob Blob) (descriptor Descriptor, err error) {
+	descriptor = Descriptor{
+		MediaType: blob.MediaType,
+		Digest:    GetDigest(blob.Data),
+		Size:      int64(len(blob.Data)),
+	}
+
+	// Don't upload blobs that the registry already has.
+	var blobUrl string
+	blobUrl, err = client.getUrl(fmt.Sprintf("/v2/%s/blobs/%s", repository, descriptor.Digest))
+	if err != nil {
+		return descriptor, err
+	}
+
+	var response *http.Response
+	response, err = client.do(&request{method: http.MethodHead, url: blobUrl, scope: repositoryScope(repository, true)})
+	if err != nil {
+		return descriptor, err
+	}
+	drainAndClose(response)
+	if response.StatusCode == http.StatusOK {
+		return descriptor, nil
+	}
+
+	// Start an upload.
+	var uploadsUrl string
+	uploadsUrl, err = client.getUrl(fmt.Sprintf("/v2/%s/blobs/uploads/", repository))
+	if err != nil {
+		return descriptor, err
+	}
+
+	response, err = client.do(&request{method: http.MethodPost, url: uploadsUrl, scope: repositoryScope(repository, true)})
+	if err != nil {
+		return descriptor, err
+	}
+	drainAndClose(response)
+	if err = checkResponse(response, http.StatusAccepted); err != nil {
+		return descriptor, err
+	}
+
+	// Finish the upload in a single request.
+	var uploadUrl string
+	uploadUrl, err = client.getUrl(response.Header.Get("Location"))
+	if err != nil {
+		return descriptor, err
+	}
+
+	var parsedUploadUrl *url.URL
+	parsedUploadUrl, err = url.Parse(uploadUrl)
+	if err != nil {
+		return descriptor, err
+	}
+	var query = parsedUploadUrl.Query()
+	query.Set("digest", descriptor.Digest)
+	parsedUploadUrl.RawQuery = query.Encode()
+
+	response, err = client.do(&request{
+		method:      http.MethodPut,
+		url:         parsedUploadUrl.String(),
+		scope:       repositoryScope(repository, true),
+		contentType: "application/octet-stream",
+		body:        blob.Data,
+	})
+	if err != nil {
+		return descriptor, err
+	}
+	defer drainAndClose(response)
+
+	return descriptor, checkResponse(response, http.StatusCreated)
+}
+
+func (client *Client) pullBlob(repository string, descriptor Descriptor) (blob Blob, err error) {
+	var blobUrl string
+	blobUrl, err = client.getUrl(fmt.Sprintf("/v2/%s/blobs/%s", repository, descriptor.Digest))
+	if err != nil {
+		return blob, err
+	}
+
+	var response *http.Response
+	response, err = client.do(&request{method: http.MethodGet, url: blobUrl, scope: repositoryScope(repository, false)})
+	if err != nil {
+		return blob, err
+	}
+	defer drainAndClose(response)
+
+	if err = checkResponse(response, http.StatusOK); err != nil {
+		return blob, err
+	}
+
+	// Don't read more than the manifest says we should get.
+	var data []byte
+	data, err = io.ReadAll(io.LimitReader(response.Body, descriptor.Size+1))
+	if err != nil {
+		return blob, fmt.Errorf("failed to download blob '%s': %s", descriptor.Digest, err)
+	}
+
+	// Make sure we got what we asked for.
+	if int64(len(data)) != descriptor.Size || GetDigest(data) != descriptor.Digest {
+		return blob, fmt.Errorf("downloaded blob does not match its digest: %s", descriptor.Digest)
+	}
+
+	return Blob{MediaType: descriptor.MediaType, Data: data}, nil
+}
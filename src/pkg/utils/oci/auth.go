@@ -0,0 +1,164 @@
+package oci
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type tokenResponse struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// getCachedAuthorization returns the value of the "Authorization" header to send for the given scope, if we already have one.
+func (client *Client) getCachedAuthorization(scope string) string {
+	client.tokensLock.Lock()
+	defer client.tokensLock.Unlock()
+
+	return client.tokens[scope]
+}
+
+// authenticate answers a "WWW-Authenticate" challenge and caches the resulting "Authorization" header value for the scope.
+func (client *Client) authenticate(challenge string, scope string) (authorization string, err error) {
+	var scheme, params = parseChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if client.username == "" {
+			return "", errors.New("registry requires a username and password")
+		}
+		authorization = "Basic " + basicCredentials(client.username, client.password)
+	case "bearer":
+		var token string
+		token, err = client.getToken(params, scope)
+		if err != nil {
+			return "", err
+		}
+		authorization = "Bearer " + token
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge: %s", challenge)
+	}
+
+	client.tokensLock.Lock()
+	defer client.tokensLock.Unlock()
+	client.tokens[scope] = authorization
+
+	return authorization, nil
+}
+
+// getToken requests a bearer token from the token server named in the challenge.
+func (client *Client) getToken(challengeParams map[string]string, scope string) (string, error) {
+	var realm = challengeParams["realm"]
+	if realm == "" {
+		return "", errors.New("bearer challenge does not include a realm")
+	}
+
+	var tokenUrl, err = url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm '%s': %s", realm, err)
+	}
+
+	// Prefer the scope that the registry asked for.
+	if challengeScope, ok := challengeParams["scope"]; ok && challengeScope != "" {
+		scope = challengeScope
+	}
+
+	var query = tokenUrl.Query()
+	if service, ok := challengeParams["service"]; ok {
+		query.Set("service", service)
+	}
+	if scope != "" {
+		query.Set("scope", scope)
+	}
+	tokenUrl.RawQuery = query.Encode()
+
+	var httpRequest *http.Request
+	httpRequest, err = http.NewRequest(http.MethodGet, tokenUrl.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if client.username != "" {
+		httpRequest.SetBasicAuth(client.username, client.password)
+	}
+
+	var response *http.Response
+	response, err = client.httpClient.Do(httpRequest)
+	if err != nil {
+		return "", fmt.Errorf("failed to call token server: %s", err)
+	}
+	defer drainAndClose(response)
+
+	if err = checkResponse(response, http.StatusOK); err != nil {
+		return "", err
+	}
+
+	var result tokenResponse
+	err = json.NewDecoder(response.Body).Decode(&result)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse response from token server: %s", err)
+	}
+
+	if result.Token != "" {
+		return result.Token, nil
+	}
+	if result.AccessToken != "" {
+		return result.AccessToken, nil
+	}
+
+	return "", errors.New("token server did not return a token")
+}
+
+// parseChallenge parses a "WWW-Authenticate" header value such as:
+//
+//	Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:foo:pull"
+func parseChallenge(challenge string) (scheme string, params map[string]string) {
+	params = map[string]string{}
+
+	challenge = strings.TrimSpace(challenge)
+	var spaceIndex = strings.IndexByte(challenge, ' ')
+	if spaceIndex < 0 {
+		return challenge, params
+	}
+	scheme, challenge = challenge[:spaceIndex], challenge[spaceIndex+1:]
+
+	for len(challenge) > 0 {
+		// Get the key.
+		challenge = strings.TrimLeft(challenge, " ,")
+		var equalsIndex = strings.IndexByte(challenge, '=')
+		if equalsIndex < 0 {
+			break
+		}
+		var key = strings.ToLower(strings.TrimSpace(challenge[:equalsIndex]))
+		challenge = challenge[equalsIndex+1:]
+
+		// Get the value, which may be quoted (quoted values may contain commas).
+		var value string
+		if strings.HasPrefix(challenge, "\"") {
+			var endQuoteIndex = strings.IndexByte(challenge[1:], '"')
+			if endQuoteIndex < 0 {
+				value, challenge = challenge[1:], ""
+			} else {
+				value, challenge = challenge[1:endQuoteIndex+1], challenge[endQuoteIndex+2:]
+			}
+		} else {
+			var commaIndex = strings.IndexByte(challenge, ',')
+			if commaIndex < 0 {
+				value, challenge = challenge, ""
+			} else {
+				value, challenge = challenge[:commaIndex], challenge[commaIndex+1:]
+			}
+		}
+
+		params[key] = strings.TrimSpace(value)
+	}
+
+	return scheme, params
+}
+
+func basicCredentials(username string, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
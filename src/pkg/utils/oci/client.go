@@ -0,0 +1,202 @@
+package oci
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+)
+
+// ErrNotFound is returned when a repository, manifest or blob doesn't exist in the registry.
+var ErrNotFound = errors.New("not found in registry")
+
+// defaultHttpClient is shared by all clients, so that connections to registries are reused.
+var defaultHttpClient = newHttpClient(time.Minute)
+
+// Client talks to a container registry using the OCI Distribution HTTP API.
+type Client struct {
+	baseUrl    *url.URL
+	username   string
+	password   string
+	httpClient *http.Client
+
+	// tokens caches bearer tokens by scope, so we only need to authenticate once per scope.
+	tokens     map[string]string
+	tokensLock sync.Mutex
+}
+
+// NewClient creates a client for the given registry.  The registry may be a host name (in which case HTTPS is used)
+// or a URL with an explicit "http" or "https" scheme.  If a username is provided, it is used to request tokens.
+func NewClient(registry string, username string, password string) (*Client, error) {
+	if strings.TrimSpace(registry) == "" {
+		return nil, errors.New("registry cannot be empty")
+	}
+
+	var registryUrl = registry
+	if !strings.Contains(registryUrl, "://") {
+		registryUrl = "https://" + registryUrl
+	}
+
+	var baseUrl, err = url.Parse(strings.TrimSuffix(registryUrl, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry '%s': %s", registry, err)
+	}
+	if baseUrl.Scheme != "http" && baseUrl.Scheme != "https" {
+		return nil, fmt.Errorf("invalid registry '%s': scheme must be \"http\" or \"https\"", registry)
+	}
+
+	return &Client{
+		baseUrl:    baseUrl,
+		username:   username,
+		password:   password,
+		httpClient: defaultHttpClient,
+		tokens:     map[string]string{},
+	}, nil
+}
+
+// newHttpClient creates an HTTP client which fails if the registry can't be reached, or doesn't start responding within
+// the given timeout.  There is no overall timeout, so downloading a large blob isn't interrupted while data is arriving.
+func newHttpClient(responseHeaderTimeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   30 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			IdleConnTimeout:       90 * time.Second,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: 1 * time.Second,
+			ResponseHeaderTimeout: responseHeaderTimeout,
+		},
+	}
+}
+
+// request describes a call to the registry.  The body is kept as bytes so the request can be replayed after authenticating.
+type request struct {
+	method      string
+	url         string
+	scope       string
+	contentType string
+	accept      []string
+	body        []byte
+}
+
+// do sends a request to the registry, authenticating and retrying once if the registry asks for credentials.
+// The caller must close the response body.
+func (client *Client) do(req *request) (*http.Response, error) {
+	var response, err = client.send(req, client.getCachedAuthorization(req.scope))
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusUnauthorized {
+		return response, nil
+	}
+
+	// Get credentials based on the challenge and try again.
+	var challenge = response.Header.Get("WWW-Authenticate")
+	drainAndClose(response)
+
+	var authorization string
+	authorization, err = client.authenticate(challenge, req.scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate with registry '%s': %s", client.baseUrl.Host, err)
+	}
+
+	return client.send(req, authorization)
+}
+
+func (client *Client) send(req *request, authorization string) (*http.Response, error) {
+	var body io.Reader
+	if req.body != nil {
+		body = bytes.NewReader(req.body)
+	}
+
+	var httpRequest, err = http.NewRequest(req.method, req.url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.contentType != "" {
+		httpRequest.Header.Set("Content-Type", req.contentType)
+	}
+	for _, accept := range req.accept {
+		httpRequest.Header.Add("Accept", accept)
+	}
+	if authorization != "" {
+		httpRequest.Header.Set("Authorization", authorization)
+	}
+
+	log.Debugf("%s %s", req.method, req.url)
+	var response *http.Response
+	response, err = client.httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call registry: %s", err)
+	}
+
+	return response, nil
+}
+
+// getUrl returns an absolute URL for a path in the registry, or resolves a (possibly relative) URL returned by the registry.
+func (client *Client) getUrl(pathOrUrl string) (string, error) {
+	var ref, err = url.Parse(pathOrUrl)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL returned by registry '%s': %s", pathOrUrl, err)
+	}
+
+	return client.baseUrl.ResolveReference(ref).String(), nil
+}
+
+// checkResponse returns an error if the response doesn't have one of the expected status codes.
+func checkResponse(response *http.Response, expectedStatusCodes ...int) error {
+	for _, statusCode := range expectedStatusCodes {
+		if response.StatusCode == statusCode {
+			return nil
+		}
+	}
+
+	var message, _ = io.ReadAll(io.LimitReader(response.Body, 4096))
+	var err = fmt.Errorf(
+		"unexpected response from registry for %s %s: %s\n%s",
+		response.Request.Method,
+		response.Request.URL,
+		response.Status,
+		strings.TrimSpace(string(message)),
+	)
+
+	if response.StatusCode == http.StatusNotFound {
+		return errors.Join(ErrNotFound, err)
+	}
+
+	return err
+}
+
+func drainAndClose(response *http.Response) {
+	_, _ = io.Copy(io.Discard, response.Body)
+	if err := response.Body.Close(); err != nil {
+		log.Errorf("failed to close response stream: %s", err)
+	}
+}
+
+// repositoryScope returns the token scope for an operation on a repository.
+func repositoryScope(repository string, push bool) string {
+	if push {
+		return fmt.Sprintf("repository:%s:pull,push", repository)
+	}
+
+	return fmt.Sprintf("repository:%s:pull", repository)
+}
+
+// catalogScope is the token scope for listing repositories.
+const catalogScope = "registry:catalog:*"
@@ -0,0 +1,130 @@
+package oci
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/oci/ocitest"
+)
+
+func TestClient(t *testing.T) {
+	Convey("Given a registry which requires token authentication", t, func() {
+		var registry = ocitest.NewRegistryWithTokenAuth("alice", "secret")
+		Reset(registry.Close)
+
+		var artifact = &Artifact{
+			ArtifactType: "application/vnd.example",
+			Config:       Blob{MediaType: "application/vnd.example.config+json", Data: []byte(`{"hello":"world"}`)},
+			Layers:       []Blob{{MediaType: "application/vnd.example.layer", Data: []byte("layer data")}},
+		}
+
+		Convey("Requests without valid credentials fail", func() {
+			var client, err = NewClient(registry.Url(), "alice", "wrong")
+			So(err, ShouldBeNil)
+
+			err = client.PushArtifact("example/thing", "1.0.0", artifact)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Artifacts can be pushed and pulled", func() {
+			var client, err = NewClient(registry.Url(), "alice", "secret")
+			So(err, ShouldBeNil)
+
+			So(client.PushArtifact("example/thing", "1.0.0", artifact), ShouldBeNil)
+
+			var pulled *Artifact
+			pulled, err = client.PullArtifact("example/thing", "1.0.0")
+			So(err, ShouldBeNil)
+			So(pulled.ArtifactType, ShouldEqual, artifact.ArtifactType)
+			So(pulled.Config, ShouldResemble, artifact.Config)
+			So(pulled.Layers, ShouldResemble, artifact.Layers)
+
+			_, err = client.PullArtifact("example/thing", "2.0.0")
+			So(err, ShouldWrap, ErrNotFound)
+		})
+
+		Convey("Tags and repositories are listed across pages", func() {
+			var numTags = pageSize*2 + 5
+			for i := 0; i < numTags; i++ {
+				registry.PutTag("example/many", fmt.Sprintf("1.0.%d", i))
+			}
+			registry.PutTag("example/other", "1.0.0")
+
+			var client, err = NewClient(registry.Url(), "alice", "secret")
+			So(err, ShouldBeNil)
+
+			var tags = readAll(func(ch chan<- string) error { return client.ListTags(ch, "example/many") }, &err)
+			So(err, ShouldBeNil)
+			So(tags, ShouldHaveLength, numTags)
+
+			var repositories = readAll(client.ListRepositories, &err)
+			So(err, ShouldBeNil)
+			So(repositories, ShouldResemble, []string{"example/many", "example/other"})
+		})
+	})
+}
+
+func TestClientTimeouts(t *testing.T) {
+	Convey("Given a registry which never responds", t, func() {
+		var stop = make(chan struct{})
+		var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-stop:
+			}
+		}))
+		Reset(func() {
+			close(stop)
+			server.Close()
+		})
+
+		var client, err = NewClient(server.URL, "", "")
+		So(err, ShouldBeNil)
+
+		Convey("Requests have no overall deadline, so large blobs can be downloaded", func() {
+			So(client.httpClient.Timeout, ShouldEqual, 0)
+		})
+
+		Convey("Requests fail if the registry doesn't start responding", func() {
+			client.httpClient = newHttpClient(100 * time.Millisecond)
+
+			_ = readAll(func(ch chan<- string) error { return client.ListTags(ch, "example/thing") }, &err)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "timeout awaiting response headers")
+		})
+	})
+}
+
+func TestParseChallenge(t *testing.T) {
+	Convey("Challenge parameters may contain commas inside quotes", t, func() {
+		var scheme, params = parseChallenge(`Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:foo/bar:pull,push"`)
+		So(scheme, ShouldEqual, "Bearer")
+		So(params, ShouldResemble, map[string]string{
+			"realm":   "https://auth.example.com/token",
+			"service": "registry.example.com",
+			"scope":   "repository:foo/bar:pull,push",
+		})
+	})
+}
+
+func readAll(fn func(ch chan<- string) error, err *error) []string {
+	var ch = make(chan string)
+	var done = make(chan []string)
+	go func() {
+		var result []string
+		for item := range ch {
+			result = append(result, item)
+		}
+		done <- result
+	}()
+
+	*err = fn(ch)
+	close(ch)
+
+	return <-done
+}
@@ -0,0 +1,110 @@
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// pageSize is the number of results to ask for in each page of a paginated list.
+const pageSize = 100
+
+type tagsListResponse struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+type catalogResponse struct {
+	Repositories []string `json:"repositories"`
+}
+
+// ListTags gets the tags of a repository in the registry.
+func (client *Client) ListTags(ch chan<- string, repository string) error {
+	var firstPage = fmt.Sprintf("/v2/%s/tags/list?n=%d", repository, pageSize)
+
+	return client.listPages(firstPage, repositoryScope(repository, false), func(response *http.Response) error {
+		var result tagsListResponse
+		if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+			return fmt.Errorf("failed to parse tags of repository '%s': %s", repository, err)
+		}
+
+		for _, tag := range result.Tags {
+			ch <- tag
+		}
+
+		return nil
+	})
+}
+
+// ListRepositories gets the names of all repositories in the registry.
+func (client *Client) ListRepositories(ch chan<- string) error {
+	var firstPage = fmt.Sprintf("/v2/_catalog?n=%d", pageSize)
+
+	return client.listPages(firstPage, catalogScope, func(response *http.Response) error {
+		var result catalogResponse
+		if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+			return fmt.Errorf("failed to parse repository catalog: %s", err)
+		}
+
+		for _, repository := range result.Repositories {
+			ch <- repository
+		}
+
+		return nil
+	})
+}
+
+// listPages follows the "Link" headers of a paginated list, consuming each page.
+func (client *Client) listPages(pageUrl string, scope string, consumePage func(*http.Response) error) (err error) {
+	for pageUrl != "" {
+		var requestUrl string
+		requestUrl, err = client.getUrl(pageUrl)
+		if err != nil {
+			return err
+		}
+
+		var response *http.Response
+		response, err = client.do(&request{method: http.MethodGet, url: requestUrl, scope: scope})
+		if err != nil {
+			return err
+		}
+
+		err = checkResponse(response, http.StatusOK)
+		if err == nil {
+			err = consumePage(response)
+		}
+		drainAndClose(response)
+		if err != nil {
+			return err
+		}
+
+		pageUrl = getNextLink(response.Header.Values("Link"))
+	}
+
+	return nil
+}
+
+// getNextLink returns the target of the "next" link in a set of "Link" header values, such as:
+//
+//	</v2/_catalog?last=b&n=100>; rel="next"
+func getNextLink(linkHeaders []string) string {
+	for _, linkHeader := range linkHeaders {
+		for _, link := range strings.Split(linkHeader, ",") {
+			var parts = strings.Split(link, ";")
+			var target = strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+
+			for _, param := range parts[1:] {
+				var key, value, found = strings.Cut(strings.TrimSpace(param), "=")
+				if found && strings.EqualFold(key, "rel") && strings.Trim(value, "\"") == "next" {
+					return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+				}
+			}
+		}
+	}
+
+	return ""
+}
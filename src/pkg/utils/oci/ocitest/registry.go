@@ -0,0 +1,337 @@
+// Package ocitest provides an in-memory container registry which implements enough of the OCI Distribution API to test clients against.
+package ocitest
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Registry is an in-memory registry which is served by an httptest.Server.
+type Registry struct {
+	// Server is the underlying test server.  Its URL can be used as the registry location.
+	Server *httptest.Server
+
+	requireToken bool
+	username     string
+	password     string
+	tokens       map[string]bool
+
+	blobs     map[string][]byte
+	manifests map[string]map[string][]byte
+	uploads   map[string]string
+	lock      sync.Mutex
+}
+
+// NewRegistry starts a new registry which allows anonymous access.
+func NewRegistry() *Registry {
+	var registry = &Registry{
+		tokens:    map[string]bool{},
+		blobs:     map[string][]byte{},
+		manifests: map[string]map[string][]byte{},
+		uploads:   map[string]string{},
+	}
+	registry.Server = httptest.NewServer(http.HandlerFunc(registry.serveHTTP))
+
+	return registry
+}
+
+// NewRegistryWithTokenAuth starts a new registry which requires bearer tokens, which are issued to the given user by the registry's "/token" endpoint.
+// If the username is empty, tokens are issued to anonymous users (like public images on Docker Hub).
+func NewRegistryWithTokenAuth(username string, password string) *Registry {
+	var registry = NewRegistry()
+	registry.requireToken = true
+	registry.username = username
+	registry.password = password
+
+	return registry
+}
+
+// Close shuts down the registry.
+func (registry *Registry) Close() {
+	registry.Server.Close()
+}
+
+// Url returns the base URL of the registry.
+func (registry *Registry) Url() string {
+	return registry.Server.URL
+}
+
+// PutTag stores a manifest under the given tag without uploading any blobs, which is useful for testing tag listing.
+func (registry *Registry) PutTag(repository string, tag string) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	registry.putManifest(repository, tag, []byte("{}"))
+}
+
+func (registry *Registry) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/token" {
+		registry.serveToken(w, r)
+		return
+	}
+
+	if !registry.isAuthorized(r) {
+		w.Header().Set(
+			"WWW-Authenticate",
+			fmt.Sprintf(`Bearer realm="%s/token",service="ocitest"`, registry.Server.URL),
+		)
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	var path = strings.TrimPrefix(r.URL.Path, "/v2/")
+	switch {
+	case r.URL.Path == "/v2/" || r.URL.Path == "/v2":
+		w.WriteHeader(http.StatusOK)
+	case path == "_catalog":
+		registry.serveCatalog(w, r)
+	case strings.HasSuffix(path, "/tags/list"):
+		registry.serveTags(w, r, strings.TrimSuffix(path, "/tags/list"))
+	case strings.Contains(path, "/blobs/uploads/"):
+		var repository, uploadId, _ = strings.Cut(path, "/blobs/uploads/")
+		registry.serveUpload(w, r, repository, uploadId)
+	case strings.Contains(path, "/blobs/"):
+		var _, digest, _ = strings.Cut(path, "/blobs/")
+		registry.serveBlob(w, r, digest)
+	case strings.Contains(path, "/manifests/"):
+		var repository, reference, _ = strings.Cut(path, "/manifests/")
+		registry.serveManifest(w, r, repository, reference)
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "unknown endpoint")
+	}
+}
+
+func (registry *Registry) isAuthorized(r *http.Request) bool {
+	if !registry.requireToken {
+		return true
+	}
+
+	var token, found = strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return false
+	}
+
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	return registry.tokens[token]
+}
+
+func (registry *Registry) serveToken(w http.ResponseWriter, r *http.Request) {
+	if registry.username != "" {
+		var username, password, ok = r.BasicAuth()
+		if !ok || username != registry.username || password != registry.password {
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "invalid credentials")
+			return
+		}
+	}
+
+	var tokenBytes = make([]byte, 16)
+	_, _ = rand.Read(tokenBytes)
+	var token = hex.EncodeToString(tokenBytes)
+
+	registry.lock.Lock()
+	registry.tokens[token] = true
+	registry.lock.Unlock()
+
+	writeJson(w, map[string]string{"token": token})
+}
+
+func (registry *Registry) serveCatalog(w http.ResponseWriter, r *http.Request) {
+	var repositories = make([]string, 0, len(registry.manifests))
+	for repository := range registry.manifests {
+		repositories = append(repositories, repository)
+	}
+
+	var page = paginate(w, r, repositories)
+	writeJson(w, map[string]any{"repositories": page})
+}
+
+func (registry *Registry) serveTags(w http.ResponseWriter, r *http.Request, repository string) {
+	var references, found = registry.manifests[repository]
+	if !found {
+		writeError(w, http.StatusNotFound, "NAME_UNKNOWN", "repository name not known to registry")
+		return
+	}
+
+	var tags = []string{}
+	for reference := range references {
+		if !strings.HasPrefix(reference, "sha256:") {
+			tags = append(tags, reference)
+		}
+	}
+
+	var page = paginate(w, r, tags)
+	writeJson(w, map[string]any{"name": repository, "tags": page})
+}
+
+func (registry *Registry) serveUpload(w http.ResponseWriter, r *http.Request, repository string, uploadId string) {
+	switch r.Method {
+	case http.MethodPost:
+		var idBytes = make([]byte, 8)
+		_, _ = rand.Read(idBytes)
+		uploadId = hex.EncodeToString(idBytes)
+		registry.uploads[uploadId] = repository
+
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/%s", repository, uploadId))
+		w.WriteHeader(http.StatusAccepted)
+	case http.MethodPut:
+		if _, found := registry.uploads[uploadId]; !found {
+			writeError(w, http.StatusNotFound, "BLOB_UPLOAD_UNKNOWN", "upload not found")
+			return
+		}
+		delete(registry.uploads, uploadId)
+
+		var data, err = io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BLOB_UPLOAD_INVALID", err.Error())
+			return
+		}
+
+		var digest = r.URL.Query().Get("digest")
+		if digest != getDigest(data) {
+			writeError(w, http.StatusBadRequest, "DIGEST_INVALID", "provided digest did not match uploaded content")
+			return
+		}
+
+		registry.blobs[digest] = data
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/%s", repository, digest))
+		w.WriteHeader(http.StatusCreated)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", "unsupported method")
+	}
+}
+
+func (registry *Registry) serveBlob(w http.ResponseWriter, r *http.Request, digest string) {
+	var data, found = registry.blobs[digest]
+	if !found {
+		writeError(w, http.StatusNotFound, "BLOB_UNKNOWN", "blob unknown to registry")
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Docker-Content-Digest", digest)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(data)
+	}
+}
+
+func (registry *Registry) serveManifest(w http.ResponseWriter, r *http.Request, repository string, reference string) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		var manifest, found = registry.manifests[repository][reference]
+		if !found {
+			writeError(w, http.StatusNotFound, "MANIFEST_UNKNOWN", "manifest unknown")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Docker-Content-Digest", getDigest(manifest))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(manifest)
+		}
+	case http.MethodPut:
+		var data, err = io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "MANIFEST_INVALID", err.Error())
+			return
+		}
+
+		// Make sure all referenced blobs were uploaded first.
+		var manifest struct {
+			Config struct {
+				Digest string `json:"digest"`
+			} `json:"config"`
+			Layers []struct {
+				Digest string `json:"digest"`
+			} `json:"layers"`
+		}
+		if err = json.Unmarshal(data, &manifest); err != nil {
+			writeError(w, http.StatusBadRequest, "MANIFEST_INVALID", err.Error())
+			return
+		}
+		var digests = []string{manifest.Config.Digest}
+		for _, layer := range manifest.Layers {
+			digests = append(digests, layer.Digest)
+		}
+		for _, digest := range digests {
+			if _, found := registry.blobs[digest]; !found {
+				writeError(w, http.StatusBadRequest, "MANIFEST_BLOB_UNKNOWN", "blob unknown to registry: "+digest)
+				return
+			}
+		}
+
+		registry.putManifest(repository, reference, data)
+		w.WriteHeader(http.StatusCreated)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "UNSUPPORTED", "unsupported method")
+	}
+}
+
+func (registry *Registry) putManifest(repository string, tag string, data []byte) {
+	if registry.manifests[repository] == nil {
+		registry.manifests[repository] = map[string][]byte{}
+	}
+
+	registry.manifests[repository][tag] = data
+	registry.manifests[repository][getDigest(data)] = data
+}
+
+// paginate returns a page of the sorted values, based on the "n" and "last" query parameters, and sets the "Link" header if there are more pages.
+func paginate(w http.ResponseWriter, r *http.Request, values []string) []string {
+	sort.Strings(values)
+
+	var last = r.URL.Query().Get("last")
+	var start = sort.SearchStrings(values, last)
+	if start < len(values) && values[start] == last {
+		start++
+	}
+	values = values[start:]
+
+	var pageSize, err = strconv.Atoi(r.URL.Query().Get("n"))
+	if err != nil || pageSize <= 0 || pageSize >= len(values) {
+		return values
+	}
+
+	var page = values[:pageSize]
+	var nextQuery = r.URL.Query()
+	nextQuery.Set("last", page[len(page)-1])
+	w.Header().Set("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, r.URL.Path, nextQuery.Encode()))
+
+	return page
+}
+
+func getDigest(data []byte) string {
+	var hash = sha256.Sum256(data)
+
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
+
+func writeJson(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, code string, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"errors": []map[string]string{{"code": code, "message": message}},
+	})
+}
@@ -0,0 +1,14 @@
+package oci
+
+import "strings"
+
+// VersionToTag converts a semantic version into a valid tag.  Tags can't contain "+", so build metadata is separated
+// with "_" instead (e.g. "1.0.0+build.5" becomes "1.0.0_build.5").
+func VersionToTag(version string) string {
+	return strings.ReplaceAll(version, "+", "_")
+}
+
+// TagToVersion is the inverse of VersionToTag.
+func TagToVersion(tag string) string {
+	return strings.ReplaceAll(tag, "_", "+")
+}
@@ -0,0 +1,18 @@
+package oci
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// repositoryNameRegex is the format of repository names from the OCI Distribution specification.
+var repositoryNameRegex = regexp.MustCompile(`^[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*(/[a-z0-9]+((\.|_|__|-+)[a-z0-9]+)*)*$`)
+
+// ValidateRepositoryName validates the name (or namespace prefix) of a repository in a registry.
+func ValidateRepositoryName(repositoryName string) error {
+	if !repositoryNameRegex.MatchString(repositoryName) {
+		return fmt.Errorf("repository names must consist of lowercase path segments separated by forward slashes, where each segment may contain dots, underscores and dashes between letters and digits: %s", repositoryName)
+	}
+
+	return nil
+}
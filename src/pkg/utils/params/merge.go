@@ -0,0 +1,62 @@
+package params
+
+// Merge deep-merges parameters on top of other parameters, and returns the result without modifying either of them.
+// Objects are merged key by key, and any other value (including a list) replaces the value underneath it.
+//
+// A null value in the overriding parameters removes the key.  If "keepNulls" is true, the null value is kept instead, so
+// the key can be removed when the result is later merged on top of the package's default parameters.
+func Merge(base map[string]any, override map[string]any, keepNulls bool) map[string]any {
+	var result = copyMap(base)
+
+	for key, overrideValue := range override {
+		if overrideValue == nil {
+			if keepNulls {
+				result[key] = nil
+			} else {
+				delete(result, key)
+			}
+			continue
+		}
+
+		// Merge objects, so overriding one field in an object doesn't remove the object's other fields
+		var overrideMap, overrideIsMap = overrideValue.(map[string]any)
+		var baseMap, baseIsMap = result[key].(map[string]any)
+		if overrideIsMap {
+			if !baseIsMap {
+				baseMap = map[string]any{}
+			}
+			result[key] = Merge(baseMap, overrideMap, keepNulls)
+			continue
+		}
+
+		result[key] = copyValue(overrideValue)
+	}
+
+	return result
+}
+
+// copyMap returns a deep copy of an object, so it can be modified without affecting the original.
+func copyMap(m map[string]any) map[string]any {
+	var result = make(map[string]any, len(m))
+	for key, value := range m {
+		result[key] = copyValue(value)
+	}
+
+	return result
+}
+
+// copyValue returns a deep copy of a value.  Only objects and lists are copied, since other values can't be modified.
+func copyValue(value any) any {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		return copyMap(typedValue)
+	case []any:
+		var result = make([]any, len(typedValue))
+		for i, item := range typedValue {
+			result[i] = copyValue(item)
+		}
+		return result
+	default:
+		return value
+	}
+}
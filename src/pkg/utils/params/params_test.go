@@ -0,0 +1,154 @@
+package params
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMerge(t *testing.T) {
+	Convey("Given default parameters", t, func() {
+		var defaults = map[string]any{
+			"name":   map[string]any{"first": "Foo", "last": "Bar"},
+			"colors": []any{"red", "green"},
+			"labels": map[string]any{"app": "example", "tier": "web"},
+		}
+
+		Convey("Objects are merged and lists are replaced, without modifying the inputs", func() {
+			var result = Merge(defaults, map[string]any{
+				"name":   map[string]any{"first": "Baz"},
+				"colors": []any{"blue"},
+			}, false)
+
+			So(result, ShouldResemble, map[string]any{
+				"name":   map[string]any{"first": "Baz", "last": "Bar"},
+				"colors": []any{"blue"},
+				"labels": map[string]any{"app": "example", "tier": "web"},
+			})
+			So(defaults["name"], ShouldResemble, map[string]any{"first": "Foo", "last": "Bar"})
+		})
+
+		Convey("Null values remove keys from the defaults", func() {
+			var result = Merge(defaults, map[string]any{
+				"labels": map[string]any{"tier": nil, "extra": map[string]any{"a": nil}},
+			}, false)
+
+			So(result["labels"], ShouldResemble, map[string]any{"app": "example", "extra": map[string]any{}})
+		})
+
+		Convey("Null values are kept when merging user parameters, so they can remove defaults later", func() {
+			var userParameters = Merge(
+				map[string]any{"labels": map[string]any{"tier": "worker"}},
+				map[string]any{"labels": map[string]any{"tier": nil}},
+				true,
+			)
+			So(userParameters["labels"], ShouldResemble, map[string]any{"tier": nil})
+
+			var result = Merge(defaults, userParameters, false)
+			So(result["labels"], ShouldResemble, map[string]any{"app": "example"})
+		})
+	})
+}
+
+func TestOverrides(t *testing.T) {
+	Convey("Typed values are parsed from \"--set\" expressions", t, func() {
+		var overrides, err = ParseSet(`a=true,b=null,c=42,d=1.5,e=007,f=,g=x\,y=z`)
+		So(err, ShouldBeNil)
+
+		var parameters = map[string]any{}
+		So(ApplyOverrides(parameters, overrides), ShouldBeNil)
+		So(parameters, ShouldResemble, map[string]any{
+			"a": true,
+			"b": nil,
+			"c": 42.0,
+			"d": "1.5",
+			"e": "007",
+			"f": "",
+			"g": "x,y=z",
+		})
+	})
+
+	Convey("Nested objects and lists are created along the path", t, func() {
+		var overrides, err = ParseSetString(`a.b[1].c=5,labels.app\.kubernetes\.io/name=foo`)
+		So(err, ShouldBeNil)
+
+		var parameters = map[string]any{"a": map[string]any{"other": "kept"}}
+		So(ApplyOverrides(parameters, overrides), ShouldBeNil)
+		So(parameters, ShouldResemble, map[string]any{
+			"a":      map[string]any{"other": "kept", "b": []any{nil, map[string]any{"c": "5"}}},
+			"labels": map[string]any{"app.kubernetes.io/name": "foo"},
+		})
+	})
+
+	Convey("Files can be used as values", t, func() {
+		var filePath = filepath.Join(t.TempDir(), "cert.pem")
+		So(os.WriteFile(filePath, []byte("contents\n"), 0644), ShouldBeNil)
+
+		var overrides, err = ParseSetFile("tls.cert=" + filePath)
+		So(err, ShouldBeNil)
+
+		var parameters = map[string]any{}
+		So(ApplyOverrides(parameters, overrides), ShouldBeNil)
+		So(parameters["tls"], ShouldResemble, map[string]any{"cert": "contents\n"})
+	})
+
+	Convey("Invalid expressions are rejected", t, func() {
+		for _, expression := range []string{"a", "=1", "a..b=1", "a[x]=1", "a[0=1", "[0]=1", "a[99999999]=1"} {
+			var _, err = ParseSet(expression)
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("Paths which conflict with the existing parameters are rejected", t, func() {
+		var overrides, err = ParseSet("a.b=1")
+		So(err, ShouldBeNil)
+
+		err = ApplyOverrides(map[string]any{"a": []any{1}}, overrides)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "\"a\" is not an object")
+	})
+}
+
+func TestSources(t *testing.T) {
+	Convey("Given parameters which were merged from several layers", t, func() {
+		var defaultsBytes = []byte("name:\n  first: Foo\n  last: Bar\nport: 80\n")
+		var defaults = map[string]any{"name": map[string]any{"first": "Foo", "last": "Bar"}, "port": 80.0}
+		var userFile = map[string]any{"name": map[string]any{"first": "Baz"}}
+		var overrides, err = ParseSet("tags[1]=web")
+		So(err, ShouldBeNil)
+
+		var parameters = Merge(defaults, userFile, false)
+		So(ApplyOverrides(parameters, overrides), ShouldBeNil)
+
+		var sources = GetSources(parameters, []*Layer{
+			NewFileLayer("package default", "parameters.yaml", defaults, defaultsBytes),
+			NewLayer("user file", "values.yaml", userFile),
+			NewOverrideLayer(overrides[0]),
+		})
+
+		Convey("Each leaf value is attributed to the last layer which set it", func() {
+			So(sources, ShouldResemble, map[string]string{
+				".name.first": "user file (values.yaml)",
+				".name.last":  "package default (parameters.yaml:3)",
+				".port":       "package default (parameters.yaml:4)",
+				".tags[0]":    "--set tags[1]",
+				".tags[1]":    "--set tags[1]",
+			})
+		})
+
+		Convey("The sources are written as comments in the YAML", func() {
+			var yamlBytes, err = ToAnnotatedYaml(parameters, sources)
+			So(err, ShouldBeNil)
+			So(string(yamlBytes), ShouldEqual, `name:
+    first: Baz # user file (values.yaml)
+    last: Bar # package default (parameters.yaml:3)
+port: 80 # package default (parameters.yaml:4)
+tags:
+    - null # --set tags[1]
+    - web # --set tags[1]
+`)
+		})
+	})
+}
@@ -0,0 +1,244 @@
+package params
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+)
+
+// Override sets a single parameter, given by its path (e.g. "name.first" or "ports[0].number").
+type Override struct {
+	// Path is the path of the parameter, as it was provided.
+	Path string
+
+	// Flag is the name of the command line flag which the override was parsed from (e.g. "--set").
+	Flag string
+
+	Value any
+
+	segments []*pathSegment
+}
+
+// pathSegment is a part of a parameter's path, which is either the key of a property in an object or an index in a list.
+type pathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// maxListIndex is the highest index that can be set in a list, so a typo can't allocate a huge list.
+const maxListIndex = 65535
+
+// escapableChars are the characters which must be escaped with a backslash to be used literally in an assignment.
+const escapableChars = `\,=.[]`
+
+// integerPattern matches the values which "--set" treats as integers.
+var integerPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+
+// ParseSet parses a comma-separated list of "path=value" assignments, where values are typed.  The values "true" and
+// "false" are booleans, "null" is null (which removes the parameter), integers are numbers and anything else is a string.
+func ParseSet(expression string) ([]*Override, error) {
+	return parseAssignments(expression, "--set", func(value string) (any, error) {
+		switch value {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+
+		// Numbers are parsed from YAML as floats, so integers are stored the same way if they can be
+		if integerPattern.MatchString(value) {
+			if number, err := strconv.ParseInt(value, 10, 64); err == nil && math.Abs(float64(number)) <= 1<<53 {
+				return float64(number), nil
+			}
+		}
+
+		return value, nil
+	})
+}
+
+// ParseSetString parses a comma-separated list of "path=value" assignments, where every value is a string.
+func ParseSetString(expression string) ([]*Override, error) {
+	return parseAssignments(expression, "--set-string", func(value string) (any, error) {
+		return value, nil
+	})
+}
+
+// ParseSetFile parses a comma-separated list of "path=filePath" assignments, where each parameter is set to the contents
+// of the file.
+func ParseSetFile(expression string) ([]*Override, error) {
+	return parseAssignments(expression, "--set-file", func(filePath string) (any, error) {
+		var fileBytes, err = files.ReadBytes(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(fileBytes), nil
+	})
+}
+
+// Apply sets the parameter.  Objects and lists along the path are created if they don't exist, and lists are padded with
+// null values so the index exists.
+func (override *Override) Apply(parameters map[string]any) error {
+	var _, err = setValue(parameters, override.segments, "", override.Value)
+	if err != nil {
+		return fmt.Errorf("failed to set parameter \"%s\": %s", override.Path, err)
+	}
+
+	return nil
+}
+
+// ApplyOverrides sets each of the given parameters in order.
+func ApplyOverrides(parameters map[string]any, overrides []*Override) error {
+	for _, override := range overrides {
+		if err := override.Apply(parameters); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setValue sets the value at the end of a path in an object or list, and returns the updated object or list.
+func setValue(current any, segments []*pathSegment, currentPath string, value any) (result any, err error) {
+	if len(segments) == 0 {
+		return value, nil
+	}
+
+	var segment = segments[0]
+	if segment.isIndex {
+		var list, isList = current.([]any)
+		if current != nil && !isList {
+			return nil, fmt.Errorf("\"%s\" is not a list", currentPath)
+		}
+
+		for len(list) <= segment.index {
+			list = append(list, nil)
+		}
+
+		list[segment.index], err = setValue(list[segment.index], segments[1:], fmt.Sprintf("%s[%d]", currentPath, segment.index), value)
+		return list, err
+	}
+
+	var object, isObject = current.(map[string]any)
+	if current == nil {
+		object = map[string]any{}
+	} else if !isObject {
+		return nil, fmt.Errorf("\"%s\" is not an object", currentPath)
+	}
+
+	var keyPath = segment.key
+	if currentPath != "" {
+		keyPath = currentPath + "." + segment.key
+	}
+
+	object[segment.key], err = setValue(object[segment.key], segments[1:], keyPath, value)
+	return object, err
+}
+
+// parseAssignments splits a comma-separated list of "path=value" assignments, and converts each value with the given
+// function.  Commas, equals signs, dots and square brackets can be escaped with a backslash.
+func parseAssignments(expression string, flag string, parseValue func(value string) (any, error)) (result []*Override, err error) {
+	result = []*Override{}
+	for _, assignment := range splitUnescaped(expression, ',') {
+		var parts = splitUnescaped(assignment, '=')
+		if len(parts) < 2 {
+			return nil, fmt.Errorf("invalid parameter assignment (expected \"path=value\"): %s", assignment)
+		}
+
+		// Only the first equals sign separates the path from the value
+		var rawPath = parts[0]
+		var rawValue = strings.Join(parts[1:], "=")
+
+		var override = &Override{Path: unescape(rawPath), Flag: flag}
+		if override.segments, err = parsePath(rawPath); err != nil {
+			return nil, fmt.Errorf("invalid parameter path \"%s\": %s", override.Path, err)
+		}
+		if override.Value, err = parseValue(unescape(rawValue)); err != nil {
+			return nil, fmt.Errorf("invalid value for parameter \"%s\": %s", override.Path, err)
+		}
+
+		result = append(result, override)
+	}
+
+	return result, nil
+}
+
+// parsePath parses a parameter path such as "a.b[0].c", which may contain escaped characters.
+func parsePath(rawPath string) ([]*pathSegment, error) {
+	var result = []*pathSegment{}
+	for _, rawSegment := range splitUnescaped(rawPath, '.') {
+		// Find the key, which ends at the first unescaped square bracket
+		var keyEnd = len(rawSegment)
+		for i := 0; i < len(rawSegment); i++ {
+			if rawSegment[i] == '\\' {
+				i++
+			} else if rawSegment[i] == '[' {
+				keyEnd = i
+				break
+			}
+		}
+
+		var key = unescape(rawSegment[:keyEnd])
+		if key == "" {
+			return nil, fmt.Errorf("every part of the path must start with a name")
+		}
+		result = append(result, &pathSegment{key: key})
+
+		// Parse the list indexes after the key
+		var indexes = rawSegment[keyEnd:]
+		for indexes != "" {
+			var closeIndex = strings.IndexByte(indexes, ']')
+			if indexes[0] != '[' || closeIndex < 0 {
+				return nil, fmt.Errorf("list indexes must be written as \"[<number>]\"")
+			}
+
+			var index, err = strconv.Atoi(indexes[1:closeIndex])
+			if err != nil || index < 0 || index > maxListIndex {
+				return nil, fmt.Errorf("list indexes must be numbers between 0 and %d: %s", maxListIndex, indexes[1:closeIndex])
+			}
+			result = append(result, &pathSegment{index: index, isIndex: true})
+
+			indexes = indexes[closeIndex+1:]
+		}
+	}
+
+	return result, nil
+}
+
+// splitUnescaped splits a string on a separator which isn't escaped with a backslash, without removing the escapes.
+func splitUnescaped(s string, separator byte) []string {
+	var result = []string{}
+
+	var start = 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == separator {
+			result = append(result, s[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(result, s[start:])
+}
+
+// unescape removes the backslashes which escape special characters.  Other backslashes are kept, so Windows file paths
+// don't need to be escaped.
+func unescape(s string) string {
+	var result = new(strings.Builder)
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && strings.IndexByte(escapableChars, s[i+1]) >= 0 {
+			i++
+		}
+		result.WriteByte(s[i])
+	}
+
+	return result.String()
+}
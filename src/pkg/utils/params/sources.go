@@ -0,0 +1,280 @@
+package params
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layer is a set of parameters from a single source (e.g. a parameters file), which is merged on top of the layers before
+// it.  Layers are used to find out where each of the merged parameters came from.
+type Layer struct {
+	// Description describes where the parameters came from (e.g. "package default").
+	Description string
+
+	// FileName is the name of the file which the parameters were read from, if there is one.
+	FileName string
+
+	// paths is the set of paths of the leaf values in the layer.
+	paths map[string]bool
+
+	// lineNumbers maps the paths of values to the line in the file where they were set, if they are known.
+	lineNumbers map[string]int
+
+	// overridePath is the path which an override sets, since objects and lists along the path are only created so the
+	// value can be set.
+	overridePath string
+}
+
+// identifierPattern matches property names which can be referenced in a template with a dot.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// NewLayer creates a layer for parameters which weren't read directly from a file (e.g. parameters which were generated
+// by a template).
+func NewLayer(description string, fileName string, parameters map[string]any) *Layer {
+	var result = &Layer{Description: description, FileName: fileName, paths: map[string]bool{}, lineNumbers: map[string]int{}}
+	walkLeaves(parameters, "", func(leafPath string, _ any) {
+		result.paths[leafPath] = true
+	})
+
+	return result
+}
+
+// NewFileLayer creates a layer for parameters which were read from a YAML file, so the line number of each parameter can
+// be reported.
+func NewFileLayer(description string, fileName string, parameters map[string]any, fileBytes []byte) *Layer {
+	var result = NewLayer(description, fileName, parameters)
+
+	// Line numbers are only used for reporting, so they are left out if the file can't be parsed
+	var document yaml.Node
+	if yaml.Unmarshal(fileBytes, &document) == nil && len(document.Content) > 0 {
+		recordLineNumbers(document.Content[0], "", result.lineNumbers)
+	}
+
+	return result
+}
+
+// NewOverrideLayer creates a layer for a parameter which was set with an override.
+func NewOverrideLayer(override *Override) *Layer {
+	var parameters = map[string]any{}
+	if _, err := setValue(parameters, override.segments, "", override.Value); err != nil {
+		// The override was already applied to the parameters, so it is valid
+		parameters = map[string]any{}
+	}
+
+	var result = NewLayer(fmt.Sprintf("%s %s", override.Flag, override.Path), "", parameters)
+	result.overridePath = getSegmentsPath(override.segments)
+
+	return result
+}
+
+// GetSources returns a description of where each leaf value in the merged parameters came from, keyed by the path of the
+// value (e.g. ".name.first" or ".ports[0]").  The layers must be in the order that they were merged.
+func GetSources(parameters map[string]any, layers []*Layer) map[string]string {
+	var result = map[string]string{}
+	walkLeaves(parameters, "", func(leafPath string, _ any) {
+		// The last layer which set a value is the one that it came from
+		var sourceLayer *Layer
+		for i := len(layers) - 1; i >= 0 && sourceLayer == nil; i-- {
+			if layers[i].setsPath(leafPath) {
+				sourceLayer = layers[i]
+			}
+		}
+
+		// Null values which pad a list along an override's path aren't set by anything, so blame the layer that added them
+		for i := len(layers) - 1; i >= 0 && sourceLayer == nil; i-- {
+			if layers[i].paths[leafPath] {
+				sourceLayer = layers[i]
+			}
+		}
+
+		if sourceLayer == nil {
+			result[leafPath] = "unknown"
+		} else {
+			result[leafPath] = sourceLayer.describe(leafPath)
+		}
+	})
+
+	return result
+}
+
+// ToAnnotatedYaml converts parameters to YAML, with a comment after each leaf value that describes where it came from.
+// Object keys are sorted.
+func ToAnnotatedYaml(parameters map[string]any, sources map[string]string) ([]byte, error) {
+	var node, err = toAnnotatedNode(parameters, "", sources)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []byte
+	result, err = yaml.Marshal(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize parameters to yaml: %s", err)
+	}
+
+	return result, nil
+}
+
+// setsPath returns true if the layer sets the value at the given path.
+func (layer *Layer) setsPath(valuePath string) bool {
+	if layer.overridePath != "" {
+		return valuePath == layer.overridePath ||
+			strings.HasPrefix(valuePath, layer.overridePath+".") ||
+			strings.HasPrefix(valuePath, layer.overridePath+"[")
+	}
+
+	return layer.paths[valuePath]
+}
+
+// describe returns the description of the layer, including the file and line number where the value at the given path
+// was set if they are known.
+func (layer *Layer) describe(valuePath string) string {
+	if layer.FileName == "" {
+		return layer.Description
+	}
+
+	if lineNumber, found := layer.lineNumbers[valuePath]; found {
+		return fmt.Sprintf("%s (%s:%d)", layer.Description, layer.FileName, lineNumber)
+	}
+
+	return fmt.Sprintf("%s (%s)", layer.Description, layer.FileName)
+}
+
+// walkLeaves calls the visitor for every value in an object or list which isn't a non-empty object or list.
+func walkLeaves(value any, valuePath string, visit func(leafPath string, leafValue any)) {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		if len(typedValue) == 0 && valuePath != "" {
+			visit(valuePath, value)
+		}
+		for key, childValue := range typedValue {
+			walkLeaves(childValue, getPropertyPath(valuePath, key), visit)
+		}
+	case []any:
+		if len(typedValue) == 0 {
+			visit(valuePath, value)
+		}
+		for i, item := range typedValue {
+			walkLeaves(item, getIndexPath(valuePath, i), visit)
+		}
+	default:
+		visit(valuePath, value)
+	}
+}
+
+// recordLineNumbers records the line where each value in a YAML node is set.
+func recordLineNumbers(node *yaml.Node, nodePath string, lineNumbers map[string]int) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			var keyNode, valueNode = node.Content[i], node.Content[i+1]
+
+			// Merge keys add the values from another object, but the values which are set directly take priority
+			if keyNode.Tag == "!!merge" {
+				var mergedLineNumbers = map[string]int{}
+				recordLineNumbers(valueNode, nodePath, mergedLineNumbers)
+				for mergedPath, lineNumber := range mergedLineNumbers {
+					if _, found := lineNumbers[mergedPath]; !found {
+						lineNumbers[mergedPath] = lineNumber
+					}
+				}
+				continue
+			}
+
+			var childPath = getPropertyPath(nodePath, keyNode.Value)
+			lineNumbers[childPath] = keyNode.Line
+			recordLineNumbers(valueNode, childPath, lineNumbers)
+		}
+	case yaml.SequenceNode:
+		for i, itemNode := range node.Content {
+			var childPath = getIndexPath(nodePath, i)
+			lineNumbers[childPath] = itemNode.Line
+			recordLineNumbers(itemNode, childPath, lineNumbers)
+		}
+	}
+}
+
+// toAnnotatedNode converts a value to a YAML node, adding the sources of leaf values as comments.
+func toAnnotatedNode(value any, valuePath string, sources map[string]string) (result *yaml.Node, err error) {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		result = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		if len(typedValue) == 0 {
+			result.Style = yaml.FlowStyle
+		}
+
+		var keys = make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			var valueNode *yaml.Node
+			if valueNode, err = toAnnotatedNode(typedValue[key], getPropertyPath(valuePath, key), sources); err != nil {
+				return nil, err
+			}
+			result.Content = append(result.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, valueNode)
+		}
+	case []any:
+		result = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if len(typedValue) == 0 {
+			result.Style = yaml.FlowStyle
+		}
+
+		for i, item := range typedValue {
+			var itemNode *yaml.Node
+			if itemNode, err = toAnnotatedNode(item, getIndexPath(valuePath, i), sources); err != nil {
+				return nil, err
+			}
+			result.Content = append(result.Content, itemNode)
+		}
+	default:
+		result = new(yaml.Node)
+		if err = result.Encode(value); err != nil {
+			return nil, fmt.Errorf("failed to serialize value at \"%s\" to yaml: %s", valuePath, err)
+		}
+	}
+
+	if source, found := sources[valuePath]; found {
+		result.LineComment = source
+	}
+
+	return result, nil
+}
+
+// getSegmentsPath returns the path of a parsed parameter path, in the same form as the paths of values in layers.
+func getSegmentsPath(segments []*pathSegment) string {
+	var result = ""
+	for _, segment := range segments {
+		if segment.isIndex {
+			result = getIndexPath(result, segment.index)
+		} else {
+			result = getPropertyPath(result, segment.key)
+		}
+	}
+
+	return result
+}
+
+// getPropertyPath returns the path of a property in an object.
+func getPropertyPath(objectPath string, propertyName string) string {
+	if identifierPattern.MatchString(propertyName) {
+		return objectPath + "." + propertyName
+	}
+
+	return fmt.Sprintf("%s[%s]", objectPath, strconv.Quote(propertyName))
+}
+
+// getIndexPath returns the path of an item in a list.
+func getIndexPath(listPath string, index int) string {
+	return fmt.Sprintf("%s[%d]", listPath, index)
+}
@@ -2,6 +2,7 @@ package template_package
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -9,8 +10,8 @@ import (
 
 	"github.com/emirpasic/gods/maps/linkedhashmap"
 	"github.com/emirpasic/gods/stacks/linkedliststack"
-	"golang.org/x/exp/slices"
 
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
 	"github.com/rohitramu/kpm/src/pkg/utils/log"
 	"github.com/rohitramu/kpm/src/pkg/utils/templates"
 	"github.com/rohitramu/kpm/src/pkg/utils/validation"
@@ -28,11 +29,13 @@ type dependencyTreeNode struct {
 	Children []*dependencyTreeNode
 
 	packageDefinition *PackageDefinition
+	versionConstraint string
 	hash              *string
 
 	OutputName          string
 	PackageDirPath      string
-	ExecutableTemplates []*template.Template
+	ExecutableTemplates []*ExecutableTemplate
+	StaticFiles         []*OutputFile
 	TemplateInput       *map[string]any
 }
 
@@ -41,7 +44,8 @@ func (tree *DependencyTree) VisitNodesDepthFirst(
 	consumeNode func(
 		relativeFilePath []string,
 		friendlyNamePath []string,
-		executableTemplates []*template.Template,
+		executableTemplates []*ExecutableTemplate,
+		staticFiles []*OutputFile,
 		templateInput *map[string]any,
 	) error,
 ) (int, error) {
@@ -115,7 +119,7 @@ func (tree *DependencyTree) VisitNodesDepthFirst(
 		}
 
 		// Call the consuming function
-		if err = consumeNode(relativeFilePath, friendlyNamePath, node.ExecutableTemplates, node.TemplateInput); err != nil {
+		if err = consumeNode(relativeFilePath, friendlyNamePath, node.ExecutableTemplates, node.StaticFiles, node.TemplateInput); err != nil {
 			return 0, err
 		}
 
@@ -128,13 +132,31 @@ func (tree *DependencyTree) VisitNodesDepthFirst(
 	return numVisitedNodes, nil
 }
 
-// GetDependencyTree ensures that the dependency tree has no loops and then returns the dependency tree.
+// PackageFetcher makes packages which are missing from the KPM home directory available (e.g. by pulling them from repositories).
+type PackageFetcher interface {
+	// GetPackageVersions returns the versions of a package which can be fetched.  If only some of the versions could be
+	// found, it returns them along with an error.
+	GetPackageVersions(packageName string) ([]string, error)
+
+	// FetchPackage makes a package version available in the KPM home directory.
+	FetchPackage(packageInfo *PackageInfo) error
+}
+
+// GetDependencyTree ensures that the dependency tree has no loops and then returns the dependency tree.  If a package
+// is missing from the KPM home directory, it is fetched with the given fetcher (if it is not nil).  Version constraints
+// in dependency definitions are resolved to the version in the locked output (if it is not nil and the version satisfies
+// the constraint), or otherwise the highest available version (prereleases are only used if "includePrerelease" is true).
+// If "coverage" is not nil, the blocks of each package's template files which are executed are recorded in it.
 func GetDependencyTree(
 	kpmHomeDir string,
 	packageName string,
 	packageVersion string,
 	outputName string,
 	parameters *map[string]any,
+	lockedOutput *LockedOutput,
+	fetcher PackageFetcher,
+	includePrerelease bool,
+	coverage *templates.Coverage,
 ) (*DependencyTree, error) {
 	var err error
 	var ok bool
@@ -189,6 +211,25 @@ func GetDependencyTree(
 			return nil, fmt.Errorf("invalid name for package \"%s\": %s", currentOutputName, err)
 		}
 
+		// Resolve the version constraint if an exact version wasn't provided
+		if validation.ValidatePackageVersion(currentPackageVersion) != nil {
+			var lockedVersion string
+			if lockedNode, found := lockedOutput.GetNode(currentNode.getPath()); found && lockedNode.Name == currentPackageName {
+				lockedVersion = lockedNode.Version
+			}
+
+			var resolvedVersion string
+			resolvedVersion, err = ResolvePackageVersion(kpmHomeDir, currentPackageName, currentPackageVersion, lockedVersion, fetcher, includePrerelease)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version for package \"%s\": %s", currentOutputName, err)
+			}
+
+			log.Infof("Resolved version of package \"%s\" (%s@%s): %s", currentOutputName, currentPackageName, currentPackageVersion, resolvedVersion)
+			currentNode.versionConstraint = currentPackageVersion
+			currentNode.packageDefinition.PackageInfo.Version = resolvedVersion
+			currentPackageVersion = resolvedVersion
+		}
+
 		// Validate package version
 		err = validation.ValidatePackageVersion(currentPackageVersion)
 		if err != nil {
@@ -204,14 +245,16 @@ func GetDependencyTree(
 			return nil, fmt.Errorf("output was not provided any parameters: %s", friendlyName)
 		}
 
-		// Check local repository for package
-		var packages []string
-		packages, err = GetPackageFullNamesFromLocalRepository(kpmHomeDir)
-		if err != nil {
-			return nil, err
-		}
-		if !slices.Contains(packages, currentPackageFullName) {
-			return nil, fmt.Errorf("failed to get package \"%s\": %s", currentPackageFullName, err)
+		// Check local repository for package, and fetch it if it is missing
+		if !packageExistsInLocalRepository(kpmHomeDir, currentPackageFullName) {
+			if fetcher == nil {
+				return nil, fmt.Errorf("failed to get package \"%s\": it is not in the local KPM repository", currentPackageFullName)
+			}
+
+			log.Infof("Fetching missing package: %s", currentPackageFullName)
+			if err = fetcher.FetchPackage(currentNode.packageDefinition.PackageInfo); err != nil {
+				return nil, fmt.Errorf("failed to fetch package \"%s\": %s", currentPackageFullName, err)
+			}
 		}
 
 		// Create a function to easily get the human readable path
@@ -256,7 +299,7 @@ func GetDependencyTree(
 
 		// Create shared template (with common options, functions and helper templates for this package)
 		var sharedTemplate *template.Template
-		sharedTemplate, err = GetSharedTemplate(currentPackageDirPath)
+		sharedTemplate, err = GetSharedTemplate(currentPackageDirPath, coverage)
 		if err != nil {
 			return nil, fmt.Errorf("failed to construct shared template in package: %s\n%s", getFriendlyPath(), err)
 		}
@@ -268,6 +311,7 @@ func GetDependencyTree(
 			currentPackageFullName,
 			sharedTemplate,
 			currentParameters,
+			coverage,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get template input in package: %s\n%s", getFriendlyPath(), err)
@@ -275,7 +319,7 @@ func GetDependencyTree(
 
 		// Get the dependency definition templates
 		var dependencyTemplates []*template.Template
-		dependencyTemplates, err = GetDependencyDefinitionTemplates(sharedTemplate, currentPackageDirPath)
+		dependencyTemplates, err = GetDependencyDefinitionTemplates(sharedTemplate, currentPackageDirPath, coverage)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get dependency definition templates in package: %s\n%s", getFriendlyPath(), err)
 		}
@@ -283,10 +327,14 @@ func GetDependencyTree(
 		// Save the package directory path, shared template and calculated values that can be used with this package in the node
 		currentNode.PackageDirPath = currentPackageDirPath
 		currentNode.TemplateInput = templateInput
-		currentNode.ExecutableTemplates, err = GetExecutableTemplates(sharedTemplate, currentPackageDirPath)
+		currentNode.ExecutableTemplates, err = GetExecutableTemplates(sharedTemplate, currentPackageDirPath, coverage)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get executable templates in package: %s\n%s", getFriendlyPath(), err)
 		}
+		currentNode.StaticFiles, err = GetStaticFiles(currentPackageDirPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get static files in package: %s\n%s", getFriendlyPath(), err)
+		}
 
 		// Check if there is a loop in the dependency tree
 		var currentNodeHash = currentNode.getPackageNodeHash()
@@ -394,6 +442,22 @@ func GetDependencyTree(
 	return tree, nil
 }
 
+// packageExistsInLocalRepository checks whether a valid package with the given full name is in the local KPM repository.
+func packageExistsInLocalRepository(kpmHomeDir string, packageFullName string) bool {
+	var packageDir = GetPackageDir(kpmHomeDir, packageFullName)
+	if files.DirExists(packageDir, "template package") != nil {
+		return false
+	}
+
+	var packageInfo, err = GetPackageInfo(packageDir)
+	if err != nil {
+		log.Warningf("Found invalid package '%s': %s", packageDir, err)
+		return false
+	}
+
+	return GetPackageFullName(packageInfo.Name, packageInfo.Version) == packageFullName
+}
+
 func getPackageNode(
 	parentNode *dependencyTreeNode,
 	packageDefinition *PackageDefinition,
@@ -426,6 +490,15 @@ func getPackageNode(
 	return packageNode, nil
 }
 
+// getPath returns the output path of the node, relative to the output directory.
+func (node *dependencyTreeNode) getPath() string {
+	if node.Parent == nil {
+		return node.OutputName
+	}
+
+	return path.Join(node.Parent.getPath(), node.OutputName)
+}
+
 func (node *dependencyTreeNode) getPackageNodeHash() string {
 	var err error
 
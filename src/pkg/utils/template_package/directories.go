@@ -60,6 +60,13 @@ func GetHelpersDir(packageDir string) string {
 	return helpersDirPath
 }
 
+// GetStaticFilesDir returns the path of the static files directory in a template package.
+func GetStaticFilesDir(packageDir string) string {
+	var staticFilesDirPath = filepath.Join(packageDir, constants.StaticFilesDirName)
+
+	return staticFilesDirPath
+}
+
 // GetTemplatesDir returns the path of the templates directory in a template package.
 func GetTemplatesDir(packageDir string) string {
 	var templatesDirPath = filepath.Join(packageDir, constants.TemplatesDirName)
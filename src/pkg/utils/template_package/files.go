@@ -5,6 +5,7 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/emirpasic/gods/sets/treeset"
@@ -12,6 +13,7 @@ import (
 	"github.com/rohitramu/kpm/src/pkg/utils/constants"
 	"github.com/rohitramu/kpm/src/pkg/utils/files"
 	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/params"
 	"github.com/rohitramu/kpm/src/pkg/utils/templates"
 	"github.com/rohitramu/kpm/src/pkg/utils/validation"
 	"github.com/rohitramu/kpm/src/pkg/utils/yaml"
@@ -45,12 +47,14 @@ func GetPackageFullName(packageName string, packageVersion string) string {
 	return fmt.Sprintf("%s-%s", packageName, packageVersion)
 }
 
-// GetTemplateInput creates the input values for a template by combining the interface, parameters and package info.
+// GetTemplateInput creates the input values for a template by combining the interface, parameters and package info.  If
+// "coverage" is not nil, the blocks of the interface which are executed are recorded in it.
 func GetTemplateInput(
 	kpmHomeDir string,
 	packageFullName string,
 	parentTemplate *template.Template,
 	parameters *map[string]any,
+	coverage *templates.Coverage,
 ) (*map[string]any, error) {
 	var err error
 
@@ -68,25 +72,21 @@ func GetTemplateInput(
 	packageInfoMap["version"] = packageInfo.Version
 	result[constants.TemplateFieldPackage] = &packageInfoMap
 
-	// Get the default values
+	// Combine the default values with the provided parameters
 	var inputParameters *map[string]any
-	inputParameters, err = GetPackageParameters(GetDefaultParametersFile(packageDir))
+	inputParameters, err = getMergedParameters(packageDir, parameters)
 	if err != nil {
 		return nil, err
 	}
 
-	// If the file didn't exist, create an empty map
-	if inputParameters == nil {
-		inputParameters = new(map[string]any)
-	}
-
-	// Allow default values to be overridden by the provided parameters
-	for key := range *parameters {
-		(*inputParameters)[key] = (*parameters)[key]
+	// Make sure that the parameters are valid before the interface uses them
+	err = validateParameters(packageDir, inputParameters)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters for package: %s\n%s", packageFullName, err)
 	}
 
 	// Add values
-	result[constants.TemplateFieldValues], err = getValuesFromInterface(parentTemplate, packageDir, inputParameters)
+	result[constants.TemplateFieldValues], err = getValuesFromInterface(parentTemplate, packageDir, inputParameters, coverage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate values from the interface in package: %s\n%s", packageFullName, err)
 	}
@@ -94,9 +94,27 @@ func GetTemplateInput(
 	return &result, nil
 }
 
+// getMergedParameters returns a package's default parameters, overridden by the provided parameters.  Objects are merged
+// and null values remove defaults.
+func getMergedParameters(packageDir string, parameters *map[string]any) (*map[string]any, error) {
+	var defaultParameters, err = GetPackageParameters(GetDefaultParametersFile(packageDir))
+	if err != nil {
+		return nil, err
+	}
+
+	// If the file was empty, there are no defaults
+	if defaultParameters == nil || *defaultParameters == nil {
+		defaultParameters = &map[string]any{}
+	}
+
+	var result = params.Merge(*defaultParameters, *parameters, false)
+	return &result, nil
+}
+
 // GetSharedTemplate creates a template which contains default options, functions and
-// helper template definitions defined in the given package.
-func GetSharedTemplate(packageDir string) (*template.Template, error) {
+// helper template definitions defined in the given package.  If "coverage" is not nil, the blocks of the helper
+// templates which are executed are recorded in it.
+func GetSharedTemplate(packageDir string, coverage *templates.Coverage) (*template.Template, error) {
 	var err error
 
 	// Get the directory which contains the helper templates
@@ -108,7 +126,7 @@ func GetSharedTemplate(packageDir string) (*template.Template, error) {
 	// Create a template which includes the helper template definitions
 	if files.DirExists(helpersDir, "helpers") == nil {
 		var numHelpers int
-		sharedTemplate, numHelpers, err = templates.ChainTemplatesFromDir(sharedTemplate, helpersDir)
+		sharedTemplate, numHelpers, err = templates.ChainTemplatesFromDir(sharedTemplate, helpersDir, coverage)
 		if err != nil {
 			return nil, err
 		}
@@ -117,21 +135,15 @@ func GetSharedTemplate(packageDir string) (*template.Template, error) {
 	}
 
 	// Add the package-specific template functions
-	sharedTemplate = templates.AddPackageSpecificTemplateFunctions(sharedTemplate)
+	sharedTemplate = templates.AddPackageSpecificTemplateFunctions(sharedTemplate, packageDir)
 
 	return sharedTemplate, nil
 }
 
-// GetPackageInfo validates the package directory and returns the package info object for a given package.
-func GetPackageInfo(packageDirAbsPath string) (*PackageInfo, error) {
+// readPackageInfoFile reads the package information file in a package, without validating it.
+func readPackageInfoFile(packageDirAbsPath string) (*packageInfoFileContent, error) {
 	var err error
 
-	// Make sure that the package exists
-	err = files.DirExists(packageDirAbsPath, "package")
-	if err != nil {
-		return nil, err
-	}
-
 	// Check that the package info file exists
 	var packageInfoFile = GetPackageInfoFile(packageDirAbsPath)
 	err = files.FileExists(packageInfoFile, "template package information")
@@ -147,12 +159,33 @@ func GetPackageInfo(packageDirAbsPath string) (*PackageInfo, error) {
 	}
 
 	// Get package info object from file content
-	var packageInfo = new(PackageInfo)
-	err = yaml.BytesToObject(yamlBytes, packageInfo)
+	var result = new(packageInfoFileContent)
+	err = yaml.BytesToObject(yamlBytes, result)
 	if err != nil {
 		return nil, fmt.Errorf("invalid package information file: %s\n%s", packageInfoFile, err)
 	}
 
+	return result, nil
+}
+
+// GetPackageInfo validates the package directory and returns the package info object for a given package.
+func GetPackageInfo(packageDirAbsPath string) (*PackageInfo, error) {
+	var err error
+
+	// Make sure that the package exists
+	err = files.DirExists(packageDirAbsPath, "package")
+	if err != nil {
+		return nil, err
+	}
+
+	// Get package info object from the package info file
+	var fileContent *packageInfoFileContent
+	fileContent, err = readPackageInfoFile(packageDirAbsPath)
+	if err != nil {
+		return nil, err
+	}
+	var packageInfo = &fileContent.PackageInfo
+
 	// Validate package name
 	var packageName = packageInfo.Name
 	err = validation.ValidatePackageName(packageName)
@@ -181,25 +214,16 @@ func GetPackageInfo(packageDirAbsPath string) (*PackageInfo, error) {
 		return nil, err
 	}
 
-	// Validate the templates directory if it exists
-	var templatesDir = GetTemplatesDir(packageDirAbsPath)
-	if files.DirExists(templatesDir, "templates") == nil {
-		var fileInfos []os.DirEntry
-		fileInfos, err = os.ReadDir(templatesDir)
-		if err != nil {
-			// We already checked that this directory exists, so we shouldn't ever get to here
-			log.Panicf("Failed to read directory: %s\n%s", templatesDir, err)
-		}
-
-		for _, fileInfo := range fileInfos {
-			// Get file name
-			var fileName = fileInfo.Name()
+	// Make sure that the parameters schema is valid if it exists
+	_, err = GetParametersSchema(packageDirAbsPath)
+	if err != nil {
+		return nil, err
+	}
 
-			// Don't allow directories
-			if fileInfo.IsDir() {
-				return nil, fmt.Errorf("directories are not allowed in the \"%s\" directory: %s", constants.TemplatesDirName, fileName)
-			}
-		}
+	// Validate the static files directory if it exists
+	err = validateStaticFiles(packageDirAbsPath, fileContent.Files)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate the helpers directory if it exists
@@ -288,8 +312,10 @@ func GetPackageParameters(parametersFile string) (*map[string]any, error) {
 	return parameters, nil
 }
 
-// GetExecutableTemplates returns all executable templates in a template package.
-func GetExecutableTemplates(parentTemplate *template.Template, packageDir string) ([]*template.Template, error) {
+// GetExecutableTemplates returns all executable templates in a template package, including those in sub-directories of
+// the templates directory.  Each template is named by its path relative to the templates directory.  If "coverage" is not
+// nil, the blocks of the templates which are executed are recorded in it.
+func GetExecutableTemplates(parentTemplate *template.Template, packageDir string, coverage *templates.Coverage) ([]*ExecutableTemplate, error) {
 	var err error
 
 	// Get the templates directory
@@ -297,22 +323,35 @@ func GetExecutableTemplates(parentTemplate *template.Template, packageDir string
 
 	// If the templates directory doesn't exist, just return a list of no templates instead of erroring out
 	if files.DirExists(executableTemplatesDir, "templates") != nil {
-		return []*template.Template{}, nil
+		return []*ExecutableTemplate{}, nil
 	}
 
-	// Return the templates in the directory
+	// Get the templates in the directory and its sub-directories
 	log.Debugf("Found template directory: %s", executableTemplatesDir)
-	var result []*template.Template
-	result, err = templates.GetTemplatesFromDir(parentTemplate, executableTemplatesDir)
+	var parsedTemplates []*template.Template
+	parsedTemplates, err = templates.GetTemplatesFromDirRecursive(parentTemplate, executableTemplatesDir, coverage)
 	if err != nil {
 		return nil, err
 	}
 
+	// Read the front matter of each template
+	var result = make([]*ExecutableTemplate, 0, len(parsedTemplates))
+	for _, tmpl := range parsedTemplates {
+		var executableTemplate *ExecutableTemplate
+		executableTemplate, err = NewExecutableTemplate(tmpl, filepath.Join(executableTemplatesDir, filepath.FromSlash(tmpl.Name())))
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, executableTemplate)
+	}
+
 	return result, nil
 }
 
 // GetDependencyDefinitionTemplates returns the templates for all dependency definition templates in a template package.
-func GetDependencyDefinitionTemplates(parentTemplate *template.Template, packageDir string) ([]*template.Template, error) {
+// If "coverage" is not nil, the blocks of the templates which are executed are recorded in it.
+func GetDependencyDefinitionTemplates(parentTemplate *template.Template, packageDir string, coverage *templates.Coverage) ([]*template.Template, error) {
 	var err error
 
 	// Get the dependencies directory
@@ -324,7 +363,7 @@ func GetDependencyDefinitionTemplates(parentTemplate *template.Template, package
 	}
 
 	var dependencyTemplates []*template.Template
-	dependencyTemplates, err = templates.GetTemplatesFromDir(parentTemplate, dependenciesDir)
+	dependencyTemplates, err = templates.GetTemplatesFromDir(parentTemplate, dependenciesDir, coverage)
 	if err != nil {
 		return nil, err
 	}
@@ -337,13 +376,14 @@ func getValuesFromInterface(
 	parentTemplate *template.Template,
 	packageDir string,
 	parameters *map[string]any,
+	coverage *templates.Coverage,
 ) (*map[string]any, error) {
 	var err error
 
 	// Create template object from interface file
 	var interfaceFile = GetInterfaceFile(packageDir)
 	var tmpl *template.Template
-	tmpl, err = templates.GetTemplateFromFile(parentTemplate, filepath.Base(interfaceFile), interfaceFile)
+	tmpl, err = templates.GetTemplateFromFile(parentTemplate, filepath.Base(interfaceFile), interfaceFile, coverage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get interface file: %s\n%s", packageDir, err)
 	}
@@ -365,7 +405,8 @@ func getValuesFromInterface(
 	return result, nil
 }
 
-// GetPackageFullNamesFromLocalRepository returns the list of package names in a local package repository, in alphabetical order.
+// GetPackageFullNamesFromLocalRepository returns the list of package names in a local package repository, ordered by
+// package name and then by version precedence.
 func GetPackageFullNamesFromLocalRepository(repoDir string) ([]string, error) {
 	var err error
 	var ok bool
@@ -386,7 +427,7 @@ func GetPackageFullNamesFromLocalRepository(repoDir string) ([]string, error) {
 	}
 
 	// Traverse the packages directory.
-	var packages = treeset.NewWithStringComparator()
+	var packages = treeset.NewWith(comparePackageFullNames)
 	for _, namespaceDirEntry := range namespaceDirEntries {
 		var namespaceDirAbsPath = filepath.Join(packagesDir, namespaceDirEntry.Name())
 
@@ -424,9 +465,11 @@ func GetPackageFullNamesFromLocalRepository(repoDir string) ([]string, error) {
 			// We always expect forward slashes.
 			expectedPackageFullName = filepath.ToSlash(expectedPackageFullName)
 
-			// Skip files.
+			// Skip files (package archives are expected, so don't warn about them).
 			if !packageDirEntry.IsDir() {
-				log.Warningf("Found a file in the packages directory: %s", packageAbsPath)
+				if !strings.HasSuffix(packageDirEntry.Name(), constants.PackageArchiveExtension) {
+					log.Warningf("Found a file in the packages directory: %s", packageAbsPath)
+				}
 				continue
 			}
 
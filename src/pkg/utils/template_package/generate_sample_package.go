@@ -69,6 +69,27 @@ name:
 `, constants.ParametersFileName),
 		},
 
+		// Parameters schema
+		path.Join(packageAbsolutePath, constants.ParametersSchemaFileName): {
+			"parameters schema",
+			`{
+  "$schema": "https://json-schema.org/draft/2020-12/schema",
+  "type": "object",
+  "required": ["name"],
+  "properties": {
+    "name": {
+      "type": "object",
+      "required": ["first", "last"],
+      "properties": {
+        "first": { "type": "string", "minLength": 1 },
+        "last": { "type": "string", "minLength": 1 }
+      }
+    }
+  }
+}
+`,
+		},
+
 		// Template
 		path.Join(packageAbsolutePath, constants.TemplatesDirName, "hello.txt"): {
 			"\"hello\" template",
@@ -0,0 +1,403 @@
+package template_package
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	yamlv3 "gopkg.in/yaml.v3"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/templates"
+)
+
+// Diagnostic is a problem which was found in a file in a template package.
+type Diagnostic struct {
+	// FilePath is the path of the file, relative to the package directory.
+	FilePath string
+
+	// Line is the line in the file where the problem is, or 0 if it isn't known.
+	Line int
+
+	Message string
+}
+
+func (diagnostic *Diagnostic) String() string {
+	if diagnostic.Line <= 0 {
+		return fmt.Sprintf("%s: %s", diagnostic.FilePath, diagnostic.Message)
+	}
+
+	return fmt.Sprintf("%s:%d: %s", diagnostic.FilePath, diagnostic.Line, diagnostic.Message)
+}
+
+// lintedFile is a template file in a package which has been parsed and analyzed.
+type lintedFile struct {
+	// relativePath is the path of the file relative to the package directory.
+	relativePath string
+
+	// templateName is the name that the template has when the package is executed.
+	templateName string
+
+	// isHelper is true if the file is in the helpers directory.
+	isHelper bool
+
+	// usesTemplateInput is true if the template is executed with the template input (i.e. ".package" and ".values"),
+	// rather than the parameters.
+	usesTemplateInput bool
+
+	// analysis is nil if the file failed to parse.
+	analysis *templates.TemplateAnalysis
+}
+
+// interfaceKeyRegex matches the top-level keys which are written in the interface file.
+var interfaceKeyRegex = regexp.MustCompile(`(?m)^([A-Za-z_][A-Za-z0-9_-]*)\s*:`)
+
+// LintPackage checks a template package for problems which would otherwise only be found when it is executed (or not at
+// all).  Every template file is parsed with the same functions that are available when the package is executed, and
+// checked for:
+//   - references to values which the interface doesn't produce
+//   - default parameters which the interface doesn't read
+//   - calls to templates which aren't defined
+//   - templates which are defined more than once
+//
+// The returned diagnostics are sorted by file and line.  An error is only returned if the package is invalid.
+func LintPackage(packageDir string) ([]*Diagnostic, error) {
+	var err error
+
+	// Make sure that the package has a valid layout before looking inside the files
+	if _, err = GetPackageInfo(packageDir); err != nil {
+		return nil, err
+	}
+
+	var diagnostics = []*Diagnostic{}
+	var addDiagnostic = func(relativePath string, line int, format string, args ...any) {
+		diagnostics = append(diagnostics, &Diagnostic{FilePath: relativePath, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Parse the template files, with the helpers first since they are defined first when the package is executed
+	var lintedFiles []*lintedFile
+	lintedFiles, err = getLintedFiles(packageDir, addDiagnostic)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the templates which are defined, and make sure that each one is only defined once
+	var definitions = map[string]string{}
+	for _, file := range lintedFiles {
+		definitions[file.templateName] = file.relativePath
+	}
+	for _, file := range lintedFiles {
+		if file.analysis == nil {
+			continue
+		}
+
+		for _, definition := range file.analysis.Definitions {
+			var location = fmt.Sprintf("%s:%d", file.relativePath, definition.Line)
+			if existingLocation, found := definitions[definition.Name]; found {
+				addDiagnostic(file.relativePath, definition.Line, "template \"%s\" is already defined in %s", definition.Name, existingLocation)
+				continue
+			}
+			definitions[definition.Name] = location
+		}
+	}
+
+	// Make sure that every template which is called is defined
+	for _, file := range lintedFiles {
+		if file.analysis == nil {
+			continue
+		}
+
+		for _, reference := range file.analysis.TemplateReferences {
+			if _, found := definitions[reference.Name]; !found {
+				addDiagnostic(file.relativePath, reference.Line, "template \"%s\" is not defined", reference.Name)
+			}
+		}
+	}
+
+	// Make sure that the values which are used are produced by the interface
+	var producedValues map[string]bool
+	producedValues, err = getProducedValues(packageDir, addDiagnostic)
+	if err != nil {
+		return nil, err
+	}
+	if producedValues != nil {
+		for _, file := range lintedFiles {
+			if file.analysis == nil || !file.usesTemplateInput {
+				continue
+			}
+
+			for _, reference := range file.analysis.FieldReferences {
+				if len(reference.Fields) < 2 || reference.Fields[0] != constants.TemplateFieldValues {
+					continue
+				}
+				if !producedValues[reference.Fields[1]] {
+					addDiagnostic(file.relativePath, reference.Line, "\".%s.%s\" is not produced by the interface", constants.TemplateFieldValues, reference.Fields[1])
+				}
+			}
+		}
+	}
+
+	// Make sure that the interface reads each of the default parameters
+	for _, file := range lintedFiles {
+		if file.templateName == constants.InterfaceFileName && file.analysis != nil {
+			if err = lintParameters(packageDir, file.analysis, addDiagnostic); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].FilePath != diagnostics[j].FilePath {
+			return diagnostics[i].FilePath < diagnostics[j].FilePath
+		}
+		return diagnostics[i].Line < diagnostics[j].Line
+	})
+
+	return diagnostics, nil
+}
+
+// getLintedFiles parses and analyzes all of the template files in a package.  Parse errors are reported as diagnostics.
+func getLintedFiles(
+	packageDir string,
+	addDiagnostic func(relativePath string, line int, format string, args ...any),
+) (result []*lintedFile, err error) {
+	result = []*lintedFile{}
+
+	// Helpers and dependency definitions are only read from the top level of their directories
+	var addFilesInDir = func(dirPath string, isHelper bool) error {
+		if files.DirExists(dirPath, "template") != nil {
+			return nil
+		}
+
+		var entries, err = os.ReadDir(dirPath)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			result = append(result, &lintedFile{
+				relativePath:      path.Join(filepath.Base(dirPath), entry.Name()),
+				templateName:      entry.Name(),
+				isHelper:          isHelper,
+				usesTemplateInput: true,
+			})
+		}
+
+		return nil
+	}
+
+	if err = addFilesInDir(GetHelpersDir(packageDir), true); err != nil {
+		return nil, err
+	}
+
+	result = append(result, &lintedFile{
+		relativePath: constants.InterfaceFileName,
+		templateName: constants.InterfaceFileName,
+	})
+
+	// Executable templates may be in sub-directories
+	var templatesDir = GetTemplatesDir(packageDir)
+	if files.DirExists(templatesDir, "templates") == nil {
+		err = filepath.WalkDir(templatesDir, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return err
+			}
+
+			var relativePath string
+			if relativePath, err = filepath.Rel(templatesDir, filePath); err != nil {
+				return err
+			}
+			relativePath = filepath.ToSlash(relativePath)
+
+			result = append(result, &lintedFile{
+				relativePath:      path.Join(constants.TemplatesDirName, relativePath),
+				templateName:      relativePath,
+				usesTemplateInput: true,
+			})
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = addFilesInDir(GetDependenciesDir(packageDir), false); err != nil {
+		return nil, err
+	}
+
+	// Parse each file
+	for _, file := range result {
+		var templateString string
+		templateString, err = files.ReadString(filepath.Join(packageDir, filepath.FromSlash(file.relativePath)))
+		if err != nil {
+			return nil, err
+		}
+
+		var tmpl *template.Template
+		tmpl, err = templates.GetTemplateFromString(templates.NewRootTemplate(), file.templateName, templateString)
+		if err != nil {
+			var line, message = templates.GetErrorLine(err)
+			addDiagnostic(file.relativePath, line, "failed to parse template: %s", message)
+			continue
+		}
+		file.analysis = templates.AnalyzeTemplate(tmpl)
+
+		// The front matter of executable templates also contains templates
+		if strings.HasPrefix(file.relativePath, constants.TemplatesDirName+"/") {
+			lintFrontMatter(file, templateString, addDiagnostic)
+		}
+	}
+
+	return result, nil
+}
+
+// lintFrontMatter parses the templates in an executable template's front matter, and adds what they use to the file's
+// analysis.  Since the front matter is a comment, problems are reported on the line where it starts.
+func lintFrontMatter(
+	file *lintedFile,
+	templateString string,
+	addDiagnostic func(relativePath string, line int, format string, args ...any),
+) {
+	var line = strings.Count(templateString[:len(templateString)-len(strings.TrimLeft(templateString, " \t\r\n"))], "\n") + 1
+
+	var frontMatter, err = templates.GetFrontMatter(templateString)
+	if err != nil {
+		addDiagnostic(file.relativePath, line, "%s", err)
+		return
+	}
+	if frontMatter == nil {
+		return
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+	}{
+		{"name", frontMatter.Name},
+		{"forEach", frontMatter.ForEach},
+		{"skip", frontMatter.Skip},
+	} {
+		if field.value == "" {
+			continue
+		}
+
+		var tmpl *template.Template
+		tmpl, err = templates.GetTemplateFromString(templates.NewRootTemplate(), field.name, field.value)
+		if err != nil {
+			var _, message = templates.GetErrorLine(err)
+			addDiagnostic(file.relativePath, line, "failed to parse \"%s\" in front matter: %s", field.name, message)
+			continue
+		}
+
+		var analysis = templates.AnalyzeTemplate(tmpl)
+		for _, reference := range analysis.FieldReferences {
+			reference.Line = line
+			file.analysis.FieldReferences = append(file.analysis.FieldReferences, reference)
+		}
+		for _, reference := range analysis.TemplateReferences {
+			reference.Line = line
+			file.analysis.TemplateReferences = append(file.analysis.TemplateReferences, reference)
+		}
+	}
+}
+
+// getProducedValues returns the names of the top-level values which the interface produces, by evaluating it with the
+// default parameters and by finding the keys which are written in the file (since some keys may only be produced with
+// other parameters).  If the interface can't be evaluated, nil is returned and a diagnostic is reported.
+func getProducedValues(
+	packageDir string,
+	addDiagnostic func(relativePath string, line int, format string, args ...any),
+) (map[string]bool, error) {
+	// Parse errors in the helpers and the interface have already been reported
+	var sharedTemplate, err = GetSharedTemplate(packageDir, nil)
+	if err != nil {
+		return nil, nil
+	}
+
+	var parameters *map[string]any
+	parameters, err = getMergedParameters(packageDir, &map[string]any{})
+	if err != nil {
+		addDiagnostic(constants.ParametersFileName, 0, "failed to read default parameters: %s", err)
+		return nil, nil
+	}
+
+	var values *map[string]any
+	values, err = getValuesFromInterface(sharedTemplate, packageDir, parameters, nil)
+	if err != nil {
+		// The error contains the path of the interface file, so only report the template error underneath it
+		var errorLines = strings.SplitN(err.Error(), "\n", 2)
+		var line, message = templates.GetErrorLine(fmt.Errorf("%s", errorLines[len(errorLines)-1]))
+		addDiagnostic(constants.InterfaceFileName, line, "failed to evaluate the interface with the default parameters: %s", message)
+		return nil, nil
+	}
+
+	var result = map[string]bool{}
+	if values != nil {
+		for key := range *values {
+			result[key] = true
+		}
+	}
+
+	var interfaceString string
+	interfaceString, err = files.ReadString(GetInterfaceFile(packageDir))
+	if err != nil {
+		return nil, err
+	}
+	for _, match := range interfaceKeyRegex.FindAllStringSubmatch(interfaceString, -1) {
+		result[match[1]] = true
+	}
+
+	return result, nil
+}
+
+// lintParameters reports the default parameters which are never read by the interface.  Nothing is reported if the
+// interface uses the parameters as a whole (e.g. by passing them to a helper template), since then it isn't possible to
+// know which parameters are read.
+func lintParameters(
+	packageDir string,
+	interfaceAnalysis *templates.TemplateAnalysis,
+	addDiagnostic func(relativePath string, line int, format string, args ...any),
+) error {
+	if interfaceAnalysis.UsesWholeInput {
+		return nil
+	}
+
+	var parametersBytes, err = files.ReadBytes(GetDefaultParametersFile(packageDir))
+	if err != nil {
+		return err
+	}
+
+	var document yamlv3.Node
+	if err = yamlv3.Unmarshal(parametersBytes, &document); err != nil {
+		addDiagnostic(constants.ParametersFileName, 0, "failed to parse default parameters: %s", err)
+		return nil
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	var readParameters = map[string]bool{}
+	for _, reference := range interfaceAnalysis.FieldReferences {
+		readParameters[reference.Fields[0]] = true
+	}
+
+	var mapping = document.Content[0]
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		var keyNode = mapping.Content[i]
+		if !readParameters[keyNode.Value] {
+			addDiagnostic(constants.ParametersFileName, keyNode.Line, "parameter \"%s\" is not used by the interface", keyNode.Value)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package template_package
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLintPackage(t *testing.T) {
+	var createPackage = func(packageFiles map[string]string) string {
+		var packageDir = t.TempDir()
+		packageFiles["package.yaml"] = "name: test/lint\nversion: 1.0.0\n"
+		for relativePath, content := range packageFiles {
+			var filePath = filepath.Join(packageDir, filepath.FromSlash(relativePath))
+			So(os.MkdirAll(filepath.Dir(filePath), os.ModePerm), ShouldBeNil)
+			So(os.WriteFile(filePath, []byte(content), 0644), ShouldBeNil)
+		}
+
+		return packageDir
+	}
+
+	var getMessages = func(diagnostics []*Diagnostic) []string {
+		var result = []string{}
+		for _, diagnostic := range diagnostics {
+			result = append(result, diagnostic.String())
+		}
+
+		return result
+	}
+
+	Convey("A valid package has no problems", t, func() {
+		var packageDir = createPackage(map[string]string{
+			"parameters.yaml":     "name: Foo\nextra: false\n",
+			"interface.yaml":      "greeting: Hello {{ .name }}\n{{ if .extra }}\nextra: true\n{{ end }}\n",
+			"helpers/names.tpl":   `{{ define "greeting" }}{{ .values.greeting }}{{ end }}`,
+			"templates/a.txt":     "{{ include \"greeting\" . }}{{ with .values.extra }}{{ .ignored }}{{ end }}",
+			"templates/b/c.txt":   "{{- /* kpm\nname: \"{{ .values.greeting }}.txt\"\n*/ -}}\n{{ template \"a.txt\" . }}",
+			"dependencies/d.yaml": "package:\n  name: test/dep\n  version: 1.0.0\nparameters:\n  greeting: {{ $.values.greeting }}\n",
+		})
+
+		var diagnostics, err = LintPackage(packageDir)
+		So(err, ShouldBeNil)
+		So(getMessages(diagnostics), ShouldBeEmpty)
+	})
+
+	Convey("Problems are reported with their files and lines", t, func() {
+		var packageDir = createPackage(map[string]string{
+			"parameters.yaml":   "name: Foo\nunused: true\n",
+			"interface.yaml":    "greeting: Hello {{ .name }}\n",
+			"helpers/a.tpl":     `{{ define "greeting" }}a{{ end }}`,
+			"helpers/b.tpl":     "\n{{ define \"greeting\" }}b{{ end }}",
+			"templates/a.txt":   "{{ .values.greeting }}\n{{ .values.missing }}\n{{ include \"nothing\" . }}",
+			"templates/bad.txt": "ok\n{{ if }}",
+		})
+
+		var diagnostics, err = LintPackage(packageDir)
+		So(err, ShouldBeNil)
+		So(getMessages(diagnostics), ShouldResemble, []string{
+			"helpers/b.tpl:2: template \"greeting\" is already defined in helpers/a.tpl:1",
+			"parameters.yaml:2: parameter \"unused\" is not used by the interface",
+			"templates/a.txt:2: \".values.missing\" is not produced by the interface",
+			"templates/a.txt:3: template \"nothing\" is not defined",
+			"templates/bad.txt:2: failed to parse template: missing value for if",
+		})
+	})
+
+	Convey("Parameters aren't reported as unused if the interface uses all of them at once", t, func() {
+		var packageDir = createPackage(map[string]string{
+			"parameters.yaml": "name: Foo\nother: Bar\n",
+			"interface.yaml":  "{{ include \"all\" . }}",
+			"helpers/all.tpl": `{{ define "all" }}{{ toYaml . }}{{ end }}`,
+			"templates/a.txt": "{{ .values.other }}",
+		})
+
+		var diagnostics, err = LintPackage(packageDir)
+		So(err, ShouldBeNil)
+		So(getMessages(diagnostics), ShouldBeEmpty)
+	})
+}
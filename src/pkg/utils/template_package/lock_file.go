@@ -0,0 +1,289 @@
+package template_package
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/yaml"
+)
+
+// LockFileApiVersion is the version of the lock file format.
+const LockFileApiVersion = "v1"
+
+// LockFile records the exact packages which were used to generate each output in an output directory, so that the
+// same output can be generated again later.
+type LockFile struct {
+	ApiVersion string `yaml:"apiVersion" json:"apiVersion"`
+
+	// Outputs maps output names to the packages which were used to generate them.
+	Outputs map[string]*LockedOutput `yaml:"outputs" json:"outputs"`
+}
+
+// LockedOutput records the packages which were used to generate a single output.
+type LockedOutput struct {
+	// Package is the package which was run.
+	Package PackageInfo `yaml:"package" json:"package"`
+
+	// Nodes are the nodes of the dependency tree, ordered by their paths.
+	Nodes []*LockedNode `yaml:"nodes" json:"nodes"`
+}
+
+// LockedNode records the package which was used for a single node of a dependency tree.
+type LockedNode struct {
+	// Path is the output path of the node, relative to the output directory.
+	Path string `yaml:"path" json:"path"`
+
+	Name    string `yaml:"name" json:"name"`
+	Version string `yaml:"version" json:"version"`
+
+	// Constraint is the version constraint that the version was resolved from, if the dependency definition didn't
+	// specify an exact version.
+	Constraint string `yaml:"constraint,omitempty" json:"constraint,omitempty"`
+
+	// Repository is the name of the repository that the package was pulled from.  It is empty if the package was
+	// already in the local KPM repository.
+	Repository string `yaml:"repository,omitempty" json:"repository,omitempty"`
+
+	// Digest is the digest of the package's contents, in the form "sha256:<hex>".
+	Digest string `yaml:"digest" json:"digest"`
+}
+
+// GetLockFilePath returns the path of the lock file in an output directory.
+func GetLockFilePath(outputDir string) string {
+	return filepath.Join(outputDir, constants.LockFileName)
+}
+
+// NewLockFile creates an empty lock file.
+func NewLockFile() *LockFile {
+	return &LockFile{
+		ApiVersion: LockFileApiVersion,
+		Outputs:    map[string]*LockedOutput{},
+	}
+}
+
+// ReadLockFile reads the lock file at the given path.
+func ReadLockFile(lockFilePath string) (result *LockFile, err error) {
+	var lockFileBytes []byte
+	lockFileBytes, err = files.ReadBytes(lockFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	result = NewLockFile()
+	err = yaml.BytesToObject(lockFileBytes, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse lock file '%s': %s", lockFilePath, err)
+	}
+
+	if result.ApiVersion != LockFileApiVersion {
+		return nil, fmt.Errorf("unsupported lock file version '%s' in lock file: %s", result.ApiVersion, lockFilePath)
+	}
+
+	if result.Outputs == nil {
+		result.Outputs = map[string]*LockedOutput{}
+	}
+
+	return result, nil
+}
+
+// WriteLockFile writes the lock file to the given path, overwriting it if it already exists.
+func WriteLockFile(lockFilePath string, lockFile *LockFile) (err error) {
+	var lockFileBytes []byte
+	lockFileBytes, err = yaml.ObjectToBytes(lockFile)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(lockFilePath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(lockFilePath, lockFileBytes, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write lock file '%s': %s", lockFilePath, err)
+	}
+
+	return nil
+}
+
+// GetNode returns the locked node with the given path, if there is one.
+func (lockedOutput *LockedOutput) GetNode(nodePath string) (*LockedNode, bool) {
+	if lockedOutput == nil {
+		return nil, false
+	}
+
+	for _, node := range lockedOutput.Nodes {
+		if node.Path == nodePath {
+			return node, true
+		}
+	}
+
+	return nil, false
+}
+
+// GetRepository returns the repository which a package version was pulled from, if it is in the locked output.
+func (lockedOutput *LockedOutput) GetRepository(packageInfo *PackageInfo) (string, bool) {
+	if lockedOutput == nil {
+		return "", false
+	}
+
+	for _, node := range lockedOutput.Nodes {
+		if node.Name == packageInfo.Name && node.Version == packageInfo.Version {
+			return node.Repository, true
+		}
+	}
+
+	return "", false
+}
+
+// GetDifferences returns a human-readable description of each difference between this locked output and another one.
+func (lockedOutput *LockedOutput) GetDifferences(other *LockedOutput) []string {
+	var result = []string{}
+
+	if lockedOutput.Package != other.Package {
+		result = append(result, fmt.Sprintf("package '%s' was locked, but '%s' was used", lockedOutput.Package, other.Package))
+	}
+
+	var otherNodes = map[string]*LockedNode{}
+	for _, node := range other.Nodes {
+		otherNodes[node.Path] = node
+	}
+
+	for _, node := range lockedOutput.Nodes {
+		var otherNode, found = otherNodes[node.Path]
+		delete(otherNodes, node.Path)
+		if !found {
+			result = append(result, fmt.Sprintf("%s: locked package '%s-%s' is no longer used", node.Path, node.Name, node.Version))
+			continue
+		}
+
+		switch {
+		case node.Name != otherNode.Name || node.Version != otherNode.Version:
+			result = append(result, fmt.Sprintf(
+				"%s: package '%s-%s' was locked, but '%s-%s' was used",
+				node.Path, node.Name, node.Version, otherNode.Name, otherNode.Version,
+			))
+		case node.Digest != otherNode.Digest:
+			result = append(result, fmt.Sprintf(
+				"%s: package '%s-%s' has changed (locked digest '%s', but found '%s')",
+				node.Path, node.Name, node.Version, node.Digest, otherNode.Digest,
+			))
+		case node.Repository != otherNode.Repository:
+			result = append(result, fmt.Sprintf(
+				"%s: package '%s-%s' was locked to repository '%s', but was pulled from '%s'",
+				node.Path, node.Name, node.Version, node.Repository, otherNode.Repository,
+			))
+		}
+	}
+
+	var newPaths = make([]string, 0, len(otherNodes))
+	for nodePath := range otherNodes {
+		newPaths = append(newPaths, nodePath)
+	}
+	sort.Strings(newPaths)
+	for _, nodePath := range newPaths {
+		var node = otherNodes[nodePath]
+		result = append(result, fmt.Sprintf("%s: package '%s-%s' is not in the lock file", node.Path, node.Name, node.Version))
+	}
+
+	return result
+}
+
+// GetLockedOutput records the packages in the dependency tree.  The given function returns the name of the repository
+// that a package was pulled from (or an empty string if it was already in the local KPM repository).
+func (tree *DependencyTree) GetLockedOutput(getRepository func(packageInfo *PackageInfo) string) (result *LockedOutput, err error) {
+	result = &LockedOutput{
+		Package: *tree.root.packageDefinition.PackageInfo,
+		Nodes:   []*LockedNode{},
+	}
+
+	// Packages may be used by many nodes, so only calculate each digest once.
+	var digests = map[string]string{}
+
+	var addNode func(node *dependencyTreeNode) error
+	addNode = func(node *dependencyTreeNode) error {
+		var packageInfo = node.packageDefinition.PackageInfo
+		var digest, found = digests[node.PackageDirPath]
+		if !found {
+			var err error
+			digest, err = GetPackageDigest(node.PackageDirPath)
+			if err != nil {
+				return fmt.Errorf("failed to get digest of package '%s': %s", packageInfo, err)
+			}
+			digests[node.PackageDirPath] = digest
+		}
+
+		result.Nodes = append(result.Nodes, &LockedNode{
+			Path:       node.getPath(),
+			Name:       packageInfo.Name,
+			Version:    packageInfo.Version,
+			Constraint: node.versionConstraint,
+			Repository: getRepository(packageInfo),
+			Digest:     digest,
+		})
+
+		for _, child := range node.Children {
+			if err := addNode(child); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if err = addNode(tree.root); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(result.Nodes, func(i, j int) bool {
+		return result.Nodes[i].Path < result.Nodes[j].Path
+	})
+
+	return result, nil
+}
+
+// GetPackageDigest calculates a digest of the contents of a package directory, in the form "sha256:<hex>".  It only
+// depends on the relative paths and contents of the files in the package.
+func GetPackageDigest(packageDir string) (string, error) {
+	var hash = sha256.New()
+	var err = filepath.WalkDir(packageDir, func(filePath string, dirEntry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if dirEntry.IsDir() {
+			return nil
+		}
+
+		var relativePath, err = filepath.Rel(packageDir, filePath)
+		if err != nil {
+			log.Panicf("Failed to get relative path of file in package: %s", err)
+		}
+
+		var fileBytes []byte
+		fileBytes, err = os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+
+		// Hash each file separately, so that file boundaries can't be moved without changing the digest.
+		var fileHash = sha256.Sum256(fileBytes)
+		fmt.Fprintf(hash, "%s\x00%s\n", filepath.ToSlash(relativePath), hex.EncodeToString(fileHash[:]))
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
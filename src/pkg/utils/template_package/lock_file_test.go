@@ -0,0 +1,91 @@
+package template_package
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLockFile(t *testing.T) {
+	Convey("Given a locked output", t, func() {
+		var lockedOutput = &LockedOutput{
+			Package: PackageInfo{Name: "test/parent", Version: "1.0.0"},
+			Nodes: []*LockedNode{
+				{Path: "parent", Name: "test/parent", Version: "1.0.0", Digest: "sha256:aaa"},
+				{Path: "parent/child", Name: "test/child", Version: "1.0.0", Repository: "team", Digest: "sha256:bbb"},
+			},
+		}
+
+		Convey("It can be written and read back", func() {
+			var lockFilePath = GetLockFilePath(t.TempDir())
+			var lockFile = NewLockFile()
+			lockFile.Outputs["parent"] = lockedOutput
+			So(WriteLockFile(lockFilePath, lockFile), ShouldBeNil)
+
+			var readLockFile, err = ReadLockFile(lockFilePath)
+			So(err, ShouldBeNil)
+			So(readLockFile, ShouldResemble, lockFile)
+		})
+
+		Convey("The repositories of locked packages can be found", func() {
+			var repoName, found = lockedOutput.GetRepository(&PackageInfo{Name: "test/child", Version: "1.0.0"})
+			So(found, ShouldBeTrue)
+			So(repoName, ShouldEqual, "team")
+
+			_, found = lockedOutput.GetRepository(&PackageInfo{Name: "test/child", Version: "2.0.0"})
+			So(found, ShouldBeFalse)
+		})
+
+		Convey("There are no differences to an identical output", func() {
+			So(lockedOutput.GetDifferences(lockedOutput), ShouldBeEmpty)
+		})
+
+		Convey("Changed, removed and added nodes are differences", func() {
+			var otherOutput = &LockedOutput{
+				Package: lockedOutput.Package,
+				Nodes: []*LockedNode{
+					{Path: "parent", Name: "test/parent", Version: "1.0.0", Digest: "sha256:ccc"},
+					{Path: "parent/other", Name: "test/other", Version: "1.0.0", Digest: "sha256:ddd"},
+				},
+			}
+
+			So(lockedOutput.GetDifferences(otherOutput), ShouldResemble, []string{
+				"parent: package 'test/parent-1.0.0' has changed (locked digest 'sha256:aaa', but found 'sha256:ccc')",
+				"parent/child: locked package 'test/child-1.0.0' is no longer used",
+				"parent/other: package 'test/other-1.0.0' is not in the lock file",
+			})
+		})
+	})
+
+	Convey("Given a package directory", t, func() {
+		var packageDir = t.TempDir()
+		So(os.MkdirAll(filepath.Join(packageDir, "templates"), os.ModePerm), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(packageDir, "package.yaml"), []byte("name: test/package\nversion: 1.0.0\n"), 0644), ShouldBeNil)
+		So(os.WriteFile(filepath.Join(packageDir, "templates", "a.txt"), []byte("a"), 0644), ShouldBeNil)
+
+		var digest, err = GetPackageDigest(packageDir)
+		So(err, ShouldBeNil)
+		So(digest, ShouldStartWith, "sha256:")
+
+		Convey("The digest doesn't depend on where the package is", func() {
+			var copyDir = filepath.Join(t.TempDir(), "copy")
+			So(os.MkdirAll(filepath.Join(copyDir, "templates"), os.ModePerm), ShouldBeNil)
+			So(os.WriteFile(filepath.Join(copyDir, "package.yaml"), []byte("name: test/package\nversion: 1.0.0\n"), 0644), ShouldBeNil)
+			So(os.WriteFile(filepath.Join(copyDir, "templates", "a.txt"), []byte("a"), 0644), ShouldBeNil)
+
+			var copyDigest, err = GetPackageDigest(copyDir)
+			So(err, ShouldBeNil)
+			So(copyDigest, ShouldEqual, digest)
+		})
+
+		Convey("The digest changes when a file is renamed", func() {
+			So(os.Rename(filepath.Join(packageDir, "templates", "a.txt"), filepath.Join(packageDir, "templates", "b.txt")), ShouldBeNil)
+
+			var newDigest, err = GetPackageDigest(packageDir)
+			So(err, ShouldBeNil)
+			So(newDigest, ShouldNotEqual, digest)
+		})
+	})
+}
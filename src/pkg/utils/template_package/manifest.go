@@ -0,0 +1,131 @@
+package template_package
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/yaml"
+)
+
+// ManifestApiVersion is the version of the manifest format.
+const ManifestApiVersion = "v1"
+
+// Manifest records the files which were generated in a package's output directory, so that later runs can tell them
+// apart from files which were added by hand.
+type Manifest struct {
+	ApiVersion string `yaml:"apiVersion" json:"apiVersion"`
+
+	// Files are the generated files, ordered by their paths.
+	Files []*ManifestFile `yaml:"files" json:"files"`
+}
+
+// ManifestFile records a single generated file.
+type ManifestFile struct {
+	// Path is the path of the file relative to the package's output directory, using forward slashes.
+	Path string `yaml:"path" json:"path"`
+
+	// Digest is the digest of the file's contents when it was generated, in the form "sha256:<hex>".
+	Digest string `yaml:"digest" json:"digest"`
+}
+
+// GetManifestPath returns the path of the manifest in a package's output directory.
+func GetManifestPath(packageOutputDir string) string {
+	return filepath.Join(packageOutputDir, constants.ManifestFileName)
+}
+
+// NewManifest creates a manifest which records the given output files.
+func NewManifest(outputFiles []*OutputFile) *Manifest {
+	var result = &Manifest{
+		ApiVersion: ManifestApiVersion,
+		Files:      make([]*ManifestFile, 0, len(outputFiles)),
+	}
+
+	for _, outputFile := range outputFiles {
+		result.Files = append(result.Files, &ManifestFile{
+			Path:   outputFile.Path,
+			Digest: GetDataDigest(outputFile.Data),
+		})
+	}
+
+	sort.Slice(result.Files, func(i, j int) bool {
+		return result.Files[i].Path < result.Files[j].Path
+	})
+
+	return result
+}
+
+// ReadManifest reads the manifest at the given path.  If it doesn't exist, an empty manifest is returned.
+func ReadManifest(manifestPath string) (result *Manifest, err error) {
+	if _, err = os.Stat(manifestPath); os.IsNotExist(err) {
+		return &Manifest{ApiVersion: ManifestApiVersion, Files: []*ManifestFile{}}, nil
+	}
+
+	var manifestBytes []byte
+	manifestBytes, err = files.ReadBytes(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	result = &Manifest{}
+	err = yaml.BytesToObject(manifestBytes, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest '%s': %s", manifestPath, err)
+	}
+
+	if result.ApiVersion != ManifestApiVersion {
+		return nil, fmt.Errorf("unsupported manifest version '%s' in manifest: %s", result.ApiVersion, manifestPath)
+	}
+
+	if result.Files == nil {
+		result.Files = []*ManifestFile{}
+	}
+
+	// Paths are joined with the output directory to delete stale files, so they must not point outside it
+	for _, manifestFile := range result.Files {
+		var cleanPath string
+		if cleanPath, err = files.CleanRelativePath(manifestFile.Path); err != nil {
+			return nil, fmt.Errorf("invalid file path in manifest '%s': %s", manifestPath, err)
+		}
+		manifestFile.Path = cleanPath
+	}
+
+	return result, nil
+}
+
+// WriteManifest writes the manifest to the given path, overwriting it if it already exists.
+func WriteManifest(manifestPath string, manifest *Manifest) (err error) {
+	var manifestBytes []byte
+	manifestBytes, err = yaml.ObjectToBytes(manifest)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(manifestPath, manifestBytes, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write manifest '%s': %s", manifestPath, err)
+	}
+
+	return nil
+}
+
+// GetDigests returns the digests of the files in the manifest, keyed by their paths.
+func (manifest *Manifest) GetDigests() map[string]string {
+	var result = make(map[string]string, len(manifest.Files))
+	for _, manifestFile := range manifest.Files {
+		result[manifestFile.Path] = manifestFile.Digest
+	}
+
+	return result
+}
+
+// GetDataDigest calculates the digest of some data, in the form "sha256:<hex>".
+func GetDataDigest(data []byte) string {
+	var hash = sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(hash[:])
+}
@@ -2,8 +2,12 @@ package template_package
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
 	"github.com/rohitramu/kpm/src/pkg/utils/validation"
+	"github.com/rohitramu/kpm/src/pkg/utils/versions"
 )
 
 // PackageNamesAndVersions is the mapping of package names to its list of versions.
@@ -18,8 +22,21 @@ func GetOutputFriendlyName(outputName string, packageFullName string) string {
 	return fmt.Sprintf("%s (%s)", outputName, packageFullName)
 }
 
+// comparePackageFullNames orders package full names by package name, and then by version precedence.
+func comparePackageFullNames(a, b any) int {
+	var aFullName, bFullName = a.(string), b.(string)
+	var aName, aVersion, aErr = validation.ExtractNameAndVersionFromPackageFullName(aFullName)
+	var bName, bVersion, bErr = validation.ExtractNameAndVersionFromPackageFullName(bFullName)
+	if aErr != nil || bErr != nil || aName != bName {
+		return strings.Compare(aFullName, bFullName)
+	}
+
+	return versions.Compare(aVersion, bVersion)
+}
+
 // GetHighestPackageVersion returns the highest available package version found in the local KPM repository.
-func GetHighestPackageVersion(repoDir string, packageName string) (string, error) {
+// Prereleases are ignored unless "includePrerelease" is true.
+func GetHighestPackageVersion(repoDir string, packageName string, includePrerelease bool) (string, error) {
 	var err error
 
 	var packageVersions []string
@@ -29,20 +46,81 @@ func GetHighestPackageVersion(repoDir string, packageName string) (string, error
 	}
 
 	// Get the highest available version
-	var highestVersion *string
-	for _, currentVersion := range packageVersions {
-		// Keep replacing the current version if we found a higher matching version until we get to the end of the matched list
-		if highestVersion == nil || currentVersion > *highestVersion {
-			highestVersion = &currentVersion
+	var result, found = versions.GetHighestVersion(packageVersions, includePrerelease)
+	if !found {
+		return "", fmt.Errorf("only prerelease versions of the template package \"%s\" were found in the local KPM repository: %s", packageName, strings.Join(packageVersions, ", "))
+	}
+
+	return result, nil
+}
+
+// ResolvePackageVersion returns the highest version of a package which satisfies the version constraint, from the
+// versions in the local KPM repository and the versions that the fetcher can fetch (if it is not nil).  If the locked
+// version is not empty and it satisfies the constraint, it is used instead.  Prereleases are only used if the constraint
+// mentions a prerelease or "includePrerelease" is true.
+func ResolvePackageVersion(
+	kpmHomeDir string,
+	packageName string,
+	versionConstraint string,
+	lockedVersion string,
+	fetcher PackageFetcher,
+	includePrerelease bool,
+) (string, error) {
+	var constraint, err = versions.ParseConstraint(versionConstraint)
+	if err != nil {
+		return "", err
+	}
+
+	// Prefer the locked version, so that newer versions are only used when the constraint changes.
+	if lockedVersion != "" && constraint.Matches(lockedVersion, includePrerelease) {
+		return lockedVersion, nil
+	}
+
+	var availableVersions []string
+	availableVersions, err = GetLocalPackageVersions(kpmHomeDir, packageName)
+	if err != nil {
+		return "", err
+	}
+
+	// Repositories which can't be reached shouldn't stop local versions from being used.
+	var fetchErr error
+	if fetcher != nil {
+		var fetchableVersions []string
+		fetchableVersions, fetchErr = fetcher.GetPackageVersions(packageName)
+		if fetchErr != nil {
+			log.Warningf("Failed to get the available versions of package \"%s\" from repositories, so only local versions will be used: %s", packageName, fetchErr)
 		}
+
+		availableVersions = append(availableVersions, fetchableVersions...)
 	}
 
-	// This value will never be null since we already checked that the array is not empty
-	var result = *highestVersion
+	var result, found = constraint.GetHighestMatchingVersion(availableVersions, includePrerelease)
+	if !found {
+		if fetchErr != nil {
+			return "", fmt.Errorf("no local version of package \"%s\" satisfies the version constraint \"%s\", and the versions in repositories couldn't be checked: %s", packageName, constraint, fetchErr)
+		}
+
+		return "", fmt.Errorf("no available version of package \"%s\" satisfies the version constraint \"%s\"", packageName, constraint)
+	}
 
 	return result, nil
 }
 
+// GetLocalPackageVersions returns the versions of a package in the local KPM repository, which may be empty.
+func GetLocalPackageVersions(kpmHomeDir string, packageName string) ([]string, error) {
+	// If the packages directory doesn't exist yet, there are no packages.
+	if files.DirExists(GetRepoPackagesDir(kpmHomeDir), "packages repository") != nil {
+		return []string{}, nil
+	}
+
+	var availablePackagesAndVersions, err = GetAvailablePackagesAndVersions(kpmHomeDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return availablePackagesAndVersions[packageName], nil
+}
+
 func GetPackageVersions(repoDir string, packageName string) (result []string, err error) {
 	// Get all available package names and versions
 	var availablePackagesAndVersions PackageNamesAndVersions
@@ -97,5 +175,10 @@ func GetAvailablePackagesAndVersions(kpmHomeDir string) (PackageNamesAndVersions
 		availablePackagesAndVersions[currentPackageName] = append(versionsForPackage, currentPackageVersion)
 	}
 
+	// Order the versions by precedence
+	for _, versionsForPackage := range availablePackagesAndVersions {
+		versions.Sort(versionsForPackage)
+	}
+
 	return availablePackagesAndVersions, nil
 }
@@ -0,0 +1,58 @@
+package template_package
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// failingPackageFetcher is a package fetcher whose repositories can't be reached.
+type failingPackageFetcher struct {
+	versions []string
+}
+
+func (fetcher *failingPackageFetcher) GetPackageVersions(packageName string) ([]string, error) {
+	return fetcher.versions, fmt.Errorf("repository is unreachable")
+}
+
+func (fetcher *failingPackageFetcher) FetchPackage(packageInfo *PackageInfo) error {
+	return fmt.Errorf("repository is unreachable")
+}
+
+func TestResolvePackageVersion(t *testing.T) {
+	Convey("Given a local package and repositories which can't be reached", t, func() {
+		var kpmHomeDir = t.TempDir()
+		var packageDir = GetPackageDir(kpmHomeDir, GetPackageFullName("test/local", "1.2.0"))
+		So(os.MkdirAll(packageDir, os.ModePerm), ShouldBeNil)
+		var packageFiles = map[string]string{
+			"package.yaml":    "name: test/local\nversion: 1.2.0\n",
+			"interface.yaml":  "{}\n",
+			"parameters.yaml": "{}\n",
+		}
+		for fileName, content := range packageFiles {
+			So(os.WriteFile(filepath.Join(packageDir, fileName), []byte(content), 0644), ShouldBeNil)
+		}
+
+		Convey("A local version which satisfies the constraint is used", func() {
+			var version, err = ResolvePackageVersion(kpmHomeDir, "test/local", "^1.0.0", "", &failingPackageFetcher{}, false)
+			So(err, ShouldBeNil)
+			So(version, ShouldEqual, "1.2.0")
+		})
+
+		Convey("Versions from repositories which could be reached are still used", func() {
+			var fetcher = &failingPackageFetcher{versions: []string{"1.3.0"}}
+			var version, err = ResolvePackageVersion(kpmHomeDir, "test/local", "^1.0.0", "", fetcher, false)
+			So(err, ShouldBeNil)
+			So(version, ShouldEqual, "1.3.0")
+		})
+
+		Convey("Resolving fails if no version satisfies the constraint", func() {
+			var _, err = ResolvePackageVersion(kpmHomeDir, "test/local", "^2.0.0", "", &failingPackageFetcher{}, false)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "repository is unreachable")
+		})
+	})
+}
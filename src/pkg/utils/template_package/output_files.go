@@ -0,0 +1,257 @@
+package template_package
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/templates"
+	"github.com/rohitramu/kpm/src/pkg/utils/yaml"
+)
+
+// ExecutableTemplate is a template in a package's templates directory, which generates output files.
+type ExecutableTemplate struct {
+	*template.Template
+
+	// The templates defined in the front matter (nil if they aren't defined).
+	nameTemplate    *template.Template
+	forEachTemplate *template.Template
+	skipTemplate    *template.Template
+	skipIfEmpty     bool
+}
+
+// templateOutputFileMode is the permissions of files generated by executable templates.
+const templateOutputFileMode fs.FileMode = 0755
+
+// OutputFile is a file generated by a package.
+type OutputFile struct {
+	// Path is the path of the file relative to the package's output directory, using forward slashes.
+	Path string
+
+	// Source is the path of the template or static file which generated the file, relative to the package directory.
+	Source string
+
+	// Mode is the permissions of the file.
+	Mode fs.FileMode
+
+	Data []byte
+}
+
+// NewExecutableTemplate creates an executable template from a template which has been parsed from the given file.
+func NewExecutableTemplate(tmpl *template.Template, filePath string) (result *ExecutableTemplate, err error) {
+	result = &ExecutableTemplate{Template: tmpl}
+
+	var templateString string
+	templateString, err = files.ReadString(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var frontMatter *templates.FrontMatter
+	frontMatter, err = templates.GetFrontMatter(templateString)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template '%s': %s", tmpl.Name(), err)
+	}
+	if frontMatter == nil {
+		return result, nil
+	}
+
+	// Templates which generate many files must give each file a different name
+	if frontMatter.ForEach != "" && frontMatter.Name == "" {
+		return nil, fmt.Errorf("invalid template '%s': templates with \"forEach\" must also have a \"name\"", tmpl.Name())
+	}
+
+	// The front matter templates are added to the same template, so they can use the package's helpers
+	var parseFrontMatterField = func(fieldName string, fieldTemplate string) (*template.Template, error) {
+		if fieldTemplate == "" {
+			return nil, nil
+		}
+
+		var fieldTmpl, err = tmpl.New(fmt.Sprintf("%s (%s)", tmpl.Name(), fieldName)).Parse(fieldTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid \"%s\" in front matter of template '%s': %s", fieldName, tmpl.Name(), err)
+		}
+
+		return fieldTmpl, nil
+	}
+
+	if result.nameTemplate, err = parseFrontMatterField("name", frontMatter.Name); err != nil {
+		return nil, err
+	}
+	if result.forEachTemplate, err = parseFrontMatterField("forEach", frontMatter.ForEach); err != nil {
+		return nil, err
+	}
+	if result.skipTemplate, err = parseFrontMatterField("skip", frontMatter.Skip); err != nil {
+		return nil, err
+	}
+	result.skipIfEmpty = frontMatter.SkipIfEmpty
+
+	return result, nil
+}
+
+// GetOutputFiles executes the given templates and returns the files that they generate, along with the given static files.
+func GetOutputFiles(
+	executableTemplates []*ExecutableTemplate,
+	staticFiles []*OutputFile,
+	templateInput *map[string]any,
+) ([]*OutputFile, error) {
+	var outputFilesBySource = [][]*OutputFile{staticFiles}
+	for _, tmpl := range executableTemplates {
+		var outputFiles, err = tmpl.GetOutputFiles(templateInput)
+		if err != nil {
+			return nil, err
+		}
+
+		outputFilesBySource = append(outputFilesBySource, outputFiles)
+	}
+
+	var result = []*OutputFile{}
+	var outputFilesByPath = map[string]*OutputFile{}
+	for _, outputFiles := range outputFilesBySource {
+		for _, outputFile := range outputFiles {
+			if existingFile, found := outputFilesByPath[outputFile.Path]; found {
+				return nil, fmt.Errorf(
+					"'%s' and '%s' both generate the output file: %s",
+					existingFile.Source,
+					outputFile.Source,
+					outputFile.Path,
+				)
+			}
+			outputFilesByPath[outputFile.Path] = outputFile
+
+			result = append(result, outputFile)
+		}
+	}
+
+	return result, nil
+}
+
+// GetOutputFiles executes the template and returns the files that it generates.
+func (tmpl *ExecutableTemplate) GetOutputFiles(templateInput *map[string]any) (result []*OutputFile, err error) {
+	result = []*OutputFile{}
+
+	// Get the input for each execution of the template
+	var templateInputs = []*map[string]any{templateInput}
+	if tmpl.forEachTemplate != nil {
+		templateInputs, err = tmpl.getTemplateInputForEachItem(templateInput)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, currentInput := range templateInputs {
+		var skip bool
+		skip, err = tmpl.shouldSkip(currentInput)
+		if err != nil {
+			return nil, err
+		}
+		if skip {
+			log.Debugf("Skipping template: %s", tmpl.Name())
+			continue
+		}
+
+		// Get the path of the output file
+		var outputFilePath = tmpl.Name()
+		if tmpl.nameTemplate != nil {
+			outputFilePath, err = tmpl.executeFrontMatterField(tmpl.nameTemplate, currentInput)
+			if err != nil {
+				return nil, err
+			}
+
+			outputFilePath, err = files.CleanRelativePath(outputFilePath)
+			if err != nil {
+				return nil, fmt.Errorf("invalid output file name generated by template '%s': %s", tmpl.Name(), err)
+			}
+		}
+
+		// Execute the template
+		var data []byte
+		data, err = templates.ExecuteTemplate(tmpl.Template, currentInput)
+		if err != nil {
+			return nil, err
+		}
+
+		// Don't write files which only contain whitespace if the template doesn't want them
+		if tmpl.skipIfEmpty && strings.TrimSpace(string(data)) == "" {
+			log.Debugf("Skipping empty output file generated by template '%s': %s", tmpl.Name(), outputFilePath)
+			continue
+		}
+
+		result = append(result, &OutputFile{
+			Path:   outputFilePath,
+			Source: path.Join(constants.TemplatesDirName, tmpl.Name()),
+			Mode:   templateOutputFileMode,
+			Data:   data,
+		})
+	}
+
+	return result, nil
+}
+
+// getTemplateInputForEachItem executes the "forEach" template, and returns a copy of the template input for each item in
+// the resulting list.
+func (tmpl *ExecutableTemplate) getTemplateInputForEachItem(templateInput *map[string]any) ([]*map[string]any, error) {
+	var itemsYaml, err = tmpl.executeFrontMatterField(tmpl.forEachTemplate, templateInput)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []any
+	err = yaml.BytesToObject([]byte(itemsYaml), &items)
+	if err != nil {
+		return nil, fmt.Errorf("\"forEach\" in template '%s' did not generate a YAML list: %s", tmpl.Name(), err)
+	}
+
+	var result = make([]*map[string]any, 0, len(items))
+	for _, item := range items {
+		var itemInput = make(map[string]any, len(*templateInput)+1)
+		for key, value := range *templateInput {
+			itemInput[key] = value
+		}
+		itemInput[constants.TemplateFieldItem] = item
+
+		result = append(result, &itemInput)
+	}
+
+	return result, nil
+}
+
+// shouldSkip executes the "skip" template, if there is one.
+func (tmpl *ExecutableTemplate) shouldSkip(templateInput *map[string]any) (bool, error) {
+	if tmpl.skipTemplate == nil {
+		return false, nil
+	}
+
+	var skipString, err = tmpl.executeFrontMatterField(tmpl.skipTemplate, templateInput)
+	if err != nil {
+		return false, err
+	}
+
+	if skipString == "" {
+		return false, nil
+	}
+
+	var skip bool
+	skip, err = strconv.ParseBool(skipString)
+	if err != nil {
+		return false, fmt.Errorf("\"skip\" in template '%s' must generate \"true\" or \"false\", but generated: %s", tmpl.Name(), skipString)
+	}
+
+	return skip, nil
+}
+
+// executeFrontMatterField executes one of the templates in the front matter and trims whitespace from the result.
+func (tmpl *ExecutableTemplate) executeFrontMatterField(fieldTemplate *template.Template, templateInput *map[string]any) (string, error) {
+	var resultBytes, err = templates.ExecuteTemplate(fieldTemplate, templateInput)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(resultBytes)), nil
+}
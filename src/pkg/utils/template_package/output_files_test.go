@@ -0,0 +1,106 @@
+package template_package
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/templates"
+)
+
+func TestOutputFiles(t *testing.T) {
+	var getOutputFiles = func(templateFiles map[string]string, values map[string]any) ([]*OutputFile, error) {
+		var packageDir = t.TempDir()
+		for relativePath, content := range templateFiles {
+			var filePath = filepath.Join(GetTemplatesDir(packageDir), filepath.FromSlash(relativePath))
+			So(os.MkdirAll(filepath.Dir(filePath), os.ModePerm), ShouldBeNil)
+			So(os.WriteFile(filePath, []byte(content), 0644), ShouldBeNil)
+		}
+
+		var parentTemplate = templates.AddPackageSpecificTemplateFunctions(templates.NewRootTemplate(), packageDir)
+		var executableTemplates, err = GetExecutableTemplates(parentTemplate, packageDir, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return GetOutputFiles(executableTemplates, nil, &map[string]any{"values": values})
+	}
+
+	var getOutputFilesByPath = func(outputFiles []*OutputFile) map[string]string {
+		var result = map[string]string{}
+		for _, outputFile := range outputFiles {
+			result[outputFile.Path] = string(outputFile.Data)
+		}
+		return result
+	}
+
+	Convey("Templates without front matter generate a file with the same name", t, func() {
+		var outputFiles, err = getOutputFiles(map[string]string{"base/a.txt": "{{ .values.a }}"}, map[string]any{"a": "A"})
+		So(err, ShouldBeNil)
+		So(getOutputFilesByPath(outputFiles), ShouldResemble, map[string]string{"base/a.txt": "A"})
+	})
+
+	Convey("Templates can generate a file for each item in a list", t, func() {
+		var outputFiles, err = getOutputFiles(map[string]string{
+			"namespace.yaml": `{{- /* kpm
+name: "namespaces/{{ .item }}.yaml"
+forEach: "{{ toYaml .values.namespaces }}"
+*/ -}}
+name: {{ .item }}`,
+		}, map[string]any{"namespaces": []any{"dev", "prod"}})
+		So(err, ShouldBeNil)
+		So(getOutputFilesByPath(outputFiles), ShouldResemble, map[string]string{
+			"namespaces/dev.yaml":  "name: dev",
+			"namespaces/prod.yaml": "name: prod",
+		})
+	})
+
+	Convey("Templates can be skipped", t, func() {
+		var templateFiles = map[string]string{
+			"skipped.txt": `{{- /* kpm
+skip: "{{ not .values.enabled }}"
+*/ -}}
+enabled`,
+			"empty.txt": `{{- /* kpm
+skipIfEmpty: true
+*/ -}}
+{{ if .values.enabled }}enabled{{ end }}
+`,
+		}
+
+		var outputFiles, err = getOutputFiles(templateFiles, map[string]any{"enabled": false})
+		So(err, ShouldBeNil)
+		So(outputFiles, ShouldBeEmpty)
+
+		outputFiles, err = getOutputFiles(templateFiles, map[string]any{"enabled": true})
+		So(err, ShouldBeNil)
+		So(getOutputFilesByPath(outputFiles), ShouldResemble, map[string]string{
+			"skipped.txt": "enabled",
+			"empty.txt":   "enabled\n",
+		})
+	})
+
+	Convey("Output file names must be inside the output directory and unique", t, func() {
+		for _, name := range []string{"../escape.txt", "/abs.txt", "a/../../b.txt", ""} {
+			var _, err = getOutputFiles(map[string]string{
+				"a.txt": "{{/* kpm\nname: \"{{ .values.name }}\"\n*/}}",
+			}, map[string]any{"name": name})
+			So(err, ShouldNotBeNil)
+		}
+
+		var _, err = getOutputFiles(map[string]string{
+			"a.txt": "{{/* kpm\nname: b.txt\n*/}}",
+			"b.txt": "b",
+		}, map[string]any{})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Templates with \"forEach\" must have a \"name\"", t, func() {
+		var _, err = getOutputFiles(map[string]string{
+			"a.txt": "{{/* kpm\nforEach: \"[1, 2]\"\n*/}}",
+		}, map[string]any{})
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,144 @@
+package template_package
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/emirpasic/gods/stacks/linkedliststack"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/params"
+)
+
+// ParameterReport describes the parameters and values of a package in a dependency tree, and where each of the
+// parameters came from.
+type ParameterReport struct {
+	// FriendlyNamePath is the path of the package in the dependency tree, starting from the root package.
+	FriendlyNamePath []string
+
+	// Parameters are the package's default parameters combined with the parameters that it was given.
+	Parameters map[string]any
+
+	// Sources describes where each leaf value in the parameters came from, keyed by its path (e.g. ".name.first").
+	Sources map[string]string
+
+	// Values are the values that the package's interface generated from the parameters.
+	Values map[string]any
+}
+
+// GetParameterReports returns a report for each package in the dependency tree, in the same order that the packages are
+// visited when they are executed.  The given layers describe the parameters which were provided to the root package, in
+// the order that they were merged.
+func (tree *DependencyTree) GetParameterReports(rootLayers []*params.Layer) ([]*ParameterReport, error) {
+	var result = []*ParameterReport{}
+
+	var toVisitStack = linkedliststack.New()
+	toVisitStack.Push(tree.root)
+	for nodeObj, notEmpty := toVisitStack.Pop(); notEmpty; nodeObj, notEmpty = toVisitStack.Pop() {
+		var node, ok = nodeObj.(*dependencyTreeNode)
+		if !ok {
+			log.Panicf("Failed to cast item in stack to node object")
+		}
+
+		var report, err = node.getParameterReport(rootLayers)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, report)
+
+		for _, childNode := range node.Children {
+			toVisitStack.Push(childNode)
+		}
+	}
+
+	return result, nil
+}
+
+// getParameterReport creates the parameter report for a node.
+func (node *dependencyTreeNode) getParameterReport(rootLayers []*params.Layer) (result *ParameterReport, err error) {
+	result = &ParameterReport{FriendlyNamePath: node.getFriendlyNamePath()}
+
+	// The package's default parameters are the bottom layer
+	var defaultParametersFile = GetDefaultParametersFile(node.PackageDirPath)
+	var defaultParametersBytes []byte
+	defaultParametersBytes, err = files.ReadBytes(defaultParametersFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var defaultParameters *map[string]any
+	defaultParameters, err = GetPackageParameters(defaultParametersFile)
+	if err != nil {
+		return nil, err
+	}
+	if defaultParameters == nil {
+		defaultParameters = &map[string]any{}
+	}
+
+	var layers = []*params.Layer{
+		params.NewFileLayer("package default", constants.ParametersFileName, *defaultParameters, defaultParametersBytes),
+	}
+
+	// The root package's parameters are provided by the user, and other packages' parameters are provided by their parent
+	if node.Parent == nil {
+		layers = append(layers, rootLayers...)
+	} else {
+		var parentFriendlyNamePath = result.FriendlyNamePath[:len(result.FriendlyNamePath)-1]
+		layers = append(layers, params.NewLayer(
+			fmt.Sprintf("dependency definition in %s", parentFriendlyNamePath[len(parentFriendlyNamePath)-1]),
+			path.Join(constants.DependenciesDirName, node.OutputName+".yaml"),
+			*node.packageDefinition.Parameters,
+		))
+	}
+
+	var mergedParameters *map[string]any
+	mergedParameters, err = getMergedParameters(node.PackageDirPath, node.packageDefinition.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	result.Parameters = *mergedParameters
+	result.Sources = params.GetSources(result.Parameters, layers)
+
+	result.Values = map[string]any{}
+	if values, ok := (*node.TemplateInput)[constants.TemplateFieldValues].(*map[string]any); ok && values != nil && *values != nil {
+		result.Values = *values
+	}
+
+	return result, nil
+}
+
+// ToAnnotatedYaml converts the report to YAML, with a comment before it which names the package and a comment after each
+// parameter which describes where it came from.
+func (report *ParameterReport) ToAnnotatedYaml() ([]byte, error) {
+	// Parameters are nested under their own key, so their paths need the same prefix
+	var parametersKey = "parameters"
+	var sources = make(map[string]string, len(report.Sources))
+	for sourcePath, source := range report.Sources {
+		sources["."+parametersKey+sourcePath] = source
+	}
+
+	var yamlBytes, err = params.ToAnnotatedYaml(map[string]any{
+		parametersKey:                 report.Parameters,
+		constants.TemplateFieldValues: report.Values,
+	}, sources)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(fmt.Sprintf("# %s\n", strings.Join(report.FriendlyNamePath, " -> "))), yamlBytes...), nil
+}
+
+// getFriendlyNamePath returns the friendly names of the packages from the root of the tree to this node.
+func (node *dependencyTreeNode) getFriendlyNamePath() []string {
+	var result = []string{}
+	for currentNode := node; currentNode != nil; currentNode = currentNode.Parent {
+		var packageInfo = currentNode.packageDefinition.PackageInfo
+		var friendlyName = GetOutputFriendlyName(currentNode.OutputName, GetPackageFullName(packageInfo.Name, packageInfo.Version))
+		result = append([]string{friendlyName}, result...)
+	}
+
+	return result
+}
@@ -0,0 +1,65 @@
+package template_package
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/jsonschema"
+)
+
+// GetParametersSchemaFile returns the path of the parameters schema file in a template package.
+func GetParametersSchemaFile(packageDir string) string {
+	var parametersSchemaFilePath = filepath.Join(packageDir, constants.ParametersSchemaFileName)
+
+	return parametersSchemaFilePath
+}
+
+// GetParametersSchema returns the schema which a package's parameters must match, or nil if the package doesn't have a
+// parameters schema file.
+func GetParametersSchema(packageDir string) (*jsonschema.Schema, error) {
+	var schemaFilePath = GetParametersSchemaFile(packageDir)
+	if files.FileExists(schemaFilePath, "parameters schema") != nil {
+		return nil, nil
+	}
+
+	var schemaBytes, err = files.ReadBytes(schemaFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema *jsonschema.Schema
+	schema, err = jsonschema.Parse(schemaBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters schema file: %s\n%s", schemaFilePath, err)
+	}
+
+	return schema, nil
+}
+
+// validateParameters checks the parameters of a package against the package's parameters schema, if it has one.  The
+// error lists every parameter which doesn't match the schema.
+func validateParameters(packageDir string, parameters *map[string]any) error {
+	var schema, err = GetParametersSchema(packageDir)
+	if err != nil || schema == nil {
+		return err
+	}
+
+	var validationErrors []*jsonschema.ValidationError
+	validationErrors, err = schema.Validate(parameters)
+	if err != nil {
+		return fmt.Errorf("failed to validate parameters: %s", err)
+	}
+	if len(validationErrors) == 0 {
+		return nil
+	}
+
+	var messages = make([]string, len(validationErrors))
+	for i, validationError := range validationErrors {
+		messages[i] = validationError.Error()
+	}
+
+	return fmt.Errorf("parameters do not match the schema in \"%s\":\n%s", constants.ParametersSchemaFileName, strings.Join(messages, "\n"))
+}
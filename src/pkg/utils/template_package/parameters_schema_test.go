@@ -0,0 +1,127 @@
+package template_package
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+)
+
+func TestParametersSchema(t *testing.T) {
+	var createPackage = func(kpmHomeDir string, packageFiles map[string]string) string {
+		var packageDir = GetPackageDir(kpmHomeDir, "test/schema-1.0.0")
+		packageFiles[constants.PackageInfoFileName] = "name: test/schema\nversion: 1.0.0\n"
+		packageFiles[constants.InterfaceFileName] = "greeting: Hello {{ .name.first }}\nport: {{ .port }}\n"
+		packageFiles[constants.ParametersFileName] = "name:\n  first: Foo\nport: 80\n"
+		for relativePath, content := range packageFiles {
+			var filePath = filepath.Join(packageDir, filepath.FromSlash(relativePath))
+			So(os.MkdirAll(filepath.Dir(filePath), os.ModePerm), ShouldBeNil)
+			So(os.WriteFile(filePath, []byte(content), 0644), ShouldBeNil)
+		}
+
+		return packageDir
+	}
+
+	var getTemplateInput = func(kpmHomeDir string, parameters map[string]any) (*map[string]any, error) {
+		var sharedTemplate, err = GetSharedTemplate(GetPackageDir(kpmHomeDir, "test/schema-1.0.0"), nil)
+		So(err, ShouldBeNil)
+
+		return GetTemplateInput(kpmHomeDir, "test/schema-1.0.0", sharedTemplate, &parameters, nil)
+	}
+
+	Convey("Given a package with a parameters schema", t, func() {
+		var kpmHomeDir = t.TempDir()
+		createPackage(kpmHomeDir, map[string]string{
+			constants.ParametersSchemaFileName: `{
+				"type": "object",
+				"required": ["name", "port"],
+				"properties": {
+					"name": {
+						"type": "object",
+						"required": ["first"],
+						"properties": {"first": {"type": "string", "minLength": 1}}
+					},
+					"port": {"type": "integer", "maximum": 65535}
+				}
+			}`,
+		})
+
+		Convey("Parameters which match the schema after being merged with the defaults are accepted", func() {
+			var templateInput, err = getTemplateInput(kpmHomeDir, map[string]any{"port": 8080})
+			So(err, ShouldBeNil)
+
+			var values = (*templateInput)[constants.TemplateFieldValues].(*map[string]any)
+			So((*values)["greeting"], ShouldEqual, "Hello Foo")
+			So((*values)["port"], ShouldEqual, 8080)
+		})
+
+		Convey("Merged parameters which don't match the schema are rejected with the path of each problem", func() {
+			var _, err = getTemplateInput(kpmHomeDir, map[string]any{
+				"name": map[string]any{"first": 5},
+				"port": 70000,
+			})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid parameters for package: test/schema-1.0.0")
+			So(err.Error(), ShouldContainSubstring, "parameters do not match the schema in \"parameters.schema.json\"")
+			So(err.Error(), ShouldContainSubstring, ".name.first: must be of type string")
+			So(err.Error(), ShouldContainSubstring, ".port: must be less than or equal to 65535")
+		})
+
+		Convey("Removing a required default is rejected", func() {
+			var _, err = getTemplateInput(kpmHomeDir, map[string]any{"name": map[string]any{"first": nil}})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, ".name.first: is required")
+		})
+	})
+
+	Convey("Given a package without a parameters schema", t, func() {
+		var kpmHomeDir = t.TempDir()
+		createPackage(kpmHomeDir, map[string]string{})
+
+		Convey("Any parameters are accepted", func() {
+			var templateInput, err = getTemplateInput(kpmHomeDir, map[string]any{"name": map[string]any{"first": 5}, "extra": true})
+			So(err, ShouldBeNil)
+
+			var values = (*templateInput)[constants.TemplateFieldValues].(*map[string]any)
+			So((*values)["greeting"], ShouldEqual, "Hello 5")
+		})
+	})
+
+	Convey("Given a package with an invalid parameters schema", t, func() {
+		var kpmHomeDir = t.TempDir()
+		var packageDir = createPackage(kpmHomeDir, map[string]string{
+			constants.ParametersSchemaFileName: `{"type": "text"}`,
+		})
+
+		Convey("The package is invalid", func() {
+			var _, err = GetPackageInfo(packageDir)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "invalid parameters schema file")
+		})
+	})
+
+	Convey("The sample package's default parameters match its schema", t, func() {
+		var packageDir = t.TempDir()
+		So(GenerateSampleTemplatePackage(packageDir, "sample"), ShouldBeNil)
+
+		var _, err = GetPackageInfo(packageDir)
+		So(err, ShouldBeNil)
+
+		var schema, schemaErr = GetParametersSchema(packageDir)
+		So(schemaErr, ShouldBeNil)
+		So(schema, ShouldNotBeNil)
+
+		var parameters *map[string]any
+		parameters, err = getMergedParameters(packageDir, &map[string]any{})
+		So(err, ShouldBeNil)
+		So(validateParameters(packageDir, parameters), ShouldBeNil)
+
+		// The sample's schema still rejects invalid parameters
+		parameters, err = getMergedParameters(packageDir, &map[string]any{"name": map[string]any{"last": ""}})
+		So(err, ShouldBeNil)
+		So(validateParameters(packageDir, parameters), ShouldNotBeNil)
+	})
+}
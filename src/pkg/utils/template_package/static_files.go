@@ -0,0 +1,193 @@
+package template_package
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+)
+
+// GetStaticFilePaths returns the paths of the files in a package's static files directory which are selected by the
+// package's static file rules.  Paths are relative to the static files directory and use forward slashes.
+func GetStaticFilePaths(packageDir string) ([]string, error) {
+	var fileContent, err = readPackageInfoFile(packageDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return getStaticFilePaths(packageDir, fileContent.Files)
+}
+
+// GetUnmatchedStaticFileIncludes returns the include patterns in a package's static file rules which don't match any
+// files in the static files directory.
+func GetUnmatchedStaticFileIncludes(packageDir string) (result []string, err error) {
+	var fileContent *packageInfoFileContent
+	fileContent, err = readPackageInfoFile(packageDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result = []string{}
+	if fileContent.Files == nil {
+		return result, nil
+	}
+
+	// Only consider the include patterns, since files may match an include pattern and then be excluded
+	var includedPaths []string
+	includedPaths, err = getStaticFilePaths(packageDir, &StaticFileRules{Include: fileContent.Files.Include})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range fileContent.Files.Include {
+		var isMatch bool
+		for _, includedPath := range includedPaths {
+			if isMatch, err = files.MatchGlob(pattern, includedPath); err != nil {
+				return nil, err
+			}
+			if isMatch {
+				break
+			}
+		}
+
+		if !isMatch {
+			result = append(result, pattern)
+		}
+	}
+
+	return result, nil
+}
+
+// GetStaticFiles returns the files in a package's static files directory which are selected by the package's static
+// file rules, so they can be copied to the output without being executed as templates.
+func GetStaticFiles(packageDir string) ([]*OutputFile, error) {
+	var err error
+
+	var relativePaths []string
+	relativePaths, err = GetStaticFilePaths(packageDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var staticFilesDir = GetStaticFilesDir(packageDir)
+	var result = make([]*OutputFile, 0, len(relativePaths))
+	for _, relativePath := range relativePaths {
+		var filePath = filepath.Join(staticFilesDir, filepath.FromSlash(relativePath))
+
+		var fileInfo fs.FileInfo
+		fileInfo, err = os.Stat(filePath)
+		if err != nil {
+			return nil, err
+		}
+
+		var data []byte
+		data, err = os.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read static file: %s\n%s", filePath, err)
+		}
+
+		result = append(result, &OutputFile{
+			Path:   relativePath,
+			Source: path.Join(constants.StaticFilesDirName, relativePath),
+			Mode:   fileInfo.Mode().Perm(),
+			Data:   data,
+		})
+	}
+
+	return result, nil
+}
+
+// validateStaticFiles validates a package's static file rules and the static files directory, if it exists.
+func validateStaticFiles(packageDir string, rules *StaticFileRules) (err error) {
+	if rules != nil {
+		for _, pattern := range append(append([]string{}, rules.Include...), rules.Exclude...) {
+			if err = files.ValidateGlob(pattern); err != nil {
+				return fmt.Errorf("invalid static file rules in package information file: %s", err)
+			}
+		}
+	}
+
+	var staticFilesDir = GetStaticFilesDir(packageDir)
+	if _, err = os.Stat(staticFilesDir); os.IsNotExist(err) {
+		return nil
+	}
+	if err = files.DirExists(staticFilesDir, "static files"); err != nil {
+		return err
+	}
+
+	// Packages are copied and archived, so they can only contain regular files and directories
+	return filepath.WalkDir(staticFilesDir, func(filePath string, dirEntry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if !dirEntry.IsDir() && !dirEntry.Type().IsRegular() {
+			return fmt.Errorf("only regular files and directories are allowed in the \"%s\" directory: %s", constants.StaticFilesDirName, filePath)
+		}
+
+		return nil
+	})
+}
+
+// getStaticFilePaths returns the paths of the files in the static files directory which are selected by the given rules.
+func getStaticFilePaths(packageDir string, rules *StaticFileRules) (result []string, err error) {
+	result = []string{}
+
+	// If the static files directory doesn't exist, there are no static files
+	var staticFilesDir = GetStaticFilesDir(packageDir)
+	if files.DirExists(staticFilesDir, "static files") != nil {
+		return result, nil
+	}
+
+	if rules == nil {
+		rules = &StaticFileRules{}
+	}
+
+	err = filepath.WalkDir(staticFilesDir, func(filePath string, dirEntry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if dirEntry.IsDir() {
+			return nil
+		}
+
+		var relativePath, err = filepath.Rel(staticFilesDir, filePath)
+		if err != nil {
+			log.Panicf("Failed to get relative path of static file: %s", err)
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		// Check whether the file is selected
+		var isIncluded = len(rules.Include) == 0
+		if !isIncluded {
+			if isIncluded, err = files.MatchAnyGlob(rules.Include, relativePath); err != nil {
+				return err
+			}
+		}
+
+		var isExcluded bool
+		if isExcluded, err = files.MatchAnyGlob(rules.Exclude, relativePath); err != nil {
+			return err
+		}
+
+		if !isIncluded || isExcluded {
+			log.Debugf("Ignoring static file which is not selected by the static file rules: %s", relativePath)
+			return nil
+		}
+
+		result = append(result, relativePath)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
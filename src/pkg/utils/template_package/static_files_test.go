@@ -0,0 +1,84 @@
+package template_package
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestStaticFiles(t *testing.T) {
+	var createPackage = func(packageInfoYaml string, packageFiles map[string]string) string {
+		var packageDir = t.TempDir()
+		packageFiles["package.yaml"] = packageInfoYaml
+		packageFiles["interface.yaml"] = ""
+		packageFiles["parameters.yaml"] = ""
+		for relativePath, content := range packageFiles {
+			var filePath = filepath.Join(packageDir, filepath.FromSlash(relativePath))
+			So(os.MkdirAll(filepath.Dir(filePath), os.ModePerm), ShouldBeNil)
+			So(os.WriteFile(filePath, []byte(content), 0644), ShouldBeNil)
+		}
+
+		return packageDir
+	}
+
+	Convey("Static files are copied without being executed", t, func() {
+		var packageDir = createPackage("name: test/static\nversion: 1.0.0\n", map[string]string{
+			"files/dashboards/a.json": `{"title": "{{ not a template }}"}`,
+		})
+
+		var _, err = GetPackageInfo(packageDir)
+		So(err, ShouldBeNil)
+
+		var staticFiles []*OutputFile
+		staticFiles, err = GetStaticFiles(packageDir)
+		So(err, ShouldBeNil)
+		So(staticFiles, ShouldHaveLength, 1)
+		So(staticFiles[0].Path, ShouldEqual, "dashboards/a.json")
+		So(staticFiles[0].Source, ShouldEqual, "files/dashboards/a.json")
+		So(string(staticFiles[0].Data), ShouldEqual, `{"title": "{{ not a template }}"}`)
+	})
+
+	Convey("Static file rules choose which files are copied", t, func() {
+		var packageDir = createPackage(`name: test/static
+version: 1.0.0
+files:
+  include: ["dashboards/**", "missing/*"]
+  exclude: ["**/*.bak"]
+`, map[string]string{
+			"files/dashboards/a.json":          "a",
+			"files/dashboards/team/b.json":     "b",
+			"files/dashboards/team/b.json.bak": "old b",
+			"files/other/c.txt":                "c",
+		})
+
+		var _, err = GetPackageInfo(packageDir)
+		So(err, ShouldBeNil)
+
+		var staticFilePaths []string
+		staticFilePaths, err = GetStaticFilePaths(packageDir)
+		So(err, ShouldBeNil)
+		So(staticFilePaths, ShouldResemble, []string{"dashboards/a.json", "dashboards/team/b.json"})
+
+		var unmatchedIncludes []string
+		unmatchedIncludes, err = GetUnmatchedStaticFileIncludes(packageDir)
+		So(err, ShouldBeNil)
+		So(unmatchedIncludes, ShouldResemble, []string{"missing/*"})
+	})
+
+	Convey("Invalid static file rules are rejected", t, func() {
+		var packageDir = createPackage("name: test/static\nversion: 1.0.0\nfiles:\n  include: [\"../*\"]\n", map[string]string{})
+
+		var _, err = GetPackageInfo(packageDir)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Two output files can't have the same path", t, func() {
+		var staticFiles = []*OutputFile{{Path: "a.txt", Source: "files/a.txt"}}
+		var templateFiles = []*OutputFile{{Path: "a.txt", Source: "templates/a.txt"}}
+
+		var _, err = GetOutputFiles(nil, append(staticFiles, templateFiles...), &map[string]any{})
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -6,6 +6,24 @@ type PackageInfo struct {
 	Version string `yaml:"version" json:"version"`
 }
 
+// packageInfoFileContent is the structure of the package information file.
+type packageInfoFileContent struct {
+	PackageInfo
+
+	// Files chooses which files in the static files directory are copied to the output.
+	Files *StaticFileRules `yaml:"files,omitempty" json:"files,omitempty"`
+}
+
+// StaticFileRules chooses which files in the static files directory are copied to the output.  Patterns are globs
+// relative to the static files directory.
+type StaticFileRules struct {
+	// Include patterns select the files to copy.  If there are none, all files are selected.
+	Include []string `yaml:"include,omitempty" json:"include,omitempty"`
+
+	// Exclude patterns remove files from the selected files.
+	Exclude []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+}
+
 // PackageDefinition contains the information required to execute a template package.
 type PackageDefinition struct {
 	PackageInfo *PackageInfo    `yaml:"package" json:"package"`
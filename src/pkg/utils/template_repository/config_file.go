@@ -0,0 +1,189 @@
+package template_repository
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+)
+
+// configFieldRepositories is the key of the list of repositories in KPM configuration files.
+const configFieldRepositories = "repositories"
+
+// AddRepositoryToConfigFile adds a repository to the end of the list of repositories in a KPM configuration file.
+// The connection information is parsed as YAML, so it may be a plain string (e.g. a path) or a mapping.  The file
+// is created if it doesn't exist.
+func AddRepositoryToConfigFile(configFilePath string, repoName string, repoType string, connection string) error {
+	// Create and validate the repository information.
+	var repoInfo = &repositoryInfo{Name: repoName, Type: repoType}
+	if repoName == "" {
+		return fmt.Errorf("repository name cannot be empty")
+	}
+
+	var connectionDoc yaml.Node
+	if err := yaml.Unmarshal([]byte(connection), &connectionDoc); err != nil {
+		return fmt.Errorf("connection information is not valid YAML: %s", err)
+	}
+	if len(connectionDoc.Content) > 0 {
+		repoInfo.ConnectionInfo = *connectionDoc.Content[0]
+		clearStyle(&repoInfo.ConnectionInfo)
+	}
+
+	if _, err := repoInfo.ToRepository(); err != nil {
+		return err
+	}
+
+	var repoInfoNode = new(yaml.Node)
+	if err := repoInfoNode.Encode(repoInfo); err != nil {
+		log.Panicf("Failed to encode repository information: %s", err)
+	}
+
+	return updateRepositoriesInConfigFile(configFilePath, func(repoNodes []*yaml.Node) ([]*yaml.Node, error) {
+		if index, err := findRepositoryNode(repoNodes, repoName); err != nil {
+			return nil, err
+		} else if index >= 0 {
+			return nil, fmt.Errorf("repository named '%s' already exists in configuration file: %s", repoName, configFilePath)
+		}
+
+		return append(repoNodes, repoInfoNode), nil
+	})
+}
+
+// RemoveRepositoryFromConfigFile removes a repository from the list of repositories in a KPM configuration file.
+func RemoveRepositoryFromConfigFile(configFilePath string, repoName string) error {
+	return updateRepositoriesInConfigFile(configFilePath, func(repoNodes []*yaml.Node) ([]*yaml.Node, error) {
+		var index, err = findRepositoryNode(repoNodes, repoName)
+		if err != nil {
+			return nil, err
+		} else if index < 0 {
+			return nil, fmt.Errorf("unknown repository '%s' in configuration file: %s", repoName, configFilePath)
+		}
+
+		return append(repoNodes[:index], repoNodes[index+1:]...), nil
+	})
+}
+
+// SetDefaultRepositoryInConfigFile moves a repository to the start of the list of repositories in a KPM configuration
+// file, which makes it the default repository.
+func SetDefaultRepositoryInConfigFile(configFilePath string, repoName string) error {
+	return updateRepositoriesInConfigFile(configFilePath, func(repoNodes []*yaml.Node) ([]*yaml.Node, error) {
+		var index, err = findRepositoryNode(repoNodes, repoName)
+		if err != nil {
+			return nil, err
+		} else if index < 0 {
+			return nil, fmt.Errorf("unknown repository '%s' in configuration file: %s", repoName, configFilePath)
+		}
+
+		var result = []*yaml.Node{repoNodes[index]}
+		result = append(result, repoNodes[:index]...)
+		result = append(result, repoNodes[index+1:]...)
+
+		return result, nil
+	})
+}
+
+// updateRepositoriesInConfigFile edits the list of repositories in a KPM configuration file.  The rest of the file
+// (including comments) is kept as it is.
+func updateRepositoriesInConfigFile(
+	configFilePath string,
+	updateFunc func(repoNodes []*yaml.Node) ([]*yaml.Node, error),
+) (err error) {
+	// Read the file if it exists.
+	var doc yaml.Node
+	if files.FileExists(configFilePath, "KPM configuration") == nil {
+		var yamlBytes []byte
+		yamlBytes, err = files.ReadBytes(configFilePath)
+		if err != nil {
+			return err
+		}
+
+		if err = yaml.Unmarshal(yamlBytes, &doc); err != nil {
+			return fmt.Errorf("failed to parse configuration file at '%s': %s", configFilePath, err)
+		}
+	}
+
+	// Start a new document if the file is empty.
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	var root = doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("configuration file at '%s' must contain a mapping", configFilePath)
+	}
+
+	// Find the list of repositories, or add it if it doesn't exist.
+	var repositoriesNode *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == configFieldRepositories {
+			repositoriesNode = root.Content[i+1]
+			break
+		}
+	}
+	if repositoriesNode == nil {
+		repositoriesNode = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(
+			root.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: configFieldRepositories},
+			repositoriesNode,
+		)
+	}
+
+	// An empty value (i.e. "repositories:") is null, so turn it into a list.
+	if repositoriesNode.Kind == yaml.ScalarNode && repositoriesNode.Tag == "!!null" {
+		repositoriesNode.Kind, repositoriesNode.Tag, repositoriesNode.Value = yaml.SequenceNode, "!!seq", ""
+	}
+	if repositoriesNode.Kind != yaml.SequenceNode {
+		return fmt.Errorf("'%s' in configuration file at '%s' must be a list", configFieldRepositories, configFilePath)
+	}
+
+	repositoriesNode.Content, err = updateFunc(repositoriesNode.Content)
+	if err != nil {
+		return err
+	}
+
+	// Write the file.
+	var output bytes.Buffer
+	var encoder = yaml.NewEncoder(&output)
+	encoder.SetIndent(2)
+	if err = encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to serialize configuration: %s", err)
+	}
+	if err = encoder.Close(); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(configFilePath), os.ModePerm); err != nil {
+		return err
+	}
+
+	return os.WriteFile(configFilePath, output.Bytes(), 0644)
+}
+
+// findRepositoryNode returns the index of the repository with the given name, or -1 if it isn't in the list.
+func findRepositoryNode(repoNodes []*yaml.Node, repoName string) (int, error) {
+	for i, repoNode := range repoNodes {
+		var repoInfo repositoryInfo
+		if err := repoNode.Decode(&repoInfo); err != nil {
+			return -1, fmt.Errorf("invalid repository information on line %d: %s", repoNode.Line, err)
+		}
+
+		if repoInfo.Name == repoName {
+			return i, nil
+		}
+	}
+
+	return -1, nil
+}
+
+// clearStyle removes styles (e.g. flow style) from a node and its children, so it is written in the same style as the rest of the file.
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
@@ -0,0 +1,91 @@
+package template_repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRepositoryConfigFile(t *testing.T) {
+	Convey("Given a configuration file with comments", t, func() {
+		var repoDir = t.TempDir()
+		var configFilePath = filepath.Join(t.TempDir(), ".kpm.yaml")
+		So(os.WriteFile(configFilePath, []byte(fmt.Sprintf(`# KPM settings
+logLevel: debug # noisy
+repositories:
+  # The team repository
+  - name: team
+    type: filesystem
+    connection: %s
+`, repoDir)), 0644), ShouldBeNil)
+
+		var readRepositoryNames = func() []string {
+			var yamlBytes, err = os.ReadFile(configFilePath)
+			So(err, ShouldBeNil)
+
+			var config struct {
+				Repositories *RepositoryCollection `yaml:"repositories"`
+			}
+			So(yaml.Unmarshal(yamlBytes, &config), ShouldBeNil)
+
+			return config.Repositories.GetRepositoryNames()
+		}
+
+		Convey("Repositories can be added, made the default and removed", func() {
+			So(AddRepositoryToConfigFile(configFilePath, "personal", "filesystem", repoDir), ShouldBeNil)
+			So(readRepositoryNames(), ShouldResemble, []string{"team", "personal"})
+
+			So(SetDefaultRepositoryInConfigFile(configFilePath, "personal"), ShouldBeNil)
+			So(readRepositoryNames(), ShouldResemble, []string{"personal", "team"})
+
+			So(RemoveRepositoryFromConfigFile(configFilePath, "team"), ShouldBeNil)
+			So(readRepositoryNames(), ShouldResemble, []string{"personal"})
+
+			var yamlBytes, err = os.ReadFile(configFilePath)
+			So(err, ShouldBeNil)
+			So(string(yamlBytes), ShouldContainSubstring, "# KPM settings")
+			So(string(yamlBytes), ShouldContainSubstring, "logLevel: debug # noisy")
+		})
+
+		Convey("Mapping connection information is supported", func() {
+			var connection = fmt.Sprintf("{url: %s}", "https://packages.example.com/kpm/")
+			So(AddRepositoryToConfigFile(configFilePath, "web", "http", connection), ShouldBeNil)
+			So(readRepositoryNames(), ShouldResemble, []string{"team", "web"})
+		})
+
+		Convey("Invalid repositories are not saved", func() {
+			So(AddRepositoryToConfigFile(configFilePath, "team", "filesystem", repoDir), ShouldNotBeNil)
+			So(AddRepositoryToConfigFile(configFilePath, "missing", "filesystem", filepath.Join(repoDir, "missing")), ShouldNotBeNil)
+			So(AddRepositoryToConfigFile(configFilePath, "unknown", "unknown", repoDir), ShouldNotBeNil)
+			So(RemoveRepositoryFromConfigFile(configFilePath, "missing"), ShouldNotBeNil)
+			So(readRepositoryNames(), ShouldResemble, []string{"team"})
+		})
+
+		Convey("The collection can be serialized", func() {
+			var yamlBytes, err = os.ReadFile(configFilePath)
+			So(err, ShouldBeNil)
+
+			var config struct {
+				Repositories *RepositoryCollection `yaml:"repositories"`
+			}
+			So(yaml.Unmarshal(yamlBytes, &config), ShouldBeNil)
+
+			yamlBytes, err = yaml.Marshal(&config)
+			So(err, ShouldBeNil)
+			So(string(yamlBytes), ShouldContainSubstring, "name: team")
+		})
+	})
+
+	Convey("A missing configuration file is created", t, func() {
+		var configFilePath = filepath.Join(t.TempDir(), "home", ".kpm.yaml")
+		So(AddRepositoryToConfigFile(configFilePath, "local", "filesystem", t.TempDir()), ShouldBeNil)
+
+		var yamlBytes, err = os.ReadFile(configFilePath)
+		So(err, ShouldBeNil)
+		So(string(yamlBytes), ShouldStartWith, "repositories:\n  - name: local\n")
+	})
+}
@@ -0,0 +1,120 @@
+package template_repository
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/archive"
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/oci"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/versions"
+)
+
+// PackageIndexApiVersion is the version of the package index format.
+const PackageIndexApiVersion = "v1"
+
+// PackageIndex is the structure of the index file which lists the packages in an HTTP repository.
+type PackageIndex struct {
+	ApiVersion string                         `yaml:"apiVersion" json:"apiVersion"`
+	Packages   map[string][]PackageIndexEntry `yaml:"packages" json:"packages"`
+}
+
+// PackageIndexEntry describes the archive of a single package version.
+type PackageIndexEntry struct {
+	Version string `yaml:"version" json:"version"`
+
+	// Path is the location of the archive, relative to the index file (or an absolute URL).
+	Path string `yaml:"path" json:"path"`
+
+	// Digest is the digest of the archive, in the form "sha256:<hex>".
+	Digest string `yaml:"digest" json:"digest"`
+}
+
+// GeneratePackageIndex creates an index of all package archives in a directory (including sub-directories).
+func GeneratePackageIndex(repoDir string) (result *PackageIndex, err error) {
+	result = &PackageIndex{
+		ApiVersion: PackageIndexApiVersion,
+		Packages:   map[string][]PackageIndexEntry{},
+	}
+
+	err = filepath.WalkDir(repoDir, func(path string, dirEntry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), constants.PackageArchiveExtension) {
+			return nil
+		}
+
+		var packageInfo, digest, err = readPackageArchive(path)
+		if err != nil {
+			return fmt.Errorf("invalid package archive '%s': %s", path, err)
+		}
+
+		var relativePath string
+		relativePath, err = filepath.Rel(repoDir, path)
+		if err != nil {
+			return err
+		}
+
+		// Don't allow the same package version to be listed twice.
+		for _, entry := range result.Packages[packageInfo.Name] {
+			if entry.Version == packageInfo.Version {
+				return fmt.Errorf("package '%s' is in multiple archives: %s, %s", packageInfo, entry.Path, filepath.ToSlash(relativePath))
+			}
+		}
+
+		result.Packages[packageInfo.Name] = append(result.Packages[packageInfo.Name], PackageIndexEntry{
+			Version: packageInfo.Version,
+			Path:    filepath.ToSlash(relativePath),
+			Digest:  digest,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entries := range result.Packages {
+		sort.Slice(entries, func(i, j int) bool {
+			return versions.Compare(entries[i].Version, entries[j].Version) < 0
+		})
+	}
+
+	return result, nil
+}
+
+// readPackageArchive validates a package archive, and returns the package info and the archive's digest.
+func readPackageArchive(archivePath string) (packageInfo *template_package.PackageInfo, digest string, err error) {
+	var archiveBytes []byte
+	archiveBytes, err = os.ReadFile(archivePath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var tempDir string
+	tempDir, err = os.MkdirTemp("", "kpm-index-")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(tempDir)
+
+	_, err = archive.ExtractPackageArchive(bytes.NewReader(archiveBytes), tempDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	packageInfo, err = template_package.GetPackageInfo(tempDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return packageInfo, oci.GetDigest(archiveBytes), nil
+}
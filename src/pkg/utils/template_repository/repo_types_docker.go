@@ -1,14 +1,18 @@
 package template_repository
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/rohitramu/kpm/src/pkg/utils/docker"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/oci"
 	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/validation"
 )
 
-// TODO: Implement Docker repository support.
-
 const repositoryTypeNameDocker = "docker"
 
 var _ Repository = &dockerRepository{}
@@ -19,9 +23,18 @@ type dockerRepository struct {
 }
 
 type dockerRepositoryConnectionInfo struct {
-	username     string
-	organization string
-	registry     string
+	// Username is the account which owns the images.  It is used as the namespace if an organization is not provided.
+	Username string `yaml:"username"`
+
+	// Organization is the namespace which contains the images.
+	Organization string `yaml:"organization"`
+
+	// Registry is the host name of the Docker registry.  Defaults to Docker Hub.
+	Registry string `yaml:"registry"`
+
+	// Password is used with the username to authenticate with the registry when listing images (optional).  Environment
+	// variables are expanded, so secrets don't need to be written to the config file (e.g. "$DOCKER_TOKEN").
+	Password string `yaml:"password"`
 }
 
 func (repo *dockerRepository) GetName() string {
@@ -33,28 +46,184 @@ func (repo *dockerRepository) GetType() string {
 }
 
 func (repo *dockerRepository) FindPackages(
+	kpmHomeDir string,
 	ch chan<- *template_package.PackageInfo,
-	searchTeam string,
-) error {
-	return fmt.Errorf("not yet implemented")
+	searchTerm string,
+) (err error) {
+	// Get the names of the Docker repositories in the namespace.
+	var imageRepositories []string
+	imageRepositories, err = collectFromChannel(func(repositoriesCh chan<- string) error {
+		return docker.GetRepositories(repositoriesCh, repo.connectionInfo.Registry, repo.getNamespace(), repo.getUsername(), repo.getPassword())
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, imageRepository := range imageRepositories {
+		// Ignore Docker repositories which can't be template packages.
+		var packageName = repo.getPackageName(imageRepository)
+		if validation.ValidatePackageName(packageName) != nil {
+			log.Debugf("Ignoring Docker repository which is not a valid package name: %s", imageRepository)
+			continue
+		}
+
+		// If the package name doesn't contain the search term, ignore it.
+		if searchTerm != "" && !strings.Contains(packageName, searchTerm) {
+			continue
+		}
+
+		// Every tag which is a valid package version is a package.
+		var versions []string
+		versions, err = collectFromChannel(func(versionsCh chan<- string) error {
+			return repo.PackageVersions(kpmHomeDir, versionsCh, packageName)
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			ch <- &template_package.PackageInfo{Name: packageName, Version: version}
+		}
+	}
+
+	return nil
 }
 
-func (repo *dockerRepository) PackageVersions(ch chan<- string, packageName string) (err error) {
-	return docker.GetImageTags(ch, packageName, repo.connectionInfo.registry)
+func (repo *dockerRepository) PackageVersions(kpmHomeDir string, ch chan<- string, packageName string) (err error) {
+	var tags []string
+	tags, err = collectFromChannel(func(tagsCh chan<- string) error {
+		return docker.GetImageTags(tagsCh, repo.getImageRepository(packageName), repo.connectionInfo.Registry, repo.getUsername(), repo.getPassword())
+	})
+	if err != nil {
+		if errors.Is(err, docker.ErrImageNotFound) {
+			return errors.Join(PackageNotFoundError{PackageInfo: template_package.PackageInfo{Name: packageName}}, err)
+		}
+
+		return err
+	}
+
+	for _, tag := range tags {
+		var version = oci.TagToVersion(tag)
+		if validation.ValidatePackageVersion(version) != nil {
+			log.Debugf("Ignoring tag which is not a valid package version: %s", tag)
+			continue
+		}
+
+		ch <- version
+	}
+
+	return nil
 }
 
-func (repo dockerRepository) Push(
+func (repo *dockerRepository) Push(
 	kpmHomeDir string,
 	packageInfo *template_package.PackageInfo,
-) error {
-	return fmt.Errorf("not yet implemented")
+) (err error) {
+	if packageInfo == nil {
+		log.Panicf("packageInfo is nil")
+	}
+
+	// Make sure the package exists locally.
+	var packageDir = template_package.GetPackageDir(
+		kpmHomeDir,
+		template_package.GetPackageFullName(packageInfo.Name, packageInfo.Version),
+	)
+	if _, err = template_package.GetPackageInfo(packageDir); err != nil {
+		return err
+	}
+
+	// Build the image.
+	var imageName = repo.getImageName(packageInfo)
+	err = docker.BuildImage(imageName, docker.GetDockerfilePath(kpmHomeDir), packageDir)
+	if err != nil {
+		return err
+	}
+
+	// Don't keep the image around after it has been pushed.
+	defer repo.deleteImage(imageName)
+
+	return docker.PushImage(imageName)
 }
 
 func (repo *dockerRepository) Pull(
 	kpmHomeDir string,
 	packageInfo *template_package.PackageInfo,
-) error {
-	return fmt.Errorf("not yet implemented")
+) (err error) {
+	if packageInfo == nil {
+		log.Panicf("packageInfo is nil")
+	}
+
+	// Pull the image.
+	var imageName = repo.getImageName(packageInfo)
+	err = docker.PullImage(imageName)
+	if err != nil {
+		if errors.Is(err, docker.ErrImageNotFound) {
+			return errors.Join(PackageNotFoundError{PackageInfo: *packageInfo}, err)
+		}
+
+		return err
+	}
+
+	// Don't keep the image around after the package has been extracted.
+	defer repo.deleteImage(imageName)
+
+	// Extract the package into the KPM home directory, after making sure that the image contains a valid template package.
+	return installPackage(kpmHomeDir, packageInfo, func(dstDir string) error {
+		return docker.ExtractImageContents(imageName, dstDir)
+	})
+}
+
+// getNamespace returns the namespace in the registry which contains this repository's images.
+func (repo *dockerRepository) getNamespace() string {
+	if repo.connectionInfo.Organization != "" {
+		return repo.connectionInfo.Organization
+	}
+
+	return repo.connectionInfo.Username
+}
+
+// getUsername returns the username which is used to authenticate with the registry.
+func (repo *dockerRepository) getUsername() string {
+	return os.ExpandEnv(repo.connectionInfo.Username)
+}
+
+// getPassword returns the password which is used to authenticate with the registry.
+func (repo *dockerRepository) getPassword() string {
+	return os.ExpandEnv(repo.connectionInfo.Password)
+}
+
+// getImageRepository returns the name of the Docker repository (i.e. image name without registry or tag) for a package.
+func (repo *dockerRepository) getImageRepository(packageName string) string {
+	var namespace = repo.getNamespace()
+	if namespace == "" {
+		return packageName
+	}
+
+	return fmt.Sprintf("%s/%s", namespace, packageName)
+}
+
+// getPackageName is the inverse of getImageRepository.
+func (repo *dockerRepository) getPackageName(imageRepository string) string {
+	var namespace = repo.getNamespace()
+	if namespace == "" {
+		return imageRepository
+	}
+
+	return strings.TrimPrefix(imageRepository, namespace+"/")
+}
+
+func (repo *dockerRepository) getImageName(packageInfo *template_package.PackageInfo) string {
+	return docker.GetImageName(
+		repo.connectionInfo.Registry,
+		repo.getImageRepository(packageInfo.Name),
+		oci.VersionToTag(packageInfo.Version),
+	)
+}
+
+func (repo *dockerRepository) deleteImage(imageName string) {
+	if err := docker.DeleteImage(imageName); err != nil {
+		log.Warningf("Failed to clean up local image: %s", err)
+	}
 }
 
 func repoInfoToDockerRepo(repoInfo *repositoryInfo) (Repository, error) {
@@ -68,6 +237,21 @@ func repoInfoToDockerRepo(repoInfo *repositoryInfo) (Repository, error) {
 		return result, fmt.Errorf("docker repository connection info is not a valid structure")
 	}
 
+	if connectionInfo.Registry == "" {
+		connectionInfo.Registry = docker.DefaultDockerRegistry
+	}
+
+	// Namespaces must be valid so that image names are valid.
+	var namespace = connectionInfo.Organization
+	if namespace == "" {
+		namespace = connectionInfo.Username
+	}
+	if namespace != "" {
+		if err = oci.ValidateRepositoryName(namespace); err != nil {
+			return result, fmt.Errorf("invalid docker repository namespace: %s", err)
+		}
+	}
+
 	result.connectionInfo = connectionInfo
 
 	return result, nil
@@ -0,0 +1,214 @@
+package template_repository
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/docker"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/oci/ocitest"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+// fakeDockerCli imitates the commands of the docker CLI which KPM uses.  Images are directories which contain the build
+// context, so the build context is what gets extracted from "/.kpm" in a container.  Local images are stored in
+// "$KPM_TEST_DOCKER_DIR/local", and pushed images are stored in "$KPM_TEST_DOCKER_DIR/remote".
+const fakeDockerCli = `#!/bin/sh
+set -e
+dir="$KPM_TEST_DOCKER_DIR"
+key() { printf '%s' "$1" | tr '/:' '__'; }
+case "$1" in
+build)
+	# build --force-rm --file <dockerfile> --tag <image> <context>
+	rm -rf "$dir/local/$(key "$6")"
+	cp -R "$7" "$dir/local/$(key "$6")"
+	;;
+push)
+	test -d "$dir/local/$(key "$2")"
+	rm -rf "$dir/remote/$(key "$2")"
+	cp -R "$dir/local/$(key "$2")" "$dir/remote/$(key "$2")"
+	;;
+pull)
+	if [ ! -d "$dir/remote/$(key "$2")" ]; then
+		echo "Error response from daemon: manifest unknown"
+		exit 1
+	fi
+	rm -rf "$dir/local/$(key "$2")"
+	cp -R "$dir/remote/$(key "$2")" "$dir/local/$(key "$2")"
+	;;
+create)
+	# create --name <container> <image>
+	test -d "$dir/local/$(key "$4")"
+	printf '%s' "$(key "$4")" > "$dir/containers/$3"
+	;;
+cp)
+	# cp <container>:/.kpm/. <destination>
+	container="${2%%:*}"
+	cp -R "$dir/local/$(cat "$dir/containers/$container")/." "$3"
+	;;
+rm)
+	rm -f "$dir/containers/$3"
+	;;
+image)
+	rm -rf "$dir/local/$(key "$4")"
+	;;
+*)
+	echo "unexpected command: $*"
+	exit 1
+	;;
+esac
+`
+
+// useFakeDockerCli puts the fake docker CLI on the path, and returns the directory which contains its state.
+func useFakeDockerCli(t *testing.T) string {
+	t.Helper()
+
+	var binDir = t.TempDir()
+	if err := os.WriteFile(filepath.Join(binDir, "docker"), []byte(fakeDockerCli), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	var stateDir = t.TempDir()
+	for _, subDir := range []string{"local", "remote", "containers"} {
+		if err := os.Mkdir(filepath.Join(stateDir, subDir), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("KPM_TEST_DOCKER_DIR", stateDir)
+
+	return stateDir
+}
+
+func TestDockerRepository(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake docker CLI is a shell script")
+	}
+
+	var dockerDir = useFakeDockerCli(t)
+
+	Convey("Given a Docker registry", t, func() {
+		var registry = ocitest.NewRegistryWithTokenAuth("kpm", "secret")
+		Reset(registry.Close)
+
+		t.Setenv("KPM_TEST_REGISTRY_PASSWORD", "secret")
+		var repo = newTestRepository(t, fmt.Sprintf(`
+name: registry
+type: docker
+connection:
+  registry: %s
+  organization: my-org
+  username: kpm
+  password: $KPM_TEST_REGISTRY_PASSWORD
+`, registry.Url()))
+
+		var getImageName = func(packageName string, version string) string {
+			return docker.GetImageName(registry.Url(), "my-org/"+packageName, version)
+		}
+		var getRemoteImageDir = func(imageName string) string {
+			return filepath.Join(dockerDir, "remote", strings.NewReplacer("/", "_", ":", "_").Replace(imageName))
+		}
+
+		Convey("Packages are found from the tags in the organization", func() {
+			registry.PutTag("my-org/kpmtool/hello", "1.0.0")
+			registry.PutTag("my-org/kpmtool/hello", "1.1.0_build.1")
+			registry.PutTag("my-org/kpmtool/hello", "latest")
+			registry.PutTag("my-org/kpmtool/other", "2.0.0")
+			registry.PutTag("my-org/Invalid_Name", "1.0.0")
+			registry.PutTag("other-org/kpmtool/hello", "3.0.0")
+
+			var found, err = collectFromChannel(func(ch chan<- *template_package.PackageInfo) error {
+				return repo.FindPackages(t.TempDir(), ch, "")
+			})
+			So(err, ShouldBeNil)
+			So(found, ShouldResemble, []*template_package.PackageInfo{
+				{Name: "kpmtool/hello", Version: "1.0.0"},
+				{Name: "kpmtool/hello", Version: "1.1.0+build.1"},
+				{Name: "kpmtool/other", Version: "2.0.0"},
+			})
+
+			Convey("Only packages whose names contain the search term are found", func() {
+				found, err = collectFromChannel(func(ch chan<- *template_package.PackageInfo) error {
+					return repo.FindPackages(t.TempDir(), ch, "other")
+				})
+				So(err, ShouldBeNil)
+				So(found, ShouldResemble, []*template_package.PackageInfo{
+					{Name: "kpmtool/other", Version: "2.0.0"},
+				})
+			})
+		})
+
+		Convey("Listing the versions of a missing package returns PackageNotFoundError", func() {
+			var _, err = collectFromChannel(func(ch chan<- string) error {
+				return repo.PackageVersions(t.TempDir(), ch, "kpmtool/missing")
+			})
+			So(err, ShouldWrap, PackageNotFoundError{})
+		})
+
+		Convey("Packages can be pushed and pulled", func() {
+			var publisherHomeDir = t.TempDir()
+			createTestPackage(t, publisherHomeDir, "kpmtool/hello", "1.0.0")
+
+			var packageInfo = &template_package.PackageInfo{Name: "kpmtool/hello", Version: "1.0.0"}
+			So(repo.Push(publisherHomeDir, packageInfo), ShouldBeNil)
+			var _, err = os.Stat(getRemoteImageDir(getImageName("kpmtool/hello", "1.0.0")))
+			So(err, ShouldBeNil)
+
+			var consumerHomeDir = t.TempDir()
+			So(repo.Pull(consumerHomeDir, packageInfo), ShouldBeNil)
+
+			var pulledInfo *template_package.PackageInfo
+			pulledInfo, err = template_package.GetPackageInfo(template_package.GetPackageDir(consumerHomeDir, "kpmtool/hello-1.0.0"))
+			So(err, ShouldBeNil)
+			So(pulledInfo, ShouldResemble, packageInfo)
+
+			// The local images are deleted after they are pushed or pulled.
+			var localImages []os.DirEntry
+			localImages, err = os.ReadDir(filepath.Join(dockerDir, "local"))
+			So(err, ShouldBeNil)
+			So(localImages, ShouldBeEmpty)
+		})
+
+		Convey("Pushing a package which is not in the KPM home directory fails", func() {
+			var err = repo.Push(t.TempDir(), &template_package.PackageInfo{Name: "kpmtool/hello", Version: "1.0.0"})
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Pulling a missing package returns PackageNotFoundError", func() {
+			var err = repo.Pull(t.TempDir(), &template_package.PackageInfo{Name: "kpmtool/missing", Version: "1.0.0"})
+			So(err, ShouldWrap, PackageNotFoundError{})
+		})
+
+		Convey("Pulling an image which contains a different package fails without installing it", func() {
+			var publisherHomeDir = t.TempDir()
+			createTestPackage(t, publisherHomeDir, "kpmtool/hello", "1.0.0")
+
+			var imageDir = getRemoteImageDir(getImageName("kpmtool/hello", "2.0.0"))
+			So(files.CopyDir(template_package.GetPackageDir(publisherHomeDir, "kpmtool/hello-1.0.0"), imageDir), ShouldBeNil)
+
+			var consumerHomeDir = t.TempDir()
+			var err = repo.Pull(consumerHomeDir, &template_package.PackageInfo{Name: "kpmtool/hello", Version: "2.0.0"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "expected package 'kpmtool/hello-2.0.0' but found package 'kpmtool/hello-1.0.0'")
+			_, err = os.Stat(template_package.GetPackageDir(consumerHomeDir, "kpmtool/hello-2.0.0"))
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+
+		Convey("Pulling an image which doesn't contain a template package fails", func() {
+			var imageDir = getRemoteImageDir(getImageName("kpmtool/hello", "3.0.0"))
+			So(os.MkdirAll(imageDir, os.ModePerm), ShouldBeNil)
+			So(os.WriteFile(filepath.Join(imageDir, constants.ParametersFileName), []byte("{}\n"), 0644), ShouldBeNil)
+
+			var err = repo.Pull(t.TempDir(), &template_package.PackageInfo{Name: "kpmtool/hello", Version: "3.0.0"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "is not a valid template package")
+		})
+	})
+}
@@ -3,11 +3,18 @@ package template_repository
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 
+	"github.com/emirpasic/gods/sets/treeset"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/archive"
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
 	"github.com/rohitramu/kpm/src/pkg/utils/files"
 	"github.com/rohitramu/kpm/src/pkg/utils/log"
 	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/validation"
+	"github.com/rohitramu/kpm/src/pkg/utils/versions"
 )
 
 const repositoryTypeNameFilesystem = "filesystem"
@@ -28,6 +35,7 @@ func (repo *filesystemRepository) GetType() string {
 }
 
 func (repo *filesystemRepository) FindPackages(
+	kpmHomeDir string,
 	ch chan<- *template_package.PackageInfo,
 	searchTerm string,
 ) (err error) {
@@ -43,6 +51,7 @@ func (repo *filesystemRepository) FindPackages(
 		)
 	}
 
+	var foundPackages = map[template_package.PackageInfo]bool{}
 	for _, fullPackageName := range fullPackageNames {
 		var packageDirAbsPath = template_package.GetPackageDir(repo.absoluteFilePath, fullPackageName)
 		var packageInfo *template_package.PackageInfo
@@ -56,6 +65,21 @@ func (repo *filesystemRepository) FindPackages(
 			continue
 		}
 
+		foundPackages[*packageInfo] = true
+		ch <- packageInfo
+	}
+
+	// Packages may also be stored as archives.
+	for _, packageInfo := range repo.getArchivedPackages() {
+		if foundPackages[*packageInfo] {
+			continue
+		}
+
+		// If the package name doesn't contain the search term, ignore it.
+		if searchTerm != "" && !strings.Contains(packageInfo.Name, searchTerm) {
+			continue
+		}
+
 		ch <- packageInfo
 	}
 
@@ -63,17 +87,33 @@ func (repo *filesystemRepository) FindPackages(
 }
 
 func (repo *filesystemRepository) PackageVersions(
+	kpmHomeDir string,
 	ch chan<- string,
 	packageName string,
 ) (err error) {
+	var packageVersions = treeset.NewWith(func(a, b any) int {
+		return versions.Compare(a.(string), b.(string))
+	})
+
+	// Packages may also be stored as archives.
+	for _, packageInfo := range repo.getArchivedPackages() {
+		if packageInfo.Name == packageName {
+			packageVersions.Add(packageInfo.Version)
+		}
+	}
+
 	var result []string
 	result, err = template_package.GetPackageVersions(repo.absoluteFilePath, packageName)
-	if err != nil {
-		return err
+	if err != nil && packageVersions.Empty() {
+		return errors.Join(PackageNotFoundError{PackageInfo: template_package.PackageInfo{Name: packageName}}, err)
 	}
 
 	for _, r := range result {
-		ch <- r
+		packageVersions.Add(r)
+	}
+
+	for _, version := range packageVersions.Values() {
+		ch <- version.(string)
 	}
 
 	return nil
@@ -116,10 +156,18 @@ func (repo *filesystemRepository) Pull(
 		template_package.GetPackageFullName(packageInfo.Name, packageInfo.Version),
 	)
 
-	// If the directory doesn't exist, return an error.
+	// If the directory doesn't exist, try the archive instead.
 	err = files.DirExists(packageDirSrc, packageInfo.Name)
 	if err != nil {
-		return errors.Join(PackageNotFoundError{PackageInfo: *packageInfo})
+		var archivePath = packageDirSrc + constants.PackageArchiveExtension
+		if files.FileExists(archivePath, "package archive") != nil {
+			return errors.Join(PackageNotFoundError{PackageInfo: *packageInfo})
+		}
+
+		return installPackage(kpmHomeDir, packageInfo, func(dstDir string) error {
+			var _, err = archive.ExtractPackageArchiveFile(archivePath, dstDir)
+			return err
+		})
 	}
 
 	// Get the destination directory.
@@ -131,6 +179,42 @@ func (repo *filesystemRepository) Pull(
 	return copyPackage(packageDirSrc, packageDirDst)
 }
 
+// getArchivedPackages returns the packages in the repository which are stored as archives rather than directories.
+// The package info is based on the archive's file name - the contents are only checked when the package is pulled.
+func (repo *filesystemRepository) getArchivedPackages() []*template_package.PackageInfo {
+	var packagesDir = template_package.GetRepoPackagesDir(repo.absoluteFilePath)
+	var archivePaths, err = filepath.Glob(filepath.Join(packagesDir, "*", "*"+constants.PackageArchiveExtension))
+	if err != nil {
+		log.Panicf("Invalid glob pattern for package archives: %s", err)
+	}
+
+	var result []*template_package.PackageInfo
+	for _, archivePath := range archivePaths {
+		var relativePath string
+		relativePath, err = filepath.Rel(packagesDir, archivePath)
+		if err != nil {
+			log.Panicf("Unexpectedly failed to get relative path: filepath.Rel(\"%s\", \"%s\")", packagesDir, archivePath)
+		}
+
+		var packageFullName = strings.TrimSuffix(filepath.ToSlash(relativePath), constants.PackageArchiveExtension)
+		if !strings.Contains(packageFullName, "-") {
+			log.Warningf("Found package archive with an invalid name: %s", archivePath)
+			continue
+		}
+
+		var packageName, packageVersion string
+		packageName, packageVersion, err = validation.ExtractNameAndVersionFromPackageFullName(packageFullName)
+		if err != nil {
+			log.Warningf("Found package archive with an invalid name '%s': %s", archivePath, err)
+			continue
+		}
+
+		result = append(result, &template_package.PackageInfo{Name: packageName, Version: packageVersion})
+	}
+
+	return result
+}
+
 func copyPackage(packageDirSrc string, packageDirDst string) (err error) {
 	// Delete the destination directory.
 	if err = files.DeleteDirIfExists(packageDirDst, "destination template package", true); err != nil {
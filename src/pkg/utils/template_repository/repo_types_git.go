@@ -0,0 +1,264 @@
+package template_repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/git"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+const repositoryTypeNameGit = "git"
+
+// defaultGitBranch is the branch that is used when neither the user nor the remote specify one (i.e. the remote is empty).
+const defaultGitBranch = "main"
+
+var _ Repository = &gitRepository{}
+
+type gitRepository struct {
+	name           string
+	connectionInfo gitRepositoryConnectionInfo
+}
+
+type gitRepositoryConnectionInfo struct {
+	// Url is the location of the remote repository, in any form that "git clone" accepts.
+	Url string `yaml:"url"`
+
+	// Ref is the branch, tag or commit to use.  Defaults to the remote's default branch.
+	Ref string `yaml:"ref"`
+
+	// Path is the sub-directory inside the repository which contains the "packages" directory.
+	Path string `yaml:"path"`
+}
+
+func (repo *gitRepository) GetName() string {
+	return repo.name
+}
+
+func (repo *gitRepository) GetType() string {
+	return repositoryTypeNameGit
+}
+
+func (repo *gitRepository) FindPackages(
+	kpmHomeDir string,
+	ch chan<- *template_package.PackageInfo,
+	searchTerm string,
+) (err error) {
+	var packagesRepo *filesystemRepository
+	packagesRepo, _, err = repo.sync(kpmHomeDir)
+	if err != nil {
+		return err
+	}
+
+	// An empty repository has no packages.
+	if files.DirExists(template_package.GetRepoPackagesDir(packagesRepo.absoluteFilePath), "packages") != nil {
+		return nil
+	}
+
+	return packagesRepo.FindPackages(kpmHomeDir, ch, searchTerm)
+}
+
+func (repo *gitRepository) PackageVersions(
+	kpmHomeDir string,
+	ch chan<- string,
+	packageName string,
+) (err error) {
+	var packagesRepo *filesystemRepository
+	packagesRepo, _, err = repo.sync(kpmHomeDir)
+	if err != nil {
+		return err
+	}
+
+	if files.DirExists(template_package.GetRepoPackagesDir(packagesRepo.absoluteFilePath), "packages") != nil {
+		return PackageNotFoundError{PackageInfo: template_package.PackageInfo{Name: packageName}}
+	}
+
+	return packagesRepo.PackageVersions(kpmHomeDir, ch, packageName)
+}
+
+func (repo *gitRepository) Push(
+	kpmHomeDir string,
+	packageInfo *template_package.PackageInfo,
+) (err error) {
+	if packageInfo == nil {
+		log.Panicf("packageInfo is nil")
+	}
+
+	var packagesRepo *filesystemRepository
+	var branch string
+	packagesRepo, branch, err = repo.sync(kpmHomeDir)
+	if err != nil {
+		return err
+	}
+
+	// We can only push to branches.
+	if branch == "" {
+		return fmt.Errorf(
+			"cannot push to git repository '%s' because ref '%s' is not a branch",
+			repo.name,
+			repo.connectionInfo.Ref,
+		)
+	}
+
+	// Package versions are immutable, so don't overwrite one that was already pushed.
+	var packageFullName = template_package.GetPackageFullName(packageInfo.Name, packageInfo.Version)
+	var packageDirDst = template_package.GetPackageDir(packagesRepo.absoluteFilePath, packageFullName)
+	if files.DirExists(packageDirDst, "template package") == nil {
+		return fmt.Errorf("package '%s' already exists in git repository '%s'", packageFullName, repo.name)
+	}
+
+	// Copy the package into the clone.
+	err = packagesRepo.Push(kpmHomeDir, packageInfo)
+	if err != nil {
+		return err
+	}
+
+	// Commit and push the new package.
+	var cloneDir = repo.getCloneDir(kpmHomeDir)
+	var relativePackageDir string
+	relativePackageDir, err = filepath.Rel(cloneDir, packageDirDst)
+	if err != nil {
+		log.Panicf("Failed to get path of package relative to clone directory: %s", err)
+	}
+
+	err = git.CommitAll(cloneDir, filepath.ToSlash(relativePackageDir), fmt.Sprintf("Add package %s", packageFullName))
+	if err != nil {
+		return err
+	}
+
+	return git.PushBranch(cloneDir, branch)
+}
+
+func (repo *gitRepository) Pull(
+	kpmHomeDir string,
+	packageInfo *template_package.PackageInfo,
+) (err error) {
+	if packageInfo == nil {
+		log.Panicf("packageInfo is nil")
+	}
+
+	var packagesRepo *filesystemRepository
+	packagesRepo, _, err = repo.sync(kpmHomeDir)
+	if err != nil {
+		return err
+	}
+
+	return packagesRepo.Pull(kpmHomeDir, packageInfo)
+}
+
+// sync makes sure that the clone in the cache is up-to-date with the configured ref, and returns a filesystem
+// repository which points at the configured path inside the clone.  If the ref is a branch, its name is returned.
+func (repo *gitRepository) sync(kpmHomeDir string) (packagesRepo *filesystemRepository, branch string, err error) {
+	var cloneDir = repo.getCloneDir(kpmHomeDir)
+
+	// Throw away the clone if it points somewhere else (i.e. the URL was changed).
+	if files.DirExists(cloneDir, "git clone") == nil {
+		var remoteUrl string
+		remoteUrl, err = git.GetRemoteUrl(cloneDir)
+		if err != nil || remoteUrl != repo.connectionInfo.Url {
+			log.Debugf("Deleting stale clone of git repository '%s': %s", repo.name, cloneDir)
+			if err = files.DeleteDirIfExists(cloneDir, "git clone", true); err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	// Get a clone of the repository.
+	if files.DirExists(cloneDir, "git clone") != nil {
+		err = git.Clone(repo.connectionInfo.Url, cloneDir)
+		if err != nil {
+			return nil, "", err
+		}
+	} else {
+		err = git.Fetch(cloneDir)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// Work out which ref to use.
+	var ref = repo.connectionInfo.Ref
+	if ref == "" {
+		ref = git.GetDefaultBranch(cloneDir)
+	}
+	if ref == "" {
+		ref = defaultGitBranch
+	}
+
+	// Check out the ref.
+	switch git.ResolveRef(cloneDir, ref) {
+	case git.RefKindBranch:
+		err = git.CheckoutBranch(cloneDir, ref)
+		branch = ref
+	case git.RefKindTag, git.RefKindCommit:
+		err = git.CheckoutDetached(cloneDir, ref)
+	default:
+		// A ref which doesn't exist can only be used if the remote is empty and the ref can become its first branch.
+		if repo.connectionInfo.Ref != "" && git.GetDefaultBranch(cloneDir) != "" {
+			return nil, "", fmt.Errorf("failed to find ref '%s' in git repository '%s'", ref, repo.name)
+		}
+		err = git.CheckoutOrphan(cloneDir, ref)
+		branch = ref
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	packagesRepo = &filesystemRepository{
+		name:             repo.name,
+		absoluteFilePath: filepath.Join(cloneDir, filepath.FromSlash(repo.connectionInfo.Path)),
+	}
+
+	return packagesRepo, branch, nil
+}
+
+// getCloneDir returns the location of this repository's clone in the KPM home directory's cache.
+func (repo *gitRepository) getCloneDir(kpmHomeDir string) string {
+	// Key the clone on the URL so that repositories which are renamed or repointed don't share a clone.
+	var urlHash = sha256.Sum256([]byte(repo.connectionInfo.Url))
+
+	return filepath.Join(
+		kpmHomeDir,
+		constants.CacheDirName,
+		repositoryTypeNameGit,
+		hex.EncodeToString(urlHash[:])[:16],
+	)
+}
+
+func repoInfoToGitRepo(repoInfo *repositoryInfo) (Repository, error) {
+	if repoInfo == nil {
+		log.Panicf("repoInfo is nil")
+	}
+
+	var err error
+	var result = &gitRepository{name: repoInfo.Name}
+
+	var connectionInfo gitRepositoryConnectionInfo
+	err = repoInfo.ConnectionInfo.Decode(&connectionInfo)
+	if err != nil {
+		return result, fmt.Errorf("git repository connection info is not a valid structure: %s", err)
+	}
+
+	// The URL is required.
+	if strings.TrimSpace(connectionInfo.Url) == "" {
+		return result, errors.New("git repository connection info must include a URL")
+	}
+
+	// The sub-path must stay inside the repository.
+	var subPath = filepath.Clean(filepath.FromSlash(connectionInfo.Path))
+	if filepath.IsAbs(subPath) || subPath == ".." || strings.HasPrefix(subPath, ".."+string(filepath.Separator)) {
+		return result, fmt.Errorf("git repository path must be a relative path inside the repository: %s", connectionInfo.Path)
+	}
+	connectionInfo.Path = filepath.ToSlash(subPath)
+
+	result.connectionInfo = connectionInfo
+
+	return result, nil
+}
@@ -0,0 +1,111 @@
+package template_repository
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/git"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+func TestGitRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// Make sure commits work without any user-level git configuration, or an email address that git can guess.
+	var gitHomeDir = t.TempDir()
+	t.Setenv("HOME", gitHomeDir)
+	t.Setenv("XDG_CONFIG_HOME", gitHomeDir)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("EMAIL", "")
+	for _, name := range []string{"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(name, "")
+		if err := os.Unsetenv(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	Convey("Given an empty bare git repository", t, func() {
+		var remoteDir = filepath.Join(t.TempDir(), "remote.git")
+		var output, err = exec.Command("git", "init", "--bare", remoteDir).CombinedOutput()
+		So(err, ShouldBeNil)
+		output, err = exec.Command("git", "-C", remoteDir, "rev-parse", "--is-bare-repository").CombinedOutput()
+		So(err, ShouldBeNil)
+		So(strings.TrimSpace(string(output)), ShouldEqual, "true")
+
+		var repo = newTestRepository(t, fmt.Sprintf(`
+name: monorepo
+type: git
+connection:
+  url: file://%s
+  path: kpm/templates
+`, filepath.ToSlash(remoteDir)))
+
+		var publisherHomeDir = t.TempDir()
+		createTestPackage(t, publisherHomeDir, "kpmtool/hello", "1.0.0")
+		createTestPackage(t, publisherHomeDir, "kpmtool/hello", "1.1.0")
+		createTestPackage(t, publisherHomeDir, "kpmtool/other", "2.0.0")
+
+		Convey("Packages can be pushed, found and pulled", func() {
+			for _, packageInfo := range []template_package.PackageInfo{
+				{Name: "kpmtool/hello", Version: "1.0.0"},
+				{Name: "kpmtool/hello", Version: "1.1.0"},
+				{Name: "kpmtool/other", Version: "2.0.0"},
+			} {
+				So(repo.Push(publisherHomeDir, &packageInfo), ShouldBeNil)
+			}
+
+			// Without a configured identity, the default identity is used.
+			var author []byte
+			author, err = exec.Command("git", "-C", remoteDir, "log", "-1", "--branches", "--format=%an <%ae>").CombinedOutput()
+			So(err, ShouldBeNil)
+			So(strings.TrimSpace(string(author)), ShouldEqual, fmt.Sprintf("%s <%s>", git.DefaultUserName, git.DefaultUserEmail))
+
+			// Use a different home directory so nothing is shared with the publisher's clone.
+			var consumerHomeDir = t.TempDir()
+
+			var found, err = collectFromChannel(func(ch chan<- *template_package.PackageInfo) error {
+				return repo.FindPackages(consumerHomeDir, ch, "hello")
+			})
+			So(err, ShouldBeNil)
+			So(found, ShouldHaveLength, 2)
+
+			var versions []string
+			versions, err = collectFromChannel(func(ch chan<- string) error {
+				return repo.PackageVersions(consumerHomeDir, ch, "kpmtool/hello")
+			})
+			So(err, ShouldBeNil)
+			So(versions, ShouldResemble, []string{"1.0.0", "1.1.0"})
+
+			err = repo.Pull(consumerHomeDir, &template_package.PackageInfo{Name: "kpmtool/other", Version: "2.0.0"})
+			So(err, ShouldBeNil)
+			_, err = template_package.GetPackageInfo(template_package.GetPackageDir(consumerHomeDir, "kpmtool/other-2.0.0"))
+			So(err, ShouldBeNil)
+
+			Convey("Pushing an existing version fails", func() {
+				err = repo.Push(publisherHomeDir, &template_package.PackageInfo{Name: "kpmtool/hello", Version: "1.0.0"})
+				So(err, ShouldNotBeNil)
+			})
+
+			Convey("Pulling a missing package returns PackageNotFoundError", func() {
+				err = repo.Pull(consumerHomeDir, &template_package.PackageInfo{Name: "kpmtool/hello", Version: "9.9.9"})
+				So(err, ShouldWrap, PackageNotFoundError{})
+			})
+		})
+
+		Convey("An empty repository has no packages", func() {
+			var found, err = collectFromChannel(func(ch chan<- *template_package.PackageInfo) error {
+				return repo.FindPackages(t.TempDir(), ch, "")
+			})
+			So(err, ShouldBeNil)
+			So(found, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,260 @@
+package template_repository
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/archive"
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/oci"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/yaml"
+)
+
+const repositoryTypeNameHttp = "http"
+
+var _ Repository = &httpRepository{}
+
+// httpRepositoryClient is used to download index files and package archives, so that an unresponsive server doesn't
+// hang KPM forever.  The timeout is long enough to download large archives on slow connections.
+var httpRepositoryClient = &http.Client{Timeout: 5 * time.Minute}
+
+type httpRepository struct {
+	name           string
+	connectionInfo httpRepositoryConnectionInfo
+	baseUrl        *url.URL
+}
+
+type httpRepositoryConnectionInfo struct {
+	// Url is the location of the directory which contains the index file.
+	Url string `yaml:"url"`
+
+	// Username is sent using basic authentication (optional).  Environment variables are expanded.
+	Username string `yaml:"username"`
+
+	// Password is sent using basic authentication (optional).  Environment variables are expanded.
+	Password string `yaml:"password"`
+}
+
+func (repo *httpRepository) GetName() string {
+	return repo.name
+}
+
+func (repo *httpRepository) GetType() string {
+	return repositoryTypeNameHttp
+}
+
+func (repo *httpRepository) FindPackages(
+	kpmHomeDir string,
+	ch chan<- *template_package.PackageInfo,
+	searchTerm string,
+) (err error) {
+	var index *PackageIndex
+	index, err = repo.getIndex()
+	if err != nil {
+		return err
+	}
+
+	var packageNames = make([]string, 0, len(index.Packages))
+	for packageName := range index.Packages {
+		packageNames = append(packageNames, packageName)
+	}
+	sort.Strings(packageNames)
+
+	for _, packageName := range packageNames {
+		// If the package name doesn't contain the search term, ignore it.
+		if searchTerm != "" && !strings.Contains(packageName, searchTerm) {
+			continue
+		}
+
+		for _, entry := range index.Packages[packageName] {
+			ch <- &template_package.PackageInfo{Name: packageName, Version: entry.Version}
+		}
+	}
+
+	return nil
+}
+
+func (repo *httpRepository) PackageVersions(
+	kpmHomeDir string,
+	ch chan<- string,
+	packageName string,
+) (err error) {
+	var index *PackageIndex
+	index, err = repo.getIndex()
+	if err != nil {
+		return err
+	}
+
+	var entries, found = index.Packages[packageName]
+	if !found {
+		return PackageNotFoundError{PackageInfo: template_package.PackageInfo{Name: packageName}}
+	}
+
+	for _, entry := range entries {
+		ch <- entry.Version
+	}
+
+	return nil
+}
+
+func (repo *httpRepository) Push(
+	kpmHomeDir string,
+	packageInfo *template_package.PackageInfo,
+) error {
+	return fmt.Errorf(
+		"HTTP repository '%s' is read-only: upload the package archive to the web server and regenerate the index with 'kpm repo index'",
+		repo.name,
+	)
+}
+
+func (repo *httpRepository) Pull(
+	kpmHomeDir string,
+	packageInfo *template_package.PackageInfo,
+) (err error) {
+	if packageInfo == nil {
+		log.Panicf("packageInfo is nil")
+	}
+
+	var index *PackageIndex
+	index, err = repo.getIndex()
+	if err != nil {
+		return err
+	}
+
+	// Find the package in the index.
+	var entry *PackageIndexEntry
+	for _, e := range index.Packages[packageInfo.Name] {
+		if e.Version == packageInfo.Version {
+			entry = &e
+			break
+		}
+	}
+	if entry == nil {
+		return PackageNotFoundError{PackageInfo: *packageInfo}
+	}
+
+	// Download the archive and make sure it is the one that the index refers to.
+	var archiveBytes []byte
+	archiveBytes, err = repo.get(entry.Path)
+	if err != nil {
+		return fmt.Errorf("failed to download package '%s' from HTTP repository '%s': %s", packageInfo, repo.name, err)
+	}
+
+	if digest := oci.GetDigest(archiveBytes); digest != entry.Digest {
+		return fmt.Errorf(
+			"archive of package '%s' in HTTP repository '%s' does not match the digest in the index: expected %s, got %s",
+			packageInfo,
+			repo.name,
+			entry.Digest,
+			digest,
+		)
+	}
+
+	return installPackage(kpmHomeDir, packageInfo, func(dstDir string) error {
+		var _, err = archive.ExtractPackageArchive(bytes.NewReader(archiveBytes), dstDir)
+		return err
+	})
+}
+
+// getIndex downloads and parses the repository's index file.
+func (repo *httpRepository) getIndex() (*PackageIndex, error) {
+	var indexBytes, err = repo.get(constants.PackageIndexFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download index of HTTP repository '%s': %s", repo.name, err)
+	}
+
+	var index = new(PackageIndex)
+	err = yaml.BytesToObject(indexBytes, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse index of HTTP repository '%s': %s", repo.name, err)
+	}
+
+	if index.ApiVersion != PackageIndexApiVersion {
+		return nil, fmt.Errorf(
+			"index of HTTP repository '%s' has unsupported apiVersion '%s' (expected '%s')",
+			repo.name,
+			index.ApiVersion,
+			PackageIndexApiVersion,
+		)
+	}
+
+	return index, nil
+}
+
+// get downloads a file, given its path relative to the repository URL (or an absolute URL).
+func (repo *httpRepository) get(path string) (result []byte, err error) {
+	var fileUrl *url.URL
+	fileUrl, err = url.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	fileUrl = repo.baseUrl.ResolveReference(fileUrl)
+
+	var request *http.Request
+	request, err = http.NewRequest(http.MethodGet, fileUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only send credentials to the repository's own host.
+	var username = os.ExpandEnv(repo.connectionInfo.Username)
+	if username != "" && fileUrl.Host == repo.baseUrl.Host {
+		request.SetBasicAuth(username, os.ExpandEnv(repo.connectionInfo.Password))
+	}
+
+	var response *http.Response
+	response, err = httpRepositoryClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errors.Join(err, response.Body.Close())
+	}()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response for '%s': %s", fileUrl, response.Status)
+	}
+
+	return io.ReadAll(response.Body)
+}
+
+func repoInfoToHttpRepo(repoInfo *repositoryInfo) (Repository, error) {
+	if repoInfo == nil {
+		log.Panicf("repoInfo is nil")
+	}
+
+	var err error
+	var result = &httpRepository{name: repoInfo.Name}
+
+	var connectionInfo httpRepositoryConnectionInfo
+	err = repoInfo.ConnectionInfo.Decode(&connectionInfo)
+	if err != nil {
+		return result, fmt.Errorf("HTTP repository connection info is not a valid structure: %s", err)
+	}
+
+	// The URL must be absolute, and it must refer to a directory so that relative paths are resolved correctly.
+	result.baseUrl, err = url.Parse(connectionInfo.Url)
+	if err != nil {
+		return result, fmt.Errorf("invalid HTTP repository URL '%s': %s", connectionInfo.Url, err)
+	}
+	if result.baseUrl.Scheme != "http" && result.baseUrl.Scheme != "https" {
+		return result, fmt.Errorf("HTTP repository URL must start with \"http://\" or \"https://\": %s", connectionInfo.Url)
+	}
+	if !strings.HasSuffix(result.baseUrl.Path, "/") {
+		result.baseUrl.Path += "/"
+	}
+
+	result.connectionInfo = connectionInfo
+
+	return result, nil
+}
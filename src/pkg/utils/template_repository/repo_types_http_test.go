@@ -0,0 +1,143 @@
+package template_repository
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/archive"
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/yaml"
+)
+
+// writeTestPackageArchive creates a package and writes its archive into the given directory.
+func writeTestPackageArchive(t *testing.T, dir string, packageName string, packageVersion string) string {
+	t.Helper()
+
+	var homeDir = t.TempDir()
+	createTestPackage(t, homeDir, packageName, packageVersion)
+	var packageFullName = template_package.GetPackageFullName(packageName, packageVersion)
+
+	var archivePath = filepath.Join(dir, filepath.FromSlash(packageFullName)+constants.PackageArchiveExtension)
+	if err := os.MkdirAll(filepath.Dir(archivePath), os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+
+	var err = archive.WritePackageArchiveFile(template_package.GetPackageDir(homeDir, packageFullName), archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return archivePath
+}
+
+func TestHttpRepository(t *testing.T) {
+	Convey("Given a web server with package archives and an index", t, func() {
+		var servedDir = t.TempDir()
+		writeTestPackageArchive(t, servedDir, "kpmtool/hello", "1.0.0")
+		var archivePath = writeTestPackageArchive(t, servedDir, "kpmtool/hello", "1.1.0")
+		writeTestPackageArchive(t, servedDir, "kpmtool/other", "2.0.0")
+
+		var index, err = GeneratePackageIndex(servedDir)
+		So(err, ShouldBeNil)
+		So(index.Packages["kpmtool/hello"], ShouldHaveLength, 2)
+		So(index.Packages["kpmtool/hello"][1].Path, ShouldEqual, "kpmtool/hello-1.1.0.kpm")
+
+		var indexBytes []byte
+		indexBytes, err = yaml.ObjectToBytes(index)
+		So(err, ShouldBeNil)
+		So(os.WriteFile(filepath.Join(servedDir, constants.PackageIndexFileName), indexBytes, 0644), ShouldBeNil)
+
+		var server = httptest.NewServer(http.FileServer(http.Dir(servedDir)))
+		Reset(server.Close)
+
+		var repo = newTestRepository(t, fmt.Sprintf(`
+name: web
+type: http
+connection:
+  url: %s
+`, server.URL))
+
+		Convey("Packages can be found and pulled", func() {
+			var found, err = collectFromChannel(func(ch chan<- *template_package.PackageInfo) error {
+				return repo.FindPackages(t.TempDir(), ch, "hello")
+			})
+			So(err, ShouldBeNil)
+			So(found, ShouldResemble, []*template_package.PackageInfo{
+				{Name: "kpmtool/hello", Version: "1.0.0"},
+				{Name: "kpmtool/hello", Version: "1.1.0"},
+			})
+
+			var kpmHomeDir = t.TempDir()
+			err = repo.Pull(kpmHomeDir, &template_package.PackageInfo{Name: "kpmtool/hello", Version: "1.1.0"})
+			So(err, ShouldBeNil)
+			_, err = template_package.GetPackageInfo(template_package.GetPackageDir(kpmHomeDir, "kpmtool/hello-1.1.0"))
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Missing packages return PackageNotFoundError", func() {
+			var err = repo.Pull(t.TempDir(), &template_package.PackageInfo{Name: "kpmtool/hello", Version: "9.9.9"})
+			So(err, ShouldWrap, PackageNotFoundError{})
+
+			_, err = collectFromChannel(func(ch chan<- string) error {
+				return repo.PackageVersions(t.TempDir(), ch, "kpmtool/missing")
+			})
+			So(err, ShouldWrap, PackageNotFoundError{})
+		})
+
+		Convey("Archives which don't match the index are rejected", func() {
+			So(os.WriteFile(archivePath, []byte("not an archive"), 0644), ShouldBeNil)
+
+			var err = repo.Pull(t.TempDir(), &template_package.PackageInfo{Name: "kpmtool/hello", Version: "1.1.0"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "does not match the digest")
+		})
+
+		Convey("Packages can't be pushed", func() {
+			var err = repo.Push(t.TempDir(), &template_package.PackageInfo{Name: "kpmtool/hello", Version: "1.1.0"})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestHttpRepositoryTimeout(t *testing.T) {
+	Convey("Given a web server which never responds", t, func() {
+		var stop = make(chan struct{})
+		var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-stop:
+			}
+		}))
+		Reset(func() {
+			close(stop)
+			server.Close()
+		})
+
+		var originalClient = httpRepositoryClient
+		httpRepositoryClient = &http.Client{Timeout: 100 * time.Millisecond}
+		Reset(func() { httpRepositoryClient = originalClient })
+
+		var repo = newTestRepository(t, fmt.Sprintf(`
+name: web
+type: http
+connection:
+  url: %s
+`, server.URL))
+
+		Convey("Requests time out", func() {
+			var _, err = collectFromChannel(func(ch chan<- string) error {
+				return repo.PackageVersions(t.TempDir(), ch, "kpmtool/hello")
+			})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "Client.Timeout exceeded")
+		})
+	})
+}
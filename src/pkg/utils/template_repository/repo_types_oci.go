@@ -0,0 +1,256 @@
+package template_repository
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/archive"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+	"github.com/rohitramu/kpm/src/pkg/utils/oci"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+	"github.com/rohitramu/kpm/src/pkg/utils/validation"
+)
+
+const repositoryTypeNameOci = "oci"
+
+// Media types which identify template packages stored as OCI artifacts.
+const (
+	ociArtifactTypePackage  = "application/vnd.kpm.package.v1"
+	ociMediaTypePackageInfo = "application/vnd.kpm.package.config.v1+json"
+	ociMediaTypePackageData = "application/vnd.kpm.package.content.v1.tar+gzip"
+)
+
+var _ Repository = &ociRepository{}
+
+type ociRepository struct {
+	name           string
+	connectionInfo ociRepositoryConnectionInfo
+	client         *oci.Client
+}
+
+type ociRepositoryConnectionInfo struct {
+	// Registry is the host name of the registry, optionally prefixed with "http://" or "https://" (the default).
+	Registry string `yaml:"registry"`
+
+	// Namespace is the repository prefix under which packages are stored (optional).
+	Namespace string `yaml:"namespace"`
+
+	// Username is used to request tokens from the registry (optional).  Environment variables are expanded.
+	Username string `yaml:"username"`
+
+	// Password is used to request tokens from the registry (optional).  Environment variables are expanded, so
+	// secrets don't need to be written to the config file (e.g. "$REGISTRY_TOKEN").
+	Password string `yaml:"password"`
+}
+
+func (repo *ociRepository) GetName() string {
+	return repo.name
+}
+
+func (repo *ociRepository) GetType() string {
+	return repositoryTypeNameOci
+}
+
+func (repo *ociRepository) FindPackages(
+	kpmHomeDir string,
+	ch chan<- *template_package.PackageInfo,
+	searchTerm string,
+) (err error) {
+	var ociRepositories []string
+	ociRepositories, err = collectFromChannel(repo.client.ListRepositories)
+	if err != nil {
+		return err
+	}
+
+	for _, ociRepository := range ociRepositories {
+		// Ignore repositories which are outside the namespace or can't be template packages.
+		var packageName, inNamespace = repo.getPackageName(ociRepository)
+		if !inNamespace || validation.ValidatePackageName(packageName) != nil {
+			continue
+		}
+
+		// If the package name doesn't contain the search term, ignore it.
+		if searchTerm != "" && !strings.Contains(packageName, searchTerm) {
+			continue
+		}
+
+		var versions []string
+		versions, err = collectFromChannel(func(versionsCh chan<- string) error {
+			return repo.PackageVersions(kpmHomeDir, versionsCh, packageName)
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			ch <- &template_package.PackageInfo{Name: packageName, Version: version}
+		}
+	}
+
+	return nil
+}
+
+func (repo *ociRepository) PackageVersions(
+	kpmHomeDir string,
+	ch chan<- string,
+	packageName string,
+) (err error) {
+	var tags []string
+	tags, err = collectFromChannel(func(tagsCh chan<- string) error {
+		return repo.client.ListTags(tagsCh, repo.getOciRepository(packageName))
+	})
+	if err != nil {
+		if errors.Is(err, oci.ErrNotFound) {
+			return errors.Join(PackageNotFoundError{PackageInfo: template_package.PackageInfo{Name: packageName}}, err)
+		}
+
+		return err
+	}
+
+	for _, tag := range tags {
+		var version = oci.TagToVersion(tag)
+		if validation.ValidatePackageVersion(version) != nil {
+			log.Debugf("Ignoring tag which is not a valid package version: %s", tag)
+			continue
+		}
+
+		ch <- version
+	}
+
+	return nil
+}
+
+func (repo *ociRepository) Push(
+	kpmHomeDir string,
+	packageInfo *template_package.PackageInfo,
+) (err error) {
+	if packageInfo == nil {
+		log.Panicf("packageInfo is nil")
+	}
+
+	// Make sure the package exists locally.
+	var packageDir = template_package.GetPackageDir(
+		kpmHomeDir,
+		template_package.GetPackageFullName(packageInfo.Name, packageInfo.Version),
+	)
+	if _, err = template_package.GetPackageInfo(packageDir); err != nil {
+		return err
+	}
+
+	// Create the artifact.
+	var packageInfoBytes []byte
+	packageInfoBytes, err = json.Marshal(packageInfo)
+	if err != nil {
+		log.Panicf("Failed to serialize package info: %s", err)
+	}
+
+	var packageData = new(bytes.Buffer)
+	err = archive.WriteTarGz(packageDir, packageData)
+	if err != nil {
+		return err
+	}
+
+	var artifact = &oci.Artifact{
+		ArtifactType: ociArtifactTypePackage,
+		Config:       oci.Blob{MediaType: ociMediaTypePackageInfo, Data: packageInfoBytes},
+		Layers:       []oci.Blob{{MediaType: ociMediaTypePackageData, Data: packageData.Bytes()}},
+	}
+
+	// Upload it.
+	log.Infof("Pushing package '%s' to OCI repository '%s'", packageInfo, repo.name)
+	err = repo.client.PushArtifact(repo.getOciRepository(packageInfo.Name), oci.VersionToTag(packageInfo.Version), artifact)
+	if err != nil {
+		return fmt.Errorf("failed to push package '%s' to OCI repository '%s': %s", packageInfo, repo.name, err)
+	}
+
+	return nil
+}
+
+func (repo *ociRepository) Pull(
+	kpmHomeDir string,
+	packageInfo *template_package.PackageInfo,
+) (err error) {
+	if packageInfo == nil {
+		log.Panicf("packageInfo is nil")
+	}
+
+	// Download the artifact.
+	var artifact *oci.Artifact
+	artifact, err = repo.client.PullArtifact(repo.getOciRepository(packageInfo.Name), oci.VersionToTag(packageInfo.Version))
+	if err != nil {
+		if errors.Is(err, oci.ErrNotFound) {
+			return errors.Join(PackageNotFoundError{PackageInfo: *packageInfo}, err)
+		}
+
+		return fmt.Errorf("failed to pull package '%s' from OCI repository '%s': %s", packageInfo, repo.name, err)
+	}
+
+	// Make sure it is a template package.
+	if artifact.Config.MediaType != ociMediaTypePackageInfo ||
+		len(artifact.Layers) != 1 ||
+		artifact.Layers[0].MediaType != ociMediaTypePackageData {
+		return fmt.Errorf("artifact '%s' in OCI repository '%s' is not a template package", packageInfo, repo.name)
+	}
+
+	return installPackage(kpmHomeDir, packageInfo, func(dstDir string) error {
+		return archive.ExtractTarGz(bytes.NewReader(artifact.Layers[0].Data), dstDir)
+	})
+}
+
+// getOciRepository returns the name of the repository in the registry which holds a package's versions.
+func (repo *ociRepository) getOciRepository(packageName string) string {
+	if repo.connectionInfo.Namespace == "" {
+		return packageName
+	}
+
+	return fmt.Sprintf("%s/%s", repo.connectionInfo.Namespace, packageName)
+}
+
+// getPackageName is the inverse of getOciRepository.  It returns false if the repository is not in the namespace.
+func (repo *ociRepository) getPackageName(ociRepository string) (string, bool) {
+	if repo.connectionInfo.Namespace == "" {
+		return ociRepository, true
+	}
+
+	return strings.CutPrefix(ociRepository, repo.connectionInfo.Namespace+"/")
+}
+
+func repoInfoToOciRepo(repoInfo *repositoryInfo) (Repository, error) {
+	if repoInfo == nil {
+		log.Panicf("repoInfo is nil")
+	}
+
+	var err error
+	var result = &ociRepository{name: repoInfo.Name}
+
+	var connectionInfo ociRepositoryConnectionInfo
+	err = repoInfo.ConnectionInfo.Decode(&connectionInfo)
+	if err != nil {
+		return result, fmt.Errorf("OCI repository connection info is not a valid structure: %s", err)
+	}
+
+	// Namespaces must be valid so that repository names are valid.
+	connectionInfo.Namespace = strings.Trim(connectionInfo.Namespace, "/")
+	if connectionInfo.Namespace != "" {
+		if err = oci.ValidateRepositoryName(connectionInfo.Namespace); err != nil {
+			return result, fmt.Errorf("invalid OCI repository namespace: %s", err)
+		}
+	}
+
+	result.client, err = oci.NewClient(
+		connectionInfo.Registry,
+		os.ExpandEnv(connectionInfo.Username),
+		os.ExpandEnv(connectionInfo.Password),
+	)
+	if err != nil {
+		return result, err
+	}
+
+	result.connectionInfo = connectionInfo
+
+	return result, nil
+}
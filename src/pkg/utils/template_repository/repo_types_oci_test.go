@@ -0,0 +1,64 @@
+package template_repository
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/oci/ocitest"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+func TestOciRepository(t *testing.T) {
+	Convey("Given an OCI registry", t, func() {
+		var registry = ocitest.NewRegistryWithTokenAuth("kpm", "secret")
+		Reset(registry.Close)
+
+		t.Setenv("KPM_TEST_REGISTRY_PASSWORD", "secret")
+		var repo = newTestRepository(t, fmt.Sprintf(`
+name: registry
+type: oci
+connection:
+  registry: %s
+  namespace: my-org
+  username: kpm
+  password: $KPM_TEST_REGISTRY_PASSWORD
+`, registry.Url()))
+
+		var publisherHomeDir = t.TempDir()
+		createTestPackage(t, publisherHomeDir, "kpmtool/hello", "1.0.0")
+		createTestPackage(t, publisherHomeDir, "kpmtool/hello", "1.1.0")
+
+		Convey("Packages can be pushed, found and pulled", func() {
+			So(repo.Push(publisherHomeDir, &template_package.PackageInfo{Name: "kpmtool/hello", Version: "1.0.0"}), ShouldBeNil)
+			So(repo.Push(publisherHomeDir, &template_package.PackageInfo{Name: "kpmtool/hello", Version: "1.1.0"}), ShouldBeNil)
+
+			var consumerHomeDir = t.TempDir()
+
+			var found, err = collectFromChannel(func(ch chan<- *template_package.PackageInfo) error {
+				return repo.FindPackages(consumerHomeDir, ch, "")
+			})
+			So(err, ShouldBeNil)
+			So(found, ShouldResemble, []*template_package.PackageInfo{
+				{Name: "kpmtool/hello", Version: "1.0.0"},
+				{Name: "kpmtool/hello", Version: "1.1.0"},
+			})
+
+			err = repo.Pull(consumerHomeDir, &template_package.PackageInfo{Name: "kpmtool/hello", Version: "1.1.0"})
+			So(err, ShouldBeNil)
+			_, err = template_package.GetPackageInfo(template_package.GetPackageDir(consumerHomeDir, "kpmtool/hello-1.1.0"))
+			So(err, ShouldBeNil)
+		})
+
+		Convey("Missing packages return PackageNotFoundError", func() {
+			var err = repo.Pull(t.TempDir(), &template_package.PackageInfo{Name: "kpmtool/missing", Version: "1.0.0"})
+			So(err, ShouldWrap, PackageNotFoundError{})
+
+			_, err = collectFromChannel(func(ch chan<- string) error {
+				return repo.PackageVersions(t.TempDir(), ch, "kpmtool/missing")
+			})
+			So(err, ShouldWrap, PackageNotFoundError{})
+		})
+	})
+}
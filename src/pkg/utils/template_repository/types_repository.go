@@ -6,15 +6,13 @@ import (
 	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
 )
 
-// TODO: Add a repo type for git.
-
 // TODO: Don't use PackageInfo type for repository operations.
 
 type Repository interface {
 	GetName() string
 	GetType() string
-	FindPackages(ch chan<- *template_package.PackageInfo, searchTerm string) error
-	PackageVersions(ch chan<- string, packageName string) error
+	FindPackages(kpmHomeDir string, ch chan<- *template_package.PackageInfo, searchTerm string) error
+	PackageVersions(kpmHomeDir string, ch chan<- string, packageName string) error
 	Push(kpmHomeDir string, packageInfo *template_package.PackageInfo) error
 	Pull(kpmHomeDir string, packageInfo *template_package.PackageInfo) error
 }
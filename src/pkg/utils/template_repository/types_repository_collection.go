@@ -14,6 +14,14 @@ var _ yaml.Marshaler = &RepositoryCollection{}
 
 type RepositoryCollection struct {
 	repos linkedhashmap.Map
+
+	// repoInfos holds the information that each repository was created from, so the collection can be serialized.
+	repoInfos map[string]*repositoryInfo
+}
+
+// NewRepositoryCollection creates an empty collection of repositories.
+func NewRepositoryCollection() *RepositoryCollection {
+	return &RepositoryCollection{repos: *linkedhashmap.New(), repoInfos: map[string]*repositoryInfo{}}
 }
 
 func (result *RepositoryCollection) UnmarshalYAML(unmarshaller *yaml.Node) (err error) {
@@ -36,8 +44,17 @@ func (result *RepositoryCollection) UnmarshalYAML(unmarshaller *yaml.Node) (err
 }
 
 func (rc *RepositoryCollection) MarshalYAML() (any, error) {
-	// TODO: Implement a way to write a config file.
-	return nil, errors.New("not yet implemented")
+	var result = make(RepositoryInfoCollection, 0, rc.repos.Size())
+	for _, repoName := range rc.GetRepositoryNames() {
+		var repoInfo, found = rc.repoInfos[repoName]
+		if !found {
+			return nil, fmt.Errorf("repository '%s' was not created from repository information, so it can't be serialized", repoName)
+		}
+
+		result = append(result, repoInfo)
+	}
+
+	return result, nil
 }
 
 func (rc *RepositoryCollection) GetRepositoryNames() []string {
@@ -86,7 +103,16 @@ func (rc *RepositoryCollection) AddRepositoryInfo(repoInfo repositoryInfo) (err
 		return err
 	}
 
-	return rc.AddRepository(repo)
+	if err = rc.AddRepository(repo); err != nil {
+		return err
+	}
+
+	if rc.repoInfos == nil {
+		rc.repoInfos = map[string]*repositoryInfo{}
+	}
+	rc.repoInfos[repoInfo.Name] = &repoInfo
+
+	return nil
 }
 
 func (rc *RepositoryCollection) RemoveRepository(repoName string) error {
@@ -95,9 +121,47 @@ func (rc *RepositoryCollection) RemoveRepository(repoName string) error {
 	}
 
 	rc.repos.Remove(repoName)
+	delete(rc.repoInfos, repoName)
 	return nil
 }
 
+// GetPackageVersions returns the versions of a package which are available in any of the repositories, without duplicates.
+// If some repositories can't be checked, the versions from the other repositories are returned along with an error.
+func (rc *RepositoryCollection) GetPackageVersions(kpmHomeDir string, packageName string) ([]string, error) {
+	var result = []string{}
+	var repoErrs []error
+	var seen = map[string]bool{}
+	for _, repoName := range rc.GetRepositoryNames() {
+		var repo, err = rc.GetRepository(repoName)
+		if err != nil {
+			return nil, err
+		}
+
+		var versions []string
+		versions, err = collectFromChannel(func(ch chan<- string) error {
+			return repo.PackageVersions(kpmHomeDir, ch, packageName)
+		})
+		if err != nil {
+			// Not every repository has every package.
+			if errors.Is(err, PackageNotFoundError{}) {
+				continue
+			}
+
+			repoErrs = append(repoErrs, fmt.Errorf("failed to get versions of package '%s' from repository '%s': %s", packageName, repoName, err))
+			continue
+		}
+
+		for _, version := range versions {
+			if !seen[version] {
+				seen[version] = true
+				result = append(result, version)
+			}
+		}
+	}
+
+	return result, errors.Join(repoErrs...)
+}
+
 func castObjToRepo(uncasted any) Repository {
 	var repo, ok = uncasted.(Repository)
 	if !ok {
@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/emirpasic/gods/maps/linkedhashmap"
 	"golang.org/x/exp/maps"
 	"gopkg.in/yaml.v3"
 )
@@ -22,11 +21,14 @@ type repoInfoParsingFunc func(*repositoryInfo) (Repository, error)
 var repoTypeToParsingFunc = map[string]repoInfoParsingFunc{
 	repositoryTypeNameFilesystem: repoInfoToFilesystemRepo,
 	repositoryTypeNameDocker:     repoInfoToDockerRepo,
+	repositoryTypeNameGit:        repoInfoToGitRepo,
+	repositoryTypeNameOci:        repoInfoToOciRepo,
+	repositoryTypeNameHttp:       repoInfoToHttpRepo,
 }
 
 func (repoInfos RepositoryInfoCollection) ToRepositoryCollection() (*RepositoryCollection, error) {
 	var errs []error
-	var result = &RepositoryCollection{repos: *linkedhashmap.New()}
+	var result = NewRepositoryCollection()
 
 	// Create a Repository object based on the user-provided repository information.
 	for repoNum, repoInfo := range repoInfos {
@@ -39,6 +41,7 @@ func (repoInfos RepositoryInfoCollection) ToRepositoryCollection() (*RepositoryC
 		}
 
 		result.repos.Put(repoInfo.Name, repo)
+		result.repoInfos[repoInfo.Name] = repoInfo
 	}
 
 	// Combine the list of error messages if there were any.
@@ -0,0 +1,68 @@
+package template_repository
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+// collectFromChannel runs a function which writes to a channel, and returns everything that was written to it.
+func collectFromChannel[T any](fn func(ch chan<- T) error) ([]T, error) {
+	var ch = make(chan T)
+	var done = make(chan []T)
+	go func() {
+		var result []T
+		for item := range ch {
+			result = append(result, item)
+		}
+		done <- result
+	}()
+
+	var err = fn(ch)
+	close(ch)
+
+	return <-done, err
+}
+
+// installPackage extracts a package into the KPM home directory, after checking that it contains the expected package.
+// The package is extracted to a temporary directory first, so a bad package never ends up in the KPM home directory.
+func installPackage(
+	kpmHomeDir string,
+	packageInfo *template_package.PackageInfo,
+	extractFunc func(dstDir string) error,
+) (err error) {
+	var tempDir string
+	tempDir, err = os.MkdirTemp("", "kpm-package-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+
+	err = extractFunc(tempDir)
+	if err != nil {
+		return fmt.Errorf("failed to extract package '%s': %s", packageInfo, err)
+	}
+
+	var extractedPackageInfo *template_package.PackageInfo
+	extractedPackageInfo, err = template_package.GetPackageInfo(tempDir)
+	if err != nil {
+		return fmt.Errorf("package '%s' is not a valid template package: %s", packageInfo, err)
+	}
+	if *extractedPackageInfo != *packageInfo {
+		return fmt.Errorf("expected package '%s' but found package '%s'", packageInfo, extractedPackageInfo)
+	}
+
+	// Copy the package into the KPM home directory.
+	var packageDirDst = template_package.GetPackageDir(
+		kpmHomeDir,
+		template_package.GetPackageFullName(packageInfo.Name, packageInfo.Version),
+	)
+
+	if err = files.DeleteDirIfExists(packageDirDst, "destination template package", true); err != nil {
+		return err
+	}
+
+	return files.CopyDir(tempDir, packageDirDst)
+}
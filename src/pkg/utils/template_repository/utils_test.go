@@ -0,0 +1,52 @@
+package template_repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/constants"
+	"github.com/rohitramu/kpm/src/pkg/utils/template_package"
+)
+
+// createTestPackage writes a minimal valid template package into the given repository directory.
+func createTestPackage(t *testing.T, repoDir string, packageName string, packageVersion string) {
+	t.Helper()
+
+	var packageDir = template_package.GetPackageDir(repoDir, template_package.GetPackageFullName(packageName, packageVersion))
+	var packageFiles = map[string]string{
+		constants.PackageInfoFileName:                          "name: " + packageName + "\nversion: " + packageVersion + "\n",
+		constants.InterfaceFileName:                            "greeting: {{ .greeting }}\n",
+		constants.ParametersFileName:                           "greeting: hello\n",
+		filepath.Join(constants.TemplatesDirName, "hello.txt"): "{{ .values.greeting }}\n",
+	}
+
+	for fileName, content := range packageFiles {
+		var filePath = filepath.Join(packageDir, fileName)
+		if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newTestRepository parses a single repository definition from YAML.
+func newTestRepository(t *testing.T, repoYaml string) Repository {
+	t.Helper()
+
+	var repoInfo = new(repositoryInfo)
+	if err := yaml.Unmarshal([]byte(repoYaml), repoInfo); err != nil {
+		t.Fatal(err)
+	}
+
+	var repo, err = repoInfo.ToRepository()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return repo
+}
@@ -0,0 +1,190 @@
+package templates
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplateAnalysis describes what a template reads from its input and which other templates it uses, as far as can be
+// found out without executing it.  It assumes that the template is executed with the package's template input (or with
+// the parameters, for the interface), and that defined templates are called with the same input.
+type TemplateAnalysis struct {
+	// FieldReferences are the fields that are read from the template's input (e.g. ".values.name" or "$.values.name").
+	FieldReferences []*FieldReference
+
+	// TemplateReferences are the templates that are called with the "template" action or the "include" function.
+	TemplateReferences []*TemplateReference
+
+	// Definitions are the templates which are defined with "define" or "block" actions.
+	Definitions []*TemplateReference
+
+	// UsesWholeInput is true if the whole input is used at once (e.g. "toYaml ." or "include "helper" ."), so it isn't
+	// possible to know which fields are read.
+	UsesWholeInput bool
+}
+
+// FieldReference is a chain of fields which is read from the template's input.
+type FieldReference struct {
+	Fields []string
+	Line   int
+}
+
+// TemplateReference is the name of a template which is called or defined.
+type TemplateReference struct {
+	Name string
+	Line int
+}
+
+// parseErrorLineRegex matches the line number in template parse and execution errors (e.g. "template: name:12: ...").
+var parseErrorLineRegex = regexp.MustCompile(`^template: [^:]*:(\d+)(?::\d+)?:\s*((?s).*)$`)
+
+// GetErrorLine returns the line number which a template parse or execution error refers to, and the error message
+// without the template name and line number.  The line number is 0 if it isn't known.
+func GetErrorLine(err error) (int, string) {
+	var match = parseErrorLineRegex.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, err.Error()
+	}
+
+	var line, _ = strconv.Atoi(match[1])
+	return line, match[2]
+}
+
+// AnalyzeTemplate finds the fields and templates which are used by a template and any templates that it defines.
+func AnalyzeTemplate(tmpl *template.Template) *TemplateAnalysis {
+	var result = &TemplateAnalysis{
+		FieldReferences:    []*FieldReference{},
+		TemplateReferences: []*TemplateReference{},
+		Definitions:        []*TemplateReference{},
+	}
+
+	for _, associatedTemplate := range tmpl.Templates() {
+		var tree = associatedTemplate.Tree
+		if tree == nil || tree.Root == nil || tree.ParseName != tmpl.Name() {
+			continue
+		}
+
+		if associatedTemplate.Name() != tmpl.Name() {
+			result.Definitions = append(result.Definitions, &TemplateReference{
+				Name: associatedTemplate.Name(),
+				Line: getNodeLine(tree, tree.Root),
+			})
+		}
+
+		var analyzer = &treeAnalyzer{tree: tree, result: result}
+		analyzer.visit(tree.Root, true)
+	}
+
+	return result
+}
+
+// treeAnalyzer walks the nodes in a parse tree, recording what they use.
+type treeAnalyzer struct {
+	tree   *parse.Tree
+	result *TemplateAnalysis
+}
+
+// visit records what a node uses.  If "atRoot" is true, "." refers to the template's input.
+func (analyzer *treeAnalyzer) visit(node parse.Node, atRoot bool) {
+	switch typedNode := node.(type) {
+	case *parse.ListNode:
+		if typedNode == nil {
+			return
+		}
+		for _, childNode := range typedNode.Nodes {
+			analyzer.visit(childNode, atRoot)
+		}
+	case *parse.ActionNode:
+		analyzer.visit(typedNode.Pipe, atRoot)
+	case *parse.IfNode:
+		analyzer.visit(typedNode.Pipe, atRoot)
+		analyzer.visit(typedNode.List, atRoot)
+		analyzer.visit(typedNode.ElseList, atRoot)
+	case *parse.RangeNode:
+		// The body is executed with each item as ".", but the "else" branch isn't
+		analyzer.visit(typedNode.Pipe, atRoot)
+		analyzer.visit(typedNode.List, false)
+		analyzer.visit(typedNode.ElseList, atRoot)
+	case *parse.WithNode:
+		// The body is executed with the pipeline's value as ".", but the "else" branch isn't
+		analyzer.visit(typedNode.Pipe, atRoot)
+		analyzer.visit(typedNode.List, false)
+		analyzer.visit(typedNode.ElseList, atRoot)
+	case *parse.TemplateNode:
+		analyzer.result.TemplateReferences = append(analyzer.result.TemplateReferences, &TemplateReference{
+			Name: typedNode.Name,
+			Line: getNodeLine(analyzer.tree, typedNode),
+		})
+		analyzer.visit(typedNode.Pipe, atRoot)
+	case *parse.PipeNode:
+		if typedNode == nil {
+			return
+		}
+		for _, command := range typedNode.Cmds {
+			analyzer.visit(command, atRoot)
+		}
+	case *parse.CommandNode:
+		analyzer.visitCommand(typedNode, atRoot)
+	case *parse.ChainNode:
+		analyzer.visit(typedNode.Node, atRoot)
+	case *parse.FieldNode:
+		if atRoot {
+			analyzer.addFieldReference(typedNode, typedNode.Ident)
+		}
+	case *parse.VariableNode:
+		// "$" always refers to the template's input
+		if typedNode.Ident[0] == "$" {
+			if len(typedNode.Ident) == 1 {
+				analyzer.result.UsesWholeInput = true
+			} else {
+				analyzer.addFieldReference(typedNode, typedNode.Ident[1:])
+			}
+		}
+	case *parse.DotNode:
+		if atRoot {
+			analyzer.result.UsesWholeInput = true
+		}
+	}
+}
+
+// visitCommand records what a command uses, including the template which is called if it uses the "include" function.
+func (analyzer *treeAnalyzer) visitCommand(command *parse.CommandNode, atRoot bool) {
+	if len(command.Args) >= 2 {
+		var function, isIdentifier = command.Args[0].(*parse.IdentifierNode)
+		var templateName, isString = command.Args[1].(*parse.StringNode)
+		if isIdentifier && isString && function.Ident == FuncNameInclude {
+			analyzer.result.TemplateReferences = append(analyzer.result.TemplateReferences, &TemplateReference{
+				Name: templateName.Text,
+				Line: getNodeLine(analyzer.tree, command),
+			})
+		}
+	}
+
+	for _, arg := range command.Args {
+		analyzer.visit(arg, atRoot)
+	}
+}
+
+// addFieldReference records a chain of fields which is read from the template's input.
+func (analyzer *treeAnalyzer) addFieldReference(node parse.Node, fields []string) {
+	analyzer.result.FieldReferences = append(analyzer.result.FieldReferences, &FieldReference{
+		Fields: fields,
+		Line:   getNodeLine(analyzer.tree, node),
+	})
+}
+
+// getNodeLine returns the line in the template file where a node is.
+func getNodeLine(tree *parse.Tree, node parse.Node) int {
+	// The location is formatted as "name:line:column"
+	var location, _ = tree.ErrorContext(node)
+	var parts = strings.Split(location, ":")
+	if len(parts) < 3 {
+		return 0
+	}
+
+	var line, _ = strconv.Atoi(parts[len(parts)-2])
+	return line
+}
@@ -0,0 +1,233 @@
+package templates
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Kinds of coverage blocks.
+const (
+	CoverageBlockAction    = "action"
+	CoverageBlockIf        = "if"
+	CoverageBlockElse      = "else"
+	CoverageBlockRange     = "range"
+	CoverageBlockRangeElse = "range else"
+	CoverageBlockWith      = "with"
+	CoverageBlockWithElse  = "with else"
+)
+
+// coverFuncName is the name of the template function which records that a block was executed.  It is only added to
+// templates which are parsed with a coverage.
+const coverFuncName = "__kpmCover"
+
+// Coverage records how many times each action and branch in template files was executed.  Blocks are identified by their
+// file and position, so the counts are combined when a file is parsed and executed more than once.
+type Coverage struct {
+	files  map[string]*FileCoverage
+	blocks []*CoverageBlock
+
+	// blockIds maps the file path and position of each block to its index in "blocks".
+	blockIds map[blockKey]int
+
+	// instrumentedTrees is the set of parse trees which already record coverage.
+	instrumentedTrees map[*parse.Tree]bool
+}
+
+// FileCoverage is the coverage of a single template file.
+type FileCoverage struct {
+	FilePath string
+
+	// Text is the contents of the file.
+	Text string
+
+	// Blocks are the actions and branches in the file, in the order of their lines.
+	Blocks []*CoverageBlock
+}
+
+// CoverageBlock is an action or a branch of an "if", "range" or "with" action in a template file.
+type CoverageBlock struct {
+	// Line is the line where the block starts.
+	Line int
+
+	// Kind is one of the "CoverageBlock" constants.
+	Kind string
+
+	// Count is the number of times the block was executed.
+	Count int
+
+	// BranchPos is the position of the "if", "range" or "with" action that the block is a branch of, or -1 if the block
+	// is an action.
+	BranchPos int
+}
+
+type blockKey struct {
+	filePath string
+	pos      parse.Pos
+	kind     string
+}
+
+// NewCoverage creates an empty coverage.
+func NewCoverage() *Coverage {
+	return &Coverage{
+		files:             map[string]*FileCoverage{},
+		blocks:            []*CoverageBlock{},
+		blockIds:          map[blockKey]int{},
+		instrumentedTrees: map[*parse.Tree]bool{},
+	}
+}
+
+// GetFiles returns the coverage of each template file which was parsed, sorted by file path.
+func (coverage *Coverage) GetFiles() []*FileCoverage {
+	var result = make([]*FileCoverage, 0, len(coverage.files))
+	for _, file := range coverage.files {
+		result = append(result, file)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].FilePath < result[j].FilePath
+	})
+
+	return result
+}
+
+// GetNumCovered returns the number of blocks in the file which were executed at least once.
+func (file *FileCoverage) GetNumCovered() int {
+	var result = 0
+	for _, block := range file.Blocks {
+		if block.Count > 0 {
+			result++
+		}
+	}
+
+	return result
+}
+
+// instrument changes the parse trees of a template which was parsed from a file (and the templates that the file
+// defines), so they record which of their blocks are executed.
+func (coverage *Coverage) instrument(tmpl *template.Template, filePath string, text string) {
+	var file, found = coverage.files[filePath]
+	if !found {
+		file = &FileCoverage{FilePath: filePath, Text: text, Blocks: []*CoverageBlock{}}
+		coverage.files[filePath] = file
+	}
+
+	tmpl.Funcs(map[string]any{coverFuncName: coverage.record})
+
+	for _, associatedTemplate := range tmpl.Templates() {
+		var tree = associatedTemplate.Tree
+		if tree == nil || tree.Root == nil || tree.ParseName != tmpl.Name() || coverage.instrumentedTrees[tree] {
+			continue
+		}
+		coverage.instrumentedTrees[tree] = true
+
+		var instrumenter = &treeInstrumenter{coverage: coverage, file: file}
+		instrumenter.instrumentList(tree.Root)
+	}
+
+	sort.SliceStable(file.Blocks, func(i, j int) bool {
+		return file.Blocks[i].Line < file.Blocks[j].Line
+	})
+}
+
+// record is the template function which records that a block was executed.
+func (coverage *Coverage) record(blockId int) string {
+	coverage.blocks[blockId].Count++
+
+	return ""
+}
+
+// treeInstrumenter adds calls to the coverage function to the nodes in a parse tree.
+type treeInstrumenter struct {
+	coverage *Coverage
+	file     *FileCoverage
+}
+
+// instrumentList records each action in a list, and instruments the branches of any "if", "range" or "with" actions.
+func (instrumenter *treeInstrumenter) instrumentList(list *parse.ListNode) {
+	var nodes = make([]parse.Node, 0, len(list.Nodes))
+	for _, node := range list.Nodes {
+		switch typedNode := node.(type) {
+		case *parse.ActionNode, *parse.TemplateNode:
+			nodes = append(nodes, instrumenter.getCoverNode(node.Position(), CoverageBlockAction, -1))
+		case *parse.IfNode:
+			instrumenter.instrumentBranch(&typedNode.BranchNode, CoverageBlockIf, CoverageBlockElse)
+		case *parse.RangeNode:
+			instrumenter.instrumentBranch(&typedNode.BranchNode, CoverageBlockRange, CoverageBlockRangeElse)
+		case *parse.WithNode:
+			instrumenter.instrumentBranch(&typedNode.BranchNode, CoverageBlockWith, CoverageBlockWithElse)
+		}
+		nodes = append(nodes, node)
+	}
+
+	list.Nodes = nodes
+}
+
+// instrumentBranch records when each branch of an "if", "range" or "with" action is executed.  Actions without an
+// "else" branch get an empty one, so it is possible to tell whether the action was ever skipped.
+func (instrumenter *treeInstrumenter) instrumentBranch(branch *parse.BranchNode, kind string, elseKind string) {
+	var branchPos = int(branch.Position())
+
+	instrumenter.instrumentList(branch.List)
+	branch.List.Nodes = append(
+		[]parse.Node{instrumenter.getCoverNode(branch.List.Position(), kind, branchPos)},
+		branch.List.Nodes...,
+	)
+
+	if branch.ElseList == nil {
+		branch.ElseList = &parse.ListNode{NodeType: parse.NodeList, Pos: branch.Position()}
+	} else {
+		instrumenter.instrumentList(branch.ElseList)
+	}
+	branch.ElseList.Nodes = append(
+		[]parse.Node{instrumenter.getCoverNode(branch.ElseList.Position(), elseKind, branchPos)},
+		branch.ElseList.Nodes...,
+	)
+}
+
+// getCoverNode registers a block and returns an action which records that it was executed.  The action doesn't produce
+// any output.
+func (instrumenter *treeInstrumenter) getCoverNode(pos parse.Pos, kind string, branchPos int) parse.Node {
+	var coverage = instrumenter.coverage
+	var key = blockKey{filePath: instrumenter.file.FilePath, pos: pos, kind: kind}
+	var blockId, found = coverage.blockIds[key]
+	if !found {
+		var block = &CoverageBlock{
+			Line:      getPosLine(instrumenter.file.Text, pos),
+			Kind:      kind,
+			BranchPos: branchPos,
+		}
+		blockId = len(coverage.blocks)
+		coverage.blocks = append(coverage.blocks, block)
+		coverage.blockIds[key] = blockId
+		instrumenter.file.Blocks = append(instrumenter.file.Blocks, block)
+	}
+
+	var line = getPosLine(instrumenter.file.Text, pos)
+	var blockIdNode = &parse.NumberNode{NodeType: parse.NodeNumber, Pos: pos, IsInt: true, Int64: int64(blockId), Text: strconv.Itoa(blockId)}
+	return &parse.ActionNode{
+		NodeType: parse.NodeAction,
+		Pos:      pos,
+		Line:     line,
+		Pipe: &parse.PipeNode{
+			NodeType: parse.NodePipe,
+			Pos:      pos,
+			Line:     line,
+			Cmds: []*parse.CommandNode{{
+				NodeType: parse.NodeCommand,
+				Pos:      pos,
+				Args:     []parse.Node{parse.NewIdentifier(coverFuncName).SetPos(pos), blockIdNode},
+			}},
+		},
+	}
+}
+
+// getPosLine returns the line of a position in a template file's text.
+func getPosLine(text string, pos parse.Pos) int {
+	if int(pos) > len(text) {
+		pos = parse.Pos(len(text))
+	}
+
+	return strings.Count(text[:pos], "\n") + 1
+}
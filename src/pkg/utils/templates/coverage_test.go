@@ -0,0 +1,82 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCoverage(t *testing.T) {
+	Convey("Given a template file", t, func() {
+		var filePath = filepath.Join(t.TempDir(), "template.yaml")
+		var templateString = "" +
+			"name: {{ .name }}\n" +
+			"{{- if .enabled }}\n" +
+			"enabled: true\n" +
+			"{{- else }}\n" +
+			"enabled: false\n" +
+			"{{- end }}\n" +
+			"{{- range .items }}\n" +
+			"item: {{ . }}\n" +
+			"{{- end }}\n"
+		So(os.WriteFile(filePath, []byte(templateString), 0644), ShouldBeNil)
+
+		var coverage = NewCoverage()
+
+		var execute = func(input map[string]any) string {
+			var tmpl, err = GetTemplateFromFile(NewRootTemplate(), "template.yaml", filePath, coverage)
+			So(err, ShouldBeNil)
+
+			var output []byte
+			output, err = ExecuteTemplate(tmpl, input)
+			So(err, ShouldBeNil)
+			return string(output)
+		}
+
+		Convey("Templates which are parsed without a coverage don't record into it", func() {
+			var tmpl, err = GetTemplateFromFile(NewRootTemplate(), "template.yaml", filePath, nil)
+			So(err, ShouldBeNil)
+			_, err = ExecuteTemplate(tmpl, map[string]any{"name": "a", "enabled": true, "items": []int{1}})
+			So(err, ShouldBeNil)
+
+			So(coverage.GetFiles(), ShouldBeEmpty)
+		})
+
+		Convey("Coverage doesn't change the output", func() {
+			var output = execute(map[string]any{"name": "a", "enabled": true, "items": []int{1, 2}})
+			So(output, ShouldEqual, "name: a\nenabled: true\nitem: 1\nitem: 2\n")
+		})
+
+		Convey("Blocks which were executed are counted", func() {
+			execute(map[string]any{"name": "a", "enabled": true, "items": []int{1, 2}})
+
+			var files = coverage.GetFiles()
+			So(files, ShouldHaveLength, 1)
+			So(files[0].FilePath, ShouldEqual, filePath)
+
+			var counts = map[string]int{}
+			for _, block := range files[0].Blocks {
+				counts[block.Kind] += block.Count
+			}
+			So(counts[CoverageBlockIf], ShouldEqual, 1)
+			So(counts[CoverageBlockElse], ShouldEqual, 0)
+			So(counts[CoverageBlockRange], ShouldEqual, 2)
+			So(counts[CoverageBlockRangeElse], ShouldEqual, 0)
+
+			// Both actions are covered, but neither "else" branch is
+			So(files[0].Blocks, ShouldHaveLength, 6)
+			So(files[0].GetNumCovered(), ShouldEqual, 4)
+
+			Convey("And counts are combined when the file is parsed again", func() {
+				execute(map[string]any{"name": "b", "enabled": false, "items": []int{}})
+
+				var files = coverage.GetFiles()
+				So(files, ShouldHaveLength, 1)
+				So(files[0].Blocks, ShouldHaveLength, 6)
+				So(files[0].GetNumCovered(), ShouldEqual, 6)
+			})
+		})
+	})
+}
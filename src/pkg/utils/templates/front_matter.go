@@ -0,0 +1,49 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/yaml"
+)
+
+// frontMatterRegex matches a template comment at the start of a template which starts with "kpm", e.g.:
+//
+//	{{- /* kpm
+//	name: "{{ .item }}.yaml"
+//	*/ -}}
+//
+// Since the front matter is a comment, it doesn't produce any output when the template is executed.
+var frontMatterRegex = regexp.MustCompile(`^\s*\{\{-?\s*/\*\s*kpm\s*?\n((?s).*?)\*/`)
+
+// FrontMatter holds the options at the start of an executable template which control the files that it generates.
+type FrontMatter struct {
+	// Name is a template which generates the path of the output file, relative to the output directory.
+	Name string `yaml:"name" json:"name"`
+
+	// ForEach is a template which generates a YAML list.  If it is set, the template is executed once for each item in
+	// the list, with the item in the "item" field of the template input.
+	ForEach string `yaml:"forEach" json:"forEach"`
+
+	// Skip is a template which generates "true" if the output file should not be written.
+	Skip string `yaml:"skip" json:"skip"`
+
+	// SkipIfEmpty is true if the output file should not be written when it only contains whitespace.
+	SkipIfEmpty bool `yaml:"skipIfEmpty" json:"skipIfEmpty"`
+}
+
+// GetFrontMatter returns the front matter of a template, or nil if the template doesn't have any front matter.
+func GetFrontMatter(templateString string) (*FrontMatter, error) {
+	var match = frontMatterRegex.FindStringSubmatch(templateString)
+	if match == nil {
+		return nil, nil
+	}
+
+	var result = new(FrontMatter)
+	var err = yaml.BytesToObject([]byte(match[1]), result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse front matter: %s", err)
+	}
+
+	return result, nil
+}
@@ -5,22 +5,26 @@ import (
 	"text/template"
 )
 
-type packageFuncFactory (func(tmpl *template.Template) any)
+type packageFuncFactory (func(tmpl *template.Template, packageDir string) any)
 
-// GetPackageFuncMap returns the template functions which can be used only in the context of a particular template.
-// If the template provided is nil, placeholder template functions are provided which return "Not implemented" errors.
-func GetPackageFuncMap(tmpl *template.Template) map[string]any {
+// GetPackageFuncMap returns the template functions which can be used only in the context of a particular template in
+// the given package directory.  If the template provided is nil, placeholder template functions are provided which
+// return "Not implemented" errors.
+func GetPackageFuncMap(tmpl *template.Template, packageDir string) map[string]any {
 	return map[string]any{
-		FuncNameInclude: getPackageFuncOrPlaceholder(tmpl, GetIncludeFunc),
+		FuncNameInclude:              getPackageFuncOrPlaceholder(tmpl, packageDir, GetIncludeFunc),
+		FuncNameReadFile:             getPackageFuncOrPlaceholder(tmpl, packageDir, GetReadFileFunc),
+		FuncNameGlobFiles:            getPackageFuncOrPlaceholder(tmpl, packageDir, GetGlobFilesFunc),
+		FuncNameFilesAsConfigMapData: getPackageFuncOrPlaceholder(tmpl, packageDir, GetFilesAsConfigMapDataFunc),
 	}
 }
 
-func getPackageFuncOrPlaceholder(tmpl *template.Template, fn packageFuncFactory) any {
+func getPackageFuncOrPlaceholder(tmpl *template.Template, packageDir string, fn packageFuncFactory) any {
 	if tmpl == nil {
 		return func(...any) (any, error) {
 			return nil, fmt.Errorf("not implemented")
 		}
 	}
 
-	return fn(tmpl)
+	return fn(tmpl, packageDir)
 }
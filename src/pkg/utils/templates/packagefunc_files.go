@@ -0,0 +1,179 @@
+package templates
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/rohitramu/kpm/src/pkg/utils/files"
+	"github.com/rohitramu/kpm/src/pkg/utils/log"
+)
+
+// FuncNameReadFile is the name of the "readFile" template function.
+const FuncNameReadFile = "readFile"
+
+// FuncNameGlobFiles is the name of the "globFiles" template function.
+const FuncNameGlobFiles = "globFiles"
+
+// FuncNameFilesAsConfigMapData is the name of the "filesAsConfigMapData" template function.
+const FuncNameFilesAsConfigMapData = "filesAsConfigMapData"
+
+// GetReadFileFunc creates a new instance of the ReadFile function, which returns the contents of a file in the package
+// directory.  The path of the file is relative to the package directory.
+func GetReadFileFunc(tmpl *template.Template, packageDir string) any {
+	if tmpl == nil {
+		log.Panicf("Template cannot be nil")
+	}
+
+	return func(relativePath string) (string, error) {
+		var filePath, err = getPackageFilePath(packageDir, relativePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file \"%s\": %s", relativePath, err)
+		}
+
+		var fileBytes []byte
+		fileBytes, err = os.ReadFile(filePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file \"%s\": %s", relativePath, err)
+		}
+
+		return string(fileBytes), nil
+	}
+}
+
+// GetGlobFilesFunc creates a new instance of the GlobFiles function, which returns the paths of the files in the package
+// directory which match a glob pattern.  Paths are relative to the package directory, use forward slashes and are sorted.
+func GetGlobFilesFunc(tmpl *template.Template, packageDir string) any {
+	if tmpl == nil {
+		log.Panicf("Template cannot be nil")
+	}
+
+	return func(pattern string) ([]string, error) {
+		return globPackageFiles(packageDir, pattern)
+	}
+}
+
+// GetFilesAsConfigMapDataFunc creates a new instance of the FilesAsConfigMapData function, which returns the contents of
+// the files in the package directory which match a glob pattern, keyed by file name.  The result can be used as the
+// "data" of a Kubernetes ConfigMap (e.g. with "toYaml").
+func GetFilesAsConfigMapDataFunc(tmpl *template.Template, packageDir string) any {
+	if tmpl == nil {
+		log.Panicf("Template cannot be nil")
+	}
+
+	var readFile = GetReadFileFunc(tmpl, packageDir).(func(string) (string, error))
+
+	return func(pattern string) (map[string]string, error) {
+		var relativePaths, err = globPackageFiles(packageDir, pattern)
+		if err != nil {
+			return nil, err
+		}
+
+		var result = map[string]string{}
+		var keySources = map[string]string{}
+		for _, relativePath := range relativePaths {
+			// ConfigMap keys can't contain slashes, so use the file name
+			var key = path.Base(relativePath)
+			if otherPath, found := keySources[key]; found {
+				return nil, fmt.Errorf("files \"%s\" and \"%s\" have the same name, so they can't both be added to a ConfigMap", otherPath, relativePath)
+			}
+			keySources[key] = relativePath
+
+			var content string
+			content, err = readFile(relativePath)
+			if err != nil {
+				return nil, err
+			}
+
+			if !utf8.ValidString(content) {
+				return nil, fmt.Errorf("file \"%s\" is not valid UTF-8 text, so it can't be added to the data of a ConfigMap", relativePath)
+			}
+
+			result[key] = content
+		}
+
+		return result, nil
+	}
+}
+
+// globPackageFiles returns the paths of the regular files in the package directory which match a glob pattern.
+func globPackageFiles(packageDir string, pattern string) (result []string, err error) {
+	if err = files.ValidateGlob(pattern); err != nil {
+		return nil, err
+	}
+
+	result = []string{}
+	err = filepath.WalkDir(packageDir, func(filePath string, dirEntry fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		if !dirEntry.Type().IsRegular() {
+			return nil
+		}
+
+		var relativePath, err = filepath.Rel(packageDir, filePath)
+		if err != nil {
+			log.Panicf("Failed to get relative path of file in package: %s", err)
+		}
+		relativePath = filepath.ToSlash(relativePath)
+
+		var isMatch bool
+		isMatch, err = files.MatchGlob(pattern, relativePath)
+		if err != nil {
+			return err
+		}
+
+		if isMatch {
+			result = append(result, relativePath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find files matching \"%s\": %s", pattern, err)
+	}
+
+	return result, nil
+}
+
+// getPackageFilePath returns the path of a file in the package directory, given its path relative to the package
+// directory.  Paths which refer to anything outside the package directory (including through symbolic links) are rejected.
+func getPackageFilePath(packageDir string, relativePath string) (string, error) {
+	var err error
+
+	if packageDir == "" {
+		return "", fmt.Errorf("the package directory is unknown")
+	}
+
+	relativePath, err = files.CleanRelativePath(relativePath)
+	if err != nil {
+		return "", fmt.Errorf("invalid path in package: %s", err)
+	}
+
+	// Resolve symbolic links so they can't be used to escape the package directory
+	var resolvedPackageDir string
+	resolvedPackageDir, err = filepath.EvalSymlinks(packageDir)
+	if err != nil {
+		return "", err
+	}
+
+	var resolvedFilePath string
+	resolvedFilePath, err = filepath.EvalSymlinks(filepath.Join(packageDir, filepath.FromSlash(relativePath)))
+	if err != nil {
+		return "", err
+	}
+
+	var resolvedRelativePath string
+	resolvedRelativePath, err = filepath.Rel(resolvedPackageDir, resolvedFilePath)
+	if err != nil || resolvedRelativePath == ".." || strings.HasPrefix(resolvedRelativePath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path refers to a file outside the package directory: %s", relativePath)
+	}
+
+	return resolvedFilePath, nil
+}
@@ -0,0 +1,71 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPackageFileFuncs(t *testing.T) {
+	Convey("Given a package directory", t, func() {
+		var packageDir = t.TempDir()
+		var packageFiles = map[string]string{
+			"files/scripts/init.sh":         "#!/bin/sh\necho {{ not a template }}\n",
+			"files/dashboards/a.json":       `{"a": 1}`,
+			"files/dashboards/team/b.json":  `{"b": 2}`,
+			"files/dashboards/other/b.json": `{"b": 3}`,
+		}
+		for relativePath, content := range packageFiles {
+			var filePath = filepath.Join(packageDir, filepath.FromSlash(relativePath))
+			So(os.MkdirAll(filepath.Dir(filePath), os.ModePerm), ShouldBeNil)
+			So(os.WriteFile(filePath, []byte(content), 0644), ShouldBeNil)
+		}
+
+		var execute = func(templateString string) (string, error) {
+			var tmpl = AddPackageSpecificTemplateFunctions(NewRootTemplate(), packageDir)
+			var err error
+			tmpl, err = tmpl.New("test").Parse(templateString)
+			So(err, ShouldBeNil)
+
+			var output []byte
+			output, err = ExecuteTemplate(tmpl, map[string]any{})
+			return string(output), err
+		}
+
+		Convey("Files can be read without being executed", func() {
+			var output, err = execute(`{{ readFile "files/scripts/init.sh" }}`)
+			So(err, ShouldBeNil)
+			So(output, ShouldEqual, packageFiles["files/scripts/init.sh"])
+		})
+
+		Convey("Files can be found with glob patterns", func() {
+			var output, err = execute(`{{ globFiles "files/dashboards/**/*.json" | join "," }}`)
+			So(err, ShouldBeNil)
+			So(output, ShouldEqual, "files/dashboards/a.json,files/dashboards/other/b.json,files/dashboards/team/b.json")
+		})
+
+		Convey("Files can be used as ConfigMap data", func() {
+			var output, err = execute(`{{ filesAsConfigMapData "files/dashboards/*.json" | toYaml }}`)
+			So(err, ShouldBeNil)
+			So(output, ShouldEqual, "a.json: '{\"a\": 1}'\n")
+
+			Convey("Unless they have the same name", func() {
+				var _, err = execute(`{{ filesAsConfigMapData "files/dashboards/**/b.json" }}`)
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("Files outside the package directory can't be read", func() {
+			var outsideFile = filepath.Join(t.TempDir(), "secret.txt")
+			So(os.WriteFile(outsideFile, []byte("secret"), 0644), ShouldBeNil)
+			So(os.Symlink(outsideFile, filepath.Join(packageDir, "link.txt")), ShouldBeNil)
+
+			for _, relativePath := range []string{"../secret.txt", "/etc/passwd", "files/../../secret.txt", "link.txt"} {
+				var _, err = execute(`{{ readFile "` + relativePath + `" }}`)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
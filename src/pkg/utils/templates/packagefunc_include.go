@@ -11,7 +11,7 @@ import (
 const FuncNameInclude = "include"
 
 // GetIncludeFunc creates a new instance of the Include function, which allows helper templates to be executed so their output can be used in other functions.
-func GetIncludeFunc(tmpl *template.Template) any {
+func GetIncludeFunc(tmpl *template.Template, packageDir string) any {
 	if tmpl == nil {
 		log.Panicf("Template cannot be nil")
 	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"text/template"
 
@@ -28,22 +29,46 @@ func NewRootTemplate() *template.Template {
 	tmpl = tmpl.Funcs(GetGlobalFuncMap())
 
 	// Add placeholders for package-specific functions
-	tmpl = tmpl.Funcs(GetPackageFuncMap(nil))
+	tmpl = tmpl.Funcs(GetPackageFuncMap(nil, ""))
 
 	return tmpl
 }
 
-// AddPackageSpecificTemplateFunctions adds the package-specific template functions for the given template.
-func AddPackageSpecificTemplateFunctions(tmpl *template.Template) *template.Template {
+// AddPackageSpecificTemplateFunctions adds the package-specific template functions for the given template, which is
+// defined in the given package directory.
+func AddPackageSpecificTemplateFunctions(tmpl *template.Template, packageDir string) *template.Template {
 	if tmpl == nil {
 		log.Panicf("Template cannot be nil")
 	}
 
-	return tmpl.Funcs(GetPackageFuncMap(tmpl))
+	return tmpl.Funcs(GetPackageFuncMap(tmpl, packageDir))
 }
 
-// GetTemplateFromFile returns a new template object given a template file.
-func GetTemplateFromFile(parentTemplate *template.Template, templateName string, filePath string) (*template.Template, error) {
+// GetTemplateFromFile returns a new template object given a template file.  If "coverage" is not nil, the template
+// records which of its blocks are executed in it.
+func GetTemplateFromFile(parentTemplate *template.Template, templateName string, filePath string, coverage *Coverage) (*template.Template, error) {
+	// Get template file as string
+	var templateString, err = files.ReadString(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var tmpl *template.Template
+	tmpl, err = GetTemplateFromString(parentTemplate, templateName, templateString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record which parts of the file are executed, if coverage is enabled
+	if coverage != nil {
+		coverage.instrument(tmpl, filePath, templateString)
+	}
+
+	return tmpl, nil
+}
+
+// GetTemplateFromString returns a new template object given the text of a template.
+func GetTemplateFromString(parentTemplate *template.Template, templateName string, templateString string) (*template.Template, error) {
 	var err error
 
 	// Create template
@@ -54,28 +79,44 @@ func GetTemplateFromFile(parentTemplate *template.Template, templateName string,
 		tmpl = template.New(templateName)
 	}
 
-	// Get template file as string
-	var templateString string
-	templateString, err = files.ReadString(filePath)
+	// Parse template
+	tmpl, err = tmpl.Parse(templateString)
 	if err != nil {
 		return nil, err
 	}
 
-	// Parse template
-	tmpl, err = tmpl.Parse(templateString)
+	return tmpl, nil
+}
+
+// GetTemplatesFromDir returns an array containing all of the templates found in the given directory.  If "coverage" is
+// not nil, the templates record which of their blocks are executed in it.
+func GetTemplatesFromDir(parentTemplate *template.Template, templatesDirPath string, coverage *Coverage) ([]*template.Template, error) {
+	var err error
+
+	var templates []*template.Template
+	err = visitTemplatesFromDir(templatesDirPath, false, coverage, func() *template.Template {
+		// Use the same parent template each time
+		return parentTemplate
+	}, func(tmpl *template.Template) {
+		// Add the template to the array
+		templates = append(templates, tmpl)
+	})
+
 	if err != nil {
 		return nil, err
 	}
 
-	return tmpl, nil
+	return templates, nil
 }
 
-// GetTemplatesFromDir returns an array containing all of the templates found in the given directory.
-func GetTemplatesFromDir(parentTemplate *template.Template, templatesDirPath string) ([]*template.Template, error) {
+// GetTemplatesFromDirRecursive returns an array containing all of the templates found in the given directory and its
+// sub-directories.  The name of each template is its path relative to the given directory, using forward slashes.  If
+// "coverage" is not nil, the templates record which of their blocks are executed in it.
+func GetTemplatesFromDirRecursive(parentTemplate *template.Template, templatesDirPath string, coverage *Coverage) ([]*template.Template, error) {
 	var err error
 
 	var templates []*template.Template
-	err = visitTemplatesFromDir(templatesDirPath, func() *template.Template {
+	err = visitTemplatesFromDir(templatesDirPath, true, coverage, func() *template.Template {
 		// Use the same parent template each time
 		return parentTemplate
 	}, func(tmpl *template.Template) {
@@ -91,12 +132,13 @@ func GetTemplatesFromDir(parentTemplate *template.Template, templatesDirPath str
 }
 
 // ChainTemplatesFromDir returns a single template which contains all of the templates that were found in the given directory.
-func ChainTemplatesFromDir(parentTemplate *template.Template, templatesDirPath string) (*template.Template, int, error) {
+// If "coverage" is not nil, the templates record which of their blocks are executed in it.
+func ChainTemplatesFromDir(parentTemplate *template.Template, templatesDirPath string, coverage *Coverage) (*template.Template, int, error) {
 	var err error
 
 	var currentTemplate = parentTemplate
 	var numTemplates = 0
-	err = visitTemplatesFromDir(templatesDirPath, func() *template.Template {
+	err = visitTemplatesFromDir(templatesDirPath, false, coverage, func() *template.Template {
 		// Use the current template as the parent
 		return currentTemplate
 	}, func(nextTemplate *template.Template) {
@@ -168,34 +210,65 @@ func ExecuteTemplate(tmpl *template.Template, values any) ([]byte, error) {
 }
 
 // visitTemplatesFromDir visits each template found in the given directory, sets the parent using the given "getParentTemplate" function
-// and then consumes the template using the given "consumeTemplate" function.
-func visitTemplatesFromDir(templatesDirPath string, getParentTemplate TemplateSupplier, consumeTemplate TemplateConsumer) error {
+// and then consumes the template using the given "consumeTemplate" function.  If "recursive" is true, templates in sub-directories
+// are also visited and named by their path relative to the given directory, otherwise sub-directories are ignored.
+func visitTemplatesFromDir(
+	templatesDirPath string,
+	recursive bool,
+	coverage *Coverage,
+	getParentTemplate TemplateSupplier,
+	consumeTemplate TemplateConsumer,
+) error {
+	return visitTemplatesFromSubDir(templatesDirPath, "", recursive, coverage, getParentTemplate, consumeTemplate)
+}
+
+// visitTemplatesFromSubDir visits each template found in a directory, prefixing the name of each template with the
+// directory's path relative to the root templates directory.
+func visitTemplatesFromSubDir(
+	dirPath string,
+	relativeDirPath string,
+	recursive bool,
+	coverage *Coverage,
+	getParentTemplate TemplateSupplier,
+	consumeTemplate TemplateConsumer,
+) error {
 	var err error
 
-	// Get the list of filesystem objects in the helpers directory
+	// Get the list of filesystem objects in the directory
 	var filesystemObjects []os.DirEntry
-	filesystemObjects, err = os.ReadDir(templatesDirPath)
+	filesystemObjects, err = os.ReadDir(dirPath)
 	if err != nil {
 		return err
 	}
 
-	// Parse all templates in the given directory, ignoring sub-directories
-	log.Debugf("Parsing templates in directory: %s", templatesDirPath)
+	// Parse all templates in the given directory
+	log.Debugf("Parsing templates in directory: %s", dirPath)
 	for _, filesystemObject := range filesystemObjects {
 		var fileName = filesystemObject.Name()
+		var filePath = filepath.Join(dirPath, fileName)
+
+		// Template names always use forward slashes, so they are the same on every platform
+		var templateName = path.Join(relativeDirPath, fileName)
 
-		// Ignore directories
 		if filesystemObject.IsDir() {
-			log.Warningf("Ignoring sub-directory: %s", fileName)
+			if !recursive {
+				log.Warningf("Ignoring sub-directory: %s", fileName)
+				continue
+			}
+
+			err = visitTemplatesFromSubDir(filePath, templateName, recursive, coverage, getParentTemplate, consumeTemplate)
+			if err != nil {
+				return err
+			}
+
 			continue
 		}
 
-		log.Debugf("Parsing template: %s", fileName)
+		log.Debugf("Parsing template: %s", templateName)
 
 		// Create a template object from the file
-		var filePath = filepath.Join(templatesDirPath, fileName)
 		var tmpl *template.Template
-		tmpl, err = GetTemplateFromFile(getParentTemplate(), fileName, filePath)
+		tmpl, err = GetTemplateFromFile(getParentTemplate(), templateName, filePath, coverage)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,52 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetTemplatesFromDirRecursive(t *testing.T) {
+	Convey("Given a templates directory with sub-directories", t, func() {
+		var templatesDir = t.TempDir()
+		var templateFiles = map[string]string{
+			"a.yaml":                  `{{ include "crds/b.yaml" . }}`,
+			"crds/b.yaml":             "b",
+			"monitoring/alerts/c.txt": "c",
+		}
+		for relativePath, content := range templateFiles {
+			var filePath = filepath.Join(templatesDir, filepath.FromSlash(relativePath))
+			So(os.MkdirAll(filepath.Dir(filePath), os.ModePerm), ShouldBeNil)
+			So(os.WriteFile(filePath, []byte(content), 0644), ShouldBeNil)
+		}
+
+		var rootTemplate = NewRootTemplate()
+		rootTemplate = AddPackageSpecificTemplateFunctions(rootTemplate, templatesDir)
+
+		Convey("Templates are named by their relative paths", func() {
+			var templates, err = GetTemplatesFromDirRecursive(rootTemplate, templatesDir, nil)
+			So(err, ShouldBeNil)
+
+			var names = []string{}
+			for _, tmpl := range templates {
+				names = append(names, tmpl.Name())
+			}
+			So(names, ShouldResemble, []string{"a.yaml", "crds/b.yaml", "monitoring/alerts/c.txt"})
+
+			Convey("Templates in sub-directories can be included by their relative paths", func() {
+				var output, err = ExecuteTemplate(templates[0], map[string]any{})
+				So(err, ShouldBeNil)
+				So(string(output), ShouldEqual, "b")
+			})
+		})
+
+		Convey("Sub-directories are ignored when not recursing", func() {
+			var templates, err = GetTemplatesFromDir(rootTemplate, templatesDir, nil)
+			So(err, ShouldBeNil)
+			So(templates, ShouldHaveLength, 1)
+			So(templates[0].Name(), ShouldEqual, "a.yaml")
+		})
+	})
+}
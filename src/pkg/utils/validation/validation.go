@@ -59,28 +59,35 @@ func ValidatePackageVersion(packageVersion string) error {
 		return fmt.Errorf("package version string cannot be empty")
 	}
 
-	// Check for zero version
-	var zeroVersion = "0.0.0"
-	if packageVersion == zeroVersion {
-		return fmt.Errorf("package version cannot be \"%s\"", zeroVersion)
-	}
-
 	// Regex for each segment that has a valid integer (don't allow leading zeros in any segment)
 	var segmentRegex = "(0|[1-9][0-9]*)"
 
+	// Regexes for the optional prerelease and build metadata (see https://semver.org)
+	var prereleaseIdentifierRegex = "(0|[1-9][0-9]*|[0-9]*[a-zA-Z-][0-9a-zA-Z-]*)"
+	var prereleaseRegex = fmt.Sprintf("(-%s(\\.%s)*)?", prereleaseIdentifierRegex, prereleaseIdentifierRegex)
+	var buildMetadataRegex = "(\\+[0-9a-zA-Z-]+(\\.[0-9a-zA-Z-]+)*)?"
+
 	// Overall regex
-	var fullRegex = "^%s\\.%s\\.%s$"
+	var fullRegex = "^%s\\.%s\\.%s%s%s$"
 
 	// Check whether the version string satisfies the regex
 	var isValid bool
-	isValid, err = regexp.MatchString(fmt.Sprintf(fullRegex, segmentRegex, segmentRegex, segmentRegex), packageVersion)
+	isValid, err = regexp.MatchString(fmt.Sprintf(fullRegex, segmentRegex, segmentRegex, segmentRegex, prereleaseRegex, buildMetadataRegex), packageVersion)
 	if err != nil {
 		log.Panicf("Regex execution failed: %s", err)
 	}
 
 	// Return error if the version string did not satisfy the regex
 	if !isValid {
-		return fmt.Errorf("package version must solely consist of digits, be in the form \"major.minor.revision\" with no leading zeros in any segment, and be greater than \"0.0.0\": %s", packageVersion)
+		return fmt.Errorf("package version must be a semantic version in the form \"major.minor.revision\" (optionally followed by \"-prerelease\" and/or \"+build\"), with no leading zeros in any numeric segment: %s", packageVersion)
+	}
+
+	// Check for zero version (including its prereleases and builds)
+	var zeroVersion = "0.0.0"
+	var releaseVersion, _, _ = strings.Cut(packageVersion, "+")
+	releaseVersion, _, _ = strings.Cut(releaseVersion, "-")
+	if releaseVersion == zeroVersion {
+		return fmt.Errorf("package version cannot be \"%s\": %s", zeroVersion, packageVersion)
 	}
 
 	return nil
@@ -151,8 +158,15 @@ func ValidateNamespaceSegment(namespaceSegment string) error {
 
 // ExtractNameAndVersionFromPackageFullName returns the name and version of a template package, given the full package name.
 func ExtractNameAndVersionFromPackageFullName(packageFullName string) (packageName string, packageVersion string, err error) {
-	// Split the file name to get the name and version
+	// Split the file name to get the name and version.  Since both the name and the version (i.e. prereleases) may
+	// contain dashes, use the last dash which is followed by a valid version.
 	var nameVersionSplitIndex = strings.LastIndex(packageFullName, "-")
+	for nameVersionSplitIndex > 0 && ValidatePackageVersion(packageFullName[nameVersionSplitIndex+1:]) != nil {
+		nameVersionSplitIndex = strings.LastIndex(packageFullName[:nameVersionSplitIndex], "-")
+	}
+	if nameVersionSplitIndex < 0 {
+		return "", "", fmt.Errorf("package full name must be in the form \"name-version\": %s", packageFullName)
+	}
 	packageName = packageFullName[0:nameVersionSplitIndex]
 	packageVersion = packageFullName[nameVersionSplitIndex+1:]
 
@@ -0,0 +1,47 @@
+package validation
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidatePackageVersion(t *testing.T) {
+	Convey("Semantic versions are valid package versions", t, func() {
+		for _, version := range []string{"1.0.0", "0.0.1", "1.10.0", "2.0.0-rc.1", "1.0.0-alpha.beta-1", "1.0.0+build.5", "1.0.0-rc.1+20240101"} {
+			So(ValidatePackageVersion(version), ShouldBeNil)
+		}
+	})
+
+	Convey("Other versions are not valid package versions", t, func() {
+		for _, version := range []string{"", "1", "1.0", "v1.0.0", "01.0.0", "1.0.0-", "1.0.0-01", "1.0.0+", "1.0.0-rc..1", "0.0.0", "0.0.0-rc.1", "^1.0.0"} {
+			So(ValidatePackageVersion(version), ShouldNotBeNil)
+		}
+	})
+}
+
+func TestExtractNameAndVersionFromPackageFullName(t *testing.T) {
+	Convey("Names and versions which contain dashes are extracted", t, func() {
+		var testCases = map[string][2]string{
+			"kpmtool/hello-1.0.0":            {"kpmtool/hello", "1.0.0"},
+			"kpmtool/hello-world-2.0.0-rc.1": {"kpmtool/hello-world", "2.0.0-rc.1"},
+			"kpmtool/hello-1.0.0-alpha-beta": {"kpmtool/hello", "1.0.0-alpha-beta"},
+			"kpmtool/hello-1.0.0+build.5":    {"kpmtool/hello", "1.0.0+build.5"},
+			"kpmtool/hello-2-1.0.0-rc.1+abc": {"kpmtool/hello-2", "1.0.0-rc.1+abc"},
+		}
+
+		for fullName, expected := range testCases {
+			var name, version, err = ExtractNameAndVersionFromPackageFullName(fullName)
+			So(err, ShouldBeNil)
+			So(name, ShouldEqual, expected[0])
+			So(version, ShouldEqual, expected[1])
+		}
+	})
+
+	Convey("Full names without a version are rejected", t, func() {
+		for _, fullName := range []string{"kpmtool/hello", "kpmtool/hello-world", "kpmtool/hello-1.0"} {
+			var _, _, err = ExtractNameAndVersionFromPackageFullName(fullName)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
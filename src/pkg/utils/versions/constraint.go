@@ -0,0 +1,205 @@
+// Package versions compares template package versions and matches them against version constraints.
+package versions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// implicitAndRegex matches the whitespace between two comparisons which aren't separated by a comma (e.g. the space in
+// ">=2.0.0 <3.0.0"), but not the whitespace after an operator (e.g. ">= 2.0.0") or around a hyphen range.
+var implicitAndRegex = regexp.MustCompile(`([0-9A-Za-z*])\s+([<>=!~^])`)
+
+// hyphenRangeRegex matches a hyphen range (e.g. "1.0.0 - 1.4.1"), which is the same as ">= 1.0.0, <= 1.4.1".
+var hyphenRangeRegex = regexp.MustCompile(`(\S+)\s+-\s+(\S+)`)
+
+// comparisonRegex splits a single comparison (e.g. ">= 2.0.0") into its operator and version.
+var comparisonRegex = regexp.MustCompile(`^\s*(!=|>=|=>|<=|=<|~>|[=<>~^]?)\s*(\S+)\s*$`)
+
+// fullVersionRegex matches versions which have a major, minor and patch number without any wildcards (e.g. "2.0.0", but
+// not "2.0" or "2.x").
+var fullVersionRegex = regexp.MustCompile(`^v?\d+\.\d+\.\d+([-+].*)?$`)
+
+// wildcardRegex matches a version which is only a wildcard (e.g. "*" or "x").
+var wildcardRegex = regexp.MustCompile(`^v?[xX*]$`)
+
+// Constraint is a set of conditions which versions can be matched against.
+type Constraint struct {
+	text        string
+	constraints *semver.Constraints
+
+	// comparisons are the constraint's comparisons, grouped by the "||" alternatives that they belong to.
+	comparisons [][]*comparison
+}
+
+// comparison is a single condition in a constraint (e.g. ">=2.0.0").
+type comparison struct {
+	operator string
+
+	// version is the version in the comparison, or nil if it isn't a full version (e.g. "2.x").
+	version *semver.Version
+
+	// constraint checks the comparison as it was written.
+	constraint *semver.Constraints
+
+	// prereleaseConstraint checks the comparison against prereleases of other versions, by comparing with the lowest
+	// prerelease of the comparison's version (e.g. ">=2.0.0-0" for ">=2.0.0").
+	prereleaseConstraint *semver.Constraints
+}
+
+// ParseConstraint parses a version constraint such as "^1.2.0", "~1.4", ">=2.0.0 <3.0.0" or "1.x || 2.x".
+func ParseConstraint(constraint string) (*Constraint, error) {
+	var text = strings.TrimSpace(constraint)
+	if text == "" {
+		return nil, fmt.Errorf("version constraint cannot be empty")
+	}
+
+	var normalizedText = implicitAndRegex.ReplaceAllString(text, "$1, $2")
+	var constraints, err = semver.NewConstraint(normalizedText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint \"%s\": %s", constraint, err)
+	}
+
+	var comparisons [][]*comparison
+	comparisons, err = parseComparisons(normalizedText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version constraint \"%s\": %s", constraint, err)
+	}
+
+	return &Constraint{text: text, constraints: constraints, comparisons: comparisons}, nil
+}
+
+// parseComparisons splits a normalized constraint into its "||" alternatives, and each alternative into its comparisons.
+func parseComparisons(normalizedText string) ([][]*comparison, error) {
+	var result = [][]*comparison{}
+	for _, alternative := range strings.Split(normalizedText, "||") {
+		var group = []*comparison{}
+		for _, comparisonText := range strings.Split(hyphenRangeRegex.ReplaceAllString(alternative, ">= $1, <= $2"), ",") {
+			var match = comparisonRegex.FindStringSubmatch(comparisonText)
+			if match == nil {
+				return nil, fmt.Errorf("invalid comparison: %s", comparisonText)
+			}
+
+			var currentComparison, err = newComparison(match[1], match[2])
+			if err != nil {
+				return nil, err
+			}
+			group = append(group, currentComparison)
+		}
+		result = append(result, group)
+	}
+
+	return result, nil
+}
+
+// newComparison creates a comparison from its operator and version.
+func newComparison(operator string, versionText string) (result *comparison, err error) {
+	result = &comparison{operator: operator}
+	if result.constraint, err = semver.NewConstraint(operator + versionText); err != nil {
+		return nil, err
+	}
+
+	// The lowest prerelease of a version is "<version>-0", which is inserted before any build metadata.  A wildcard on its
+	// own (e.g. "*") matches any version, so it becomes the lowest prerelease of all.
+	var prereleaseText = operator + versionText
+	if wildcardRegex.MatchString(versionText) {
+		prereleaseText = ">=0.0.0-0"
+	} else if !strings.Contains(strings.SplitN(versionText, "+", 2)[0], "-") {
+		var parts = strings.SplitN(versionText, "+", 2)
+		parts[0] += "-0"
+		prereleaseText = operator + strings.Join(parts, "+")
+	}
+	if result.prereleaseConstraint, err = semver.NewConstraint(prereleaseText); err != nil {
+		return nil, err
+	}
+
+	if fullVersionRegex.MatchString(versionText) {
+		if result.version, err = semver.NewVersion(versionText); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// String returns the constraint as it was written.
+func (constraint *Constraint) String() string {
+	return constraint.text
+}
+
+// Matches checks whether a version satisfies the constraint.  Invalid versions never satisfy the constraint.
+//
+// Prereleases only satisfy constraints which mention a prerelease of the same version (e.g. "2.0.0-rc.1" satisfies
+// ">=2.0.0-rc.0"), unless "includePrerelease" is true.  In that case, any prerelease may satisfy the constraint, but it
+// is still compared by its own precedence (e.g. "1.3.0-rc.1" satisfies "^1.2.0", and "2.0.0-rc.1" satisfies "<2.0.0"
+// but not ">=2.0.0").
+func (constraint *Constraint) Matches(version string, includePrerelease bool) bool {
+	var parsedVersion, err = semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+
+	if constraint.constraints.Check(parsedVersion) {
+		return true
+	}
+
+	if !includePrerelease || parsedVersion.Prerelease() == "" {
+		return false
+	}
+
+	for _, group := range constraint.comparisons {
+		var groupMatches = true
+		for _, currentComparison := range group {
+			if !currentComparison.matchesPrerelease(parsedVersion) {
+				groupMatches = false
+				break
+			}
+		}
+
+		if groupMatches {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesPrerelease checks whether a prerelease satisfies the comparison, without requiring the comparison to mention
+// a prerelease.
+func (currentComparison *comparison) matchesPrerelease(version *semver.Version) bool {
+	if currentComparison.version == nil || currentComparison.version.Prerelease() != "" {
+		return currentComparison.prereleaseConstraint.Check(version)
+	}
+
+	// Prereleases of the comparison's version are lower than it, and no other versions are in between, so only they
+	// need to be compared with the version itself
+	var comparisonVersion = currentComparison.version
+	if version.Major() != comparisonVersion.Major() ||
+		version.Minor() != comparisonVersion.Minor() ||
+		version.Patch() != comparisonVersion.Patch() {
+		return currentComparison.prereleaseConstraint.Check(version)
+	}
+
+	switch currentComparison.operator {
+	case "<", "<=", "=<", "!=":
+		return true
+	default:
+		return false
+	}
+}
+
+// GetHighestMatchingVersion returns the highest of the given versions which satisfies the constraint.  It returns false
+// if none of the versions satisfy the constraint.
+func (constraint *Constraint) GetHighestMatchingVersion(versions []string, includePrerelease bool) (string, bool) {
+	var matchingVersions = []string{}
+	for _, version := range versions {
+		if constraint.Matches(version, includePrerelease) {
+			matchingVersions = append(matchingVersions, version)
+		}
+	}
+
+	return GetHighestVersion(matchingVersions, true)
+}
@@ -0,0 +1,110 @@
+package versions
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConstraint(t *testing.T) {
+	var availableVersions = []string{"1.0.0", "1.2.0", "1.2.5", "1.4.1", "1.10.0", "2.0.0", "2.3.0", "3.0.0"}
+
+	Convey("Constraints resolve to the highest matching version", t, func() {
+		var testCases = map[string]string{
+			"^1.2.0":          "1.10.0",
+			"~1.2.0":          "1.2.5",
+			"~1.4":            "1.4.1",
+			">=2.0.0 <3.0.0":  "2.3.0",
+			">= 2.0.0, < 3.0": "2.3.0",
+			"1.0.0 - 1.4.1":   "1.4.1",
+			"1.x || 3.x":      "3.0.0",
+			"*":               "3.0.0",
+			"1.2.0":           "1.2.0",
+		}
+
+		for constraintText, expectedVersion := range testCases {
+			var constraint, err = ParseConstraint(constraintText)
+			So(err, ShouldBeNil)
+
+			var version, found = constraint.GetHighestMatchingVersion(availableVersions, false)
+			So(found, ShouldBeTrue)
+			So(version, ShouldEqual, expectedVersion)
+			So(constraint.Matches(expectedVersion, false), ShouldBeTrue)
+		}
+	})
+
+	Convey("Constraints which match nothing don't resolve", t, func() {
+		var constraint, err = ParseConstraint("^4.0.0")
+		So(err, ShouldBeNil)
+
+		var _, found = constraint.GetHighestMatchingVersion(availableVersions, false)
+		So(found, ShouldBeFalse)
+		So(constraint.Matches("3.0.0", false), ShouldBeFalse)
+	})
+
+	Convey("Prereleases are only chosen when they are mentioned or included", t, func() {
+		var versionsWithPrereleases = []string{"1.2.0", "1.3.0-rc.1", "2.0.0-beta.2", "2.0.0-rc.1"}
+
+		var constraint, err = ParseConstraint("^1.2.0")
+		So(err, ShouldBeNil)
+
+		var version, found = constraint.GetHighestMatchingVersion(versionsWithPrereleases, false)
+		So(found, ShouldBeTrue)
+		So(version, ShouldEqual, "1.2.0")
+
+		version, found = constraint.GetHighestMatchingVersion(versionsWithPrereleases, true)
+		So(found, ShouldBeTrue)
+		So(version, ShouldEqual, "1.3.0-rc.1")
+
+		constraint, err = ParseConstraint(">=2.0.0-beta.1")
+		So(err, ShouldBeNil)
+
+		version, found = constraint.GetHighestMatchingVersion(versionsWithPrereleases, false)
+		So(found, ShouldBeTrue)
+		So(version, ShouldEqual, "2.0.0-rc.1")
+	})
+
+	Convey("Included prereleases are compared by their own precedence", t, func() {
+		var testCases = []struct {
+			constraint string
+			version    string
+			matches    bool
+		}{
+			{">=2.0.0", "2.0.0-rc.1", false},
+			{">=2.0.0", "2.1.0-rc.1", true},
+			{">=2.0.0", "1.9.0-rc.1", false},
+			{"=2.0.0", "2.0.0-rc.1", false},
+			{"2.0.0", "2.0.0-rc.1", false},
+			{"<2.0.0", "2.0.0-rc.1", true},
+			{"<2.0.0", "2.1.0-rc.1", false},
+			{"<=2.0.0", "2.0.0-rc.1", true},
+			{">2.0.0", "2.0.0-rc.1", false},
+			{">=2.0.0-rc.2", "2.0.0-rc.1", false},
+			{"^1.2.0", "2.0.0-rc.1", false},
+			{"~1.2.0", "1.2.1-rc.1", true},
+			{"1.x", "1.5.0-beta", true},
+			{"*", "3.0.0-alpha", true},
+		}
+
+		for _, testCase := range testCases {
+			var constraint, err = ParseConstraint(testCase.constraint)
+			So(err, ShouldBeNil)
+			So(constraint.Matches(testCase.version, true), ShouldEqual, testCase.matches)
+		}
+
+		Convey("So a release candidate isn't chosen when the release isn't available", func() {
+			var constraint, err = ParseConstraint(">=2.0.0")
+			So(err, ShouldBeNil)
+
+			var _, found = constraint.GetHighestMatchingVersion([]string{"1.2.0", "2.0.0-rc.1"}, true)
+			So(found, ShouldBeFalse)
+		})
+	})
+
+	Convey("Invalid constraints are rejected", t, func() {
+		for _, constraintText := range []string{"", "latest", "^one.two"} {
+			var _, err = ParseConstraint(constraintText)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package versions
+
+import (
+	"sort"
+
+	"github.com/Masterminds/semver"
+)
+
+// Compare returns -1, 0 or 1 if version "a" has lower, equal or higher precedence than version "b", according to
+// Semantic Versioning 2.0.  Versions which only differ by build metadata have equal precedence, so they are ordered by
+// their text to keep sorting deterministic.  Invalid versions are ordered before valid ones.
+func Compare(a string, b string) int {
+	var parsedA, errA = semver.NewVersion(a)
+	var parsedB, errB = semver.NewVersion(b)
+	switch {
+	case errA != nil && errB != nil:
+		return compareText(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+
+	if result := parsedA.Compare(parsedB); result != 0 {
+		return result
+	}
+
+	return compareText(a, b)
+}
+
+// Sort sorts versions from lowest to highest precedence.
+func Sort(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return Compare(versions[i], versions[j]) < 0
+	})
+}
+
+// IsPrerelease checks whether a version is a prerelease (e.g. "2.0.0-rc.1").
+func IsPrerelease(version string) bool {
+	var parsedVersion, err = semver.NewVersion(version)
+
+	return err == nil && parsedVersion.Prerelease() != ""
+}
+
+// GetHighestVersion returns the version with the highest precedence.  Prereleases are ignored unless
+// "includePrerelease" is true.  It returns false if there are no versions to choose from.
+func GetHighestVersion(versions []string, includePrerelease bool) (string, bool) {
+	var result string
+	var found = false
+	for _, version := range versions {
+		if !includePrerelease && IsPrerelease(version) {
+			continue
+		}
+
+		if !found || Compare(version, result) > 0 {
+			result = version
+			found = true
+		}
+	}
+
+	return result, found
+}
+
+func compareText(a string, b string) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
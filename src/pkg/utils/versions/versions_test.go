@@ -0,0 +1,55 @@
+package versions
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestVersions(t *testing.T) {
+	Convey("Versions are sorted by precedence", t, func() {
+		// Example from https://semver.org/#spec-item-11
+		var expectedOrder = []string{
+			"1.0.0-alpha",
+			"1.0.0-alpha.1",
+			"1.0.0-alpha.beta",
+			"1.0.0-beta",
+			"1.0.0-beta.2",
+			"1.0.0-beta.11",
+			"1.0.0-rc.1",
+			"1.0.0",
+			"1.0.0+build.5",
+			"1.9.0",
+			"1.10.0",
+			"2.0.0",
+		}
+
+		var shuffled = []string{
+			"1.10.0", "1.0.0-beta.11", "2.0.0", "1.0.0+build.5", "1.0.0-alpha.beta", "1.0.0", "1.9.0",
+			"1.0.0-rc.1", "1.0.0-alpha", "1.0.0-beta.2", "1.0.0-alpha.1", "1.0.0-beta",
+		}
+		Sort(shuffled)
+		So(shuffled, ShouldResemble, expectedOrder)
+	})
+
+	Convey("Build metadata doesn't affect precedence", t, func() {
+		So(Compare("1.0.0+build.5", "1.0.0+build.6"), ShouldBeLessThan, 0)
+		So(Compare("1.0.0+build.5", "1.0.0-rc.1"), ShouldBeGreaterThan, 0)
+		So(Compare("1.0.0+build.5", "1.0.1"), ShouldBeLessThan, 0)
+	})
+
+	Convey("The highest version ignores prereleases unless they are included", t, func() {
+		var availableVersions = []string{"1.9.0", "1.10.0", "2.0.0-rc.1"}
+
+		var version, found = GetHighestVersion(availableVersions, false)
+		So(found, ShouldBeTrue)
+		So(version, ShouldEqual, "1.10.0")
+
+		version, found = GetHighestVersion(availableVersions, true)
+		So(found, ShouldBeTrue)
+		So(version, ShouldEqual, "2.0.0-rc.1")
+
+		_, found = GetHighestVersion([]string{"2.0.0-rc.1"}, false)
+		So(found, ShouldBeFalse)
+	})
+}